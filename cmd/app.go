@@ -1,22 +1,65 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"mockium/internal/logging"
+	"mockium/internal/model"
+	"mockium/internal/service"
 	"mockium/internal/service/builder"
-	"mockium/internal/transport"
+	"mockium/internal/service/delay"
+	"mockium/internal/service/openapi"
+	"mockium/internal/service/random"
+	"mockium/internal/service/recorder"
+	"mockium/internal/service/scenario"
+	"mockium/internal/service/sequence"
+	"mockium/internal/service/store"
+	"mockium/internal/service/watcher"
+	"mockium/internal/transport/admin"
+	"mockium/internal/transport/handler"
+	"mockium/internal/transport/proxy"
 	"mockium/internal/transport/server"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 )
 
+const (
+	// modeReplay serves the templates from the template directory.
+	modeReplay = "replay"
+	// modeRecord proxies requests to the upstream and saves them as templates.
+	modeRecord = "record"
+)
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	templateDir := flag.String("template", "templates", "location directory with template file, default './templates'")
 	address := flag.String("address", ":5000", "address with port, default ':5000'")
 	logLevel := flag.String("log-level", "info", "usage log level, default 'info'")
 	processLogPath := flag.String("log-dir", "log", "log direcrectory, default 'log'")
+	watchInterval := flag.Duration("watch", time.Second, "template directory polling interval for hot reload, '0' disables reload, default '1s'")
+	adminPrefix := flag.String("admin-prefix", "/__admin", "path prefix of the admin API, empty disables the API, default '/__admin'")
+	globalDelay := flag.String("delay", "", "delay of all responses without their own 'SetDelay': '200ms', 'uniform:100ms,300ms', 'lognormal:200ms,0.5' or 'normal:200ms,50ms', default none")
+	journalSize := flag.Int("journal-size", 1000, "number of requests kept in the in-memory request journal, '0' disables the journal, default '1000'")
+	mode := flag.String("mode", modeReplay, "'replay' serves the templates, 'record' proxies requests to 'upstream' and saves them as templates, default 'replay'")
+	upstream := flag.String("upstream", "", "upstream URL requests are proxied to in record mode")
+	recordQuery := flag.String("record-query", recorder.AllParameters, "comma separated query parameters recorded as match criteria, '*' records all, default '*'")
+	recordHeaders := flag.String("record-headers", "", "comma separated headers recorded as match criteria, '*' records all, default none")
+	proxyURL := flag.String("proxy", "", "upstream URL the requests no template matches are proxied to, default none")
+	validateSpec := flag.String("validate", "", "OpenAPI 3 document the requests are validated against, default none")
+	validationStatus := flag.Int("validation-status", http.StatusBadRequest, "status of the requests failing validation, default '400'")
+	seed := flag.Int64("seed", 0, "seed of the random responses, delays and faults, the same seed repeats the same choices, default random")
 	flag.Parse()
 
 	log, err := logging.NewZapLogger(*logLevel, *processLogPath)
@@ -25,6 +68,37 @@ func main() {
 		os.Exit(1)
 	}
 
+	switch *mode {
+	case modeReplay:
+	case modeRecord:
+		if err := record(log, *address, *upstream, *templateDir, recorder.Options{
+			Query:   splitNames(*recordQuery),
+			Headers: splitNames(*recordHeaders),
+		}); err != nil {
+			log.Error("record", zap.Error(err))
+			os.Exit(1)
+		}
+		return
+	default:
+		log.Error("unexpected mode", zap.String("mode", *mode))
+		os.Exit(1)
+	}
+
+	if *seed != 0 {
+		random.Seed(*seed)
+	}
+
+	options := builder.Options{Sequences: sequence.New()}
+	if *globalDelay != "" {
+		if options.Delay, err = delay.Parse(*globalDelay); err == nil {
+			_, err = delay.New(options.Delay)
+		}
+		if err != nil {
+			log.Error("parse delay", zap.Error(err))
+			os.Exit(1)
+		}
+	}
+
 	procLogger, err := logging.NewProcessLogger(log, *processLogPath, "requests", 10)
 	if err != nil {
 		log.Error("init process logger", zap.Error(err))
@@ -32,19 +106,109 @@ func main() {
 	}
 	defer procLogger.Close()
 
-	templates, err := builder.NewTemplateBuilder(log).Build(*templateDir)
-	if err != nil {
+	var requestLogger service.ProcessLogger = procLogger
+	var journal service.RequestJournal
+	if *journalSize > 0 {
+		requestJournal := logging.NewJournal(*journalSize)
+		requestLogger = logging.NewMultiLogger(procLogger, requestJournal)
+		journal = requestJournal
+	}
+
+	scenarios := scenario.New()
+
+	srv := server.New(log)
+
+	if *proxyURL != "" {
+		upstreamProxy, err := proxy.NewReverseProxy(log, *proxyURL)
+		if err != nil {
+			log.Error("create proxy", zap.Error(err))
+			os.Exit(1)
+		}
+
+		options.Proxy = upstreamProxy
+		srv.NotFound(handler.New(log, requestLogger, scenarios, "", upstreamProxy, nil))
+	}
+
+	if *validateSpec != "" {
+		doc, err := openapi.Load(*validateSpec)
+		if err != nil {
+			log.Error("load OpenAPI document", zap.Error(err))
+			os.Exit(1)
+		}
+
+		validator, err := openapi.NewValidator(doc)
+		if err != nil {
+			log.Error("create request validator", zap.Error(err))
+			os.Exit(1)
+		}
+
+		srv.Use(func(next http.Handler) http.Handler {
+			return handler.NewValidation(log, requestLogger, validator, *validationStatus, next)
+		})
+	}
+
+	templateBuilder := builder.NewTemplateBuilder(log)
+	templates := store.New(log, templateBuilder,
+		func() ([]model.Template, error) {
+			return templateBuilder.Build(*templateDir)
+		},
+		func(templates []model.Template) {
+			srv.Reload(builder.BuildAll(log, requestLogger, scenarios, options, templates)...)
+		},
+	)
+
+	if *adminPrefix != "" {
+		srv.Mount(*adminPrefix, admin.New(log, *adminPrefix, templates, journal, scenarios, options.Sequences))
+	}
+
+	if err := templates.Reset(); err != nil {
 		log.Error("build template", zap.Error(err))
 		os.Exit(1)
 	}
 
-	routes := make([]transport.Router, 0)
-	for _, template := range templates {
-		routes = append(routes, builder.BuildRoutes(log, procLogger, &template))
+	if *watchInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go watcher.New(log, *templateDir, *watchInterval, func() {
+			if err := templates.Reload(); err != nil {
+				log.Error("reload template, keep previous configuration", zap.Error(err))
+			}
+		}).Run(ctx)
 	}
 
-	if err := server.New(log, routes...).Start(*address); err != nil {
+	if err := srv.Start(*address); err != nil {
 		log.Error("start server", zap.Error(err))
 		os.Exit(1)
 	}
 }
+
+// record runs the server in record mode: every request is proxied to the upstream
+// and the exchange is saved as a template in the template directory.
+func record(log *zap.Logger, address, upstream, templateDir string, options recorder.Options) error {
+	if err := os.MkdirAll(templateDir, 0o755); err != nil {
+		return err
+	}
+
+	recordingProxy, err := proxy.NewRecorder(log, upstream, recorder.New(log, templateDir, options))
+	if err != nil {
+		return err
+	}
+
+	srv := server.New(log)
+	srv.Mount("/", recordingProxy)
+
+	log.Info("recording", zap.String("upstream", upstream), zap.String("template", templateDir))
+	return srv.Start(address)
+}
+
+// splitNames splits a comma separated list of names.
+func splitNames(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
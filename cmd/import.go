@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"mockium/internal/model"
+	"mockium/internal/service/builder"
+	"mockium/internal/service/openapi"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// unsafeFileChars are replaced in template file names derived from paths.
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// runImport runs the import command: mockium import openapi <spec> [-out <dir>].
+//
+// Parameters:
+//   - args: the command line arguments following "import".
+//
+// Returns an error if the arguments are invalid or the templates cannot be written.
+func runImport(args []string) error {
+	if len(args) == 0 || args[0] != "openapi" {
+		return fmt.Errorf("usage: mockium import openapi <spec> [-out <dir>]")
+	}
+
+	fs := flag.NewFlagSet("import openapi", flag.ContinueOnError)
+	out := fs.String("out", "templates", "directory the templates are written to, default './templates'")
+
+	// The flags may come before or after the specification file
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("usage: mockium import openapi <spec> [-out <dir>]")
+	}
+	spec := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return err
+	}
+
+	doc, err := openapi.Load(spec)
+	if err != nil {
+		return fmt.Errorf("%w, file: %s", err, spec)
+	}
+
+	templates, err := doc.Templates()
+	if err != nil {
+		return err
+	}
+
+	if err := builder.NewTemplateBuilder(zap.NewNop()).Validate(templates); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return err
+	}
+
+	names := make(map[string]struct{}, len(templates))
+	for _, template := range templates {
+		name := templateFileName(template.Path, names)
+		if err := writeTemplate(filepath.Join(*out, name), template); err != nil {
+			return err
+		}
+		fmt.Printf("%s -> %s\n", template.Path, filepath.Join(*out, name))
+	}
+
+	return nil
+}
+
+// templateFileName derives a unique file name from the template path, e.g. "users_id.json" for "/users/{id}".
+func templateFileName(path string, taken map[string]struct{}) string {
+	base := strings.Trim(unsafeFileChars.ReplaceAllString(path, "_"), "_")
+	if base == "" {
+		base = "root"
+	}
+
+	name := base + ".json"
+	for i := 2; ; i++ {
+		if _, ok := taken[name]; !ok {
+			break
+		}
+		name = base + "_" + strconv.Itoa(i) + ".json"
+	}
+
+	taken[name] = struct{}{}
+	return name
+}
+
+func writeTemplate(name string, template model.Template) error {
+	data, err := json.MarshalIndent(template, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0o644)
+}
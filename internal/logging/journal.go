@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"mockium/internal/model"
+	"sync"
+)
+
+// Journal keeps the most recent process log entries in memory, so that
+// served requests can be queried and verified at runtime.
+// When the journal is full, the oldest entry is dropped.
+type Journal struct {
+	mu      sync.Mutex
+	entries []*model.ProcessLoggingFileds // Ring buffer of entries.
+	start   int                           // Index of the oldest entry.
+	size    int                           // Number of stored entries.
+}
+
+// NewJournal creates a new Journal keeping at most maxEntries entries.
+func NewJournal(maxEntries int) *Journal {
+	return &Journal{
+		entries: make([]*model.ProcessLoggingFileds, maxEntries),
+	}
+}
+
+// Log adds the entry to the journal, dropping the oldest one if the journal is full.
+func (inst *Journal) Log(logFields *model.ProcessLoggingFileds) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if len(inst.entries) == 0 {
+		return
+	}
+
+	if inst.size < len(inst.entries) {
+		inst.entries[(inst.start+inst.size)%len(inst.entries)] = logFields
+		inst.size++
+		return
+	}
+
+	inst.entries[inst.start] = logFields
+	inst.start = (inst.start + 1) % len(inst.entries)
+}
+
+// Find returns the entries accepted by the predicate, from the oldest to the newest.
+// A nil predicate accepts all entries.
+func (inst *Journal) Find(accept func(*model.ProcessLoggingFileds) bool) []*model.ProcessLoggingFileds {
+	inst.mu.Lock()
+	entries := make([]*model.ProcessLoggingFileds, 0, inst.size)
+	for i := 0; i < inst.size; i++ {
+		entries = append(entries, inst.entries[(inst.start+i)%len(inst.entries)])
+	}
+	inst.mu.Unlock()
+
+	if accept == nil {
+		return entries
+	}
+
+	result := make([]*model.ProcessLoggingFileds, 0, len(entries))
+	for _, entry := range entries {
+		if accept(entry) {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// Clear removes all entries from the journal.
+func (inst *Journal) Clear() {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	for i := range inst.entries {
+		inst.entries[i] = nil
+	}
+	inst.start = 0
+	inst.size = 0
+}
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"mockium/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func entry(url string) *model.ProcessLoggingFileds {
+	return &model.ProcessLoggingFileds{Request: &model.LogginRequest{Url: url}}
+}
+
+func urls(entries []*model.ProcessLoggingFileds) []string {
+	result := make([]string, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.Request.Url)
+	}
+	return result
+}
+
+func TestJournal_Bounded(t *testing.T) {
+	journal := NewJournal(3)
+
+	for _, url := range []string{"/1", "/2", "/3", "/4", "/5"} {
+		journal.Log(entry(url))
+	}
+
+	assert.Equal(t, []string{"/3", "/4", "/5"}, urls(journal.Find(nil)))
+}
+
+func TestJournal_Find(t *testing.T) {
+	journal := NewJournal(10)
+	journal.Log(entry("/users"))
+	journal.Log(entry("/orders"))
+	journal.Log(entry("/users"))
+
+	found := journal.Find(func(e *model.ProcessLoggingFileds) bool {
+		return e.Request.Url == "/users"
+	})
+	assert.Len(t, found, 2)
+}
+
+func TestJournal_Clear(t *testing.T) {
+	journal := NewJournal(2)
+	journal.Log(entry("/1"))
+	journal.Log(entry("/2"))
+	journal.Log(entry("/3"))
+
+	journal.Clear()
+	assert.Empty(t, journal.Find(nil))
+
+	journal.Log(entry("/4"))
+	assert.Equal(t, []string{"/4"}, urls(journal.Find(nil)))
+}
+
+func TestJournal_Disabled(t *testing.T) {
+	journal := NewJournal(0)
+	journal.Log(entry("/1"))
+	assert.Empty(t, journal.Find(nil))
+}
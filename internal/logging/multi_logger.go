@@ -0,0 +1,26 @@
+package logging
+
+import (
+	"mockium/internal/model"
+	"mockium/internal/service"
+)
+
+// MultiLogger forwards every process log entry to several process loggers,
+// e.g. to the rotating log files and to the request journal.
+type MultiLogger struct {
+	loggers []service.ProcessLogger
+}
+
+// NewMultiLogger creates a new MultiLogger forwarding to the given loggers.
+func NewMultiLogger(loggers ...service.ProcessLogger) *MultiLogger {
+	return &MultiLogger{
+		loggers: loggers,
+	}
+}
+
+// Log forwards the entry to all loggers.
+func (inst *MultiLogger) Log(logFields *model.ProcessLoggingFileds) {
+	for _, logger := range inst.loggers {
+		logger.Log(logFields)
+	}
+}
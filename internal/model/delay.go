@@ -0,0 +1,56 @@
+package model
+
+import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Delay distributions supported by DelayTemplate.
+const (
+	DelayUniform   = "uniform"
+	DelayLognormal = "lognormal"
+	DelayNormal    = "normal"
+)
+
+// DelayTemplate describes how long to wait before sending a response.
+// Durations use the Go duration format (e.g. "150ms", "2s").
+//
+// Either Fixed or Distribution is set:
+//   - uniform: a value between Min and Max
+//   - lognormal: Median * e^(Sigma * N(0,1))
+//   - normal: Mean + StdDev * N(0,1), negative values are clamped to zero
+//
+// Max, if set, caps the lognormal and normal distributions.
+// A plain string value (e.g. "SetDelay": "200ms") is a fixed delay.
+type DelayTemplate struct {
+	Fixed        string  `yaml:"Fixed" json:"Fixed,omitempty"`
+	Distribution string  `yaml:"Distribution" json:"Distribution,omitempty"`
+	Min          string  `yaml:"Min" json:"Min,omitempty"`
+	Max          string  `yaml:"Max" json:"Max,omitempty"`
+	Median       string  `yaml:"Median" json:"Median,omitempty"`
+	Sigma        float64 `yaml:"Sigma" json:"Sigma,omitempty"`
+	Mean         string  `yaml:"Mean" json:"Mean,omitempty"`
+	StdDev       string  `yaml:"StdDev" json:"StdDev,omitempty"`
+}
+
+func (inst *DelayTemplate) UnmarshalJSON(data []byte) error {
+	var fixed string
+	if err := json.Unmarshal(data, &fixed); err == nil {
+		*inst = DelayTemplate{Fixed: fixed}
+		return nil
+	}
+
+	type Alias DelayTemplate
+	return json.Unmarshal(data, (*Alias)(inst))
+}
+
+func (inst *DelayTemplate) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*inst = DelayTemplate{Fixed: value.Value}
+		return nil
+	}
+
+	type Alias DelayTemplate
+	return value.Decode((*Alias)(inst))
+}
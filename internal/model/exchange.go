@@ -0,0 +1,18 @@
+package model
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Exchange is a request proxied to an upstream server together with the upstream response.
+type Exchange struct {
+	Method          string
+	Path            string
+	Query           url.Values
+	Headers         http.Header
+	Body            []byte
+	Status          int
+	ResponseHeaders http.Header
+	ResponseBody    []byte
+}
@@ -0,0 +1,21 @@
+package model
+
+// Fault types supported by FaultTemplate.
+const (
+	// FaultCloseConnection closes the connection without sending a response.
+	FaultCloseConnection = "closeConnection"
+	// FaultConnectionReset closes the connection with a TCP reset.
+	FaultConnectionReset = "connectionReset"
+	// FaultGarbage sends random bytes instead of an HTTP response and closes the connection.
+	FaultGarbage = "garbage"
+	// FaultTruncate advertises the full Content-Length but cuts the body off after TruncateAfter bytes.
+	FaultTruncate = "truncate"
+)
+
+// FaultTemplate describes a network failure injected instead of a regular response.
+type FaultTemplate struct {
+	Type          string   `yaml:"Type" json:"Type"`
+	Probability   *float64 `yaml:"Probability" json:"Probability,omitempty"`
+	TruncateAfter int      `yaml:"TruncateAfter" json:"TruncateAfter,omitempty"`
+	GarbageSize   int      `yaml:"GarbageSize" json:"GarbageSize,omitempty"`
+}
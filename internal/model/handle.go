@@ -1,6 +1,26 @@
 package model
 
+// End modes of a handle response list, used after its last response.
+const (
+	ResponsesLast     = "last"     // Keep returning the last response, the default.
+	ResponsesCycle    = "cycle"    // Start over from the first response.
+	ResponsesNotFound = "notFound" // Respond 404 Not Found.
+)
+
 type HandleTemplate struct {
-	MatchRequestTemplate MatchRequestTemplate `yaml:"MatchRequest" json:"MatchRequest"`
-	SetResponseTemplate  SetResponseTemplate  `yaml:"SetResponse" json:"SetResponse"`
+	Priority              int                  `yaml:"Priority" json:"Priority"`
+	Scenario              string               `yaml:"Scenario" json:"Scenario"`
+	RequiredScenarioState string               `yaml:"RequiredScenarioState" json:"RequiredScenarioState"`
+	NewScenarioState      string               `yaml:"NewScenarioState" json:"NewScenarioState"`
+	MatchRequestTemplate  MatchRequestTemplate `yaml:"MatchRequest" json:"MatchRequest"`
+	SetResponseTemplate   SetResponseTemplate  `yaml:"SetResponse" json:"SetResponse"`
+
+	// SetResponses replaces SetResponse with responses returned in turn, each one Repeat times.
+	// SetResponsesMode chooses what follows the last one, see ResponsesLast.
+	SetResponses     []SetResponseTemplate `yaml:"SetResponses" json:"SetResponses,omitempty"`
+	SetResponsesMode string                `yaml:"SetResponsesMode" json:"SetResponsesMode,omitempty"`
+
+	// SetRandomResponses replaces SetResponse with responses chosen at random for every request,
+	// in proportion to their Weight.
+	SetRandomResponses []SetResponseTemplate `yaml:"SetRandomResponses" json:"SetRandomResponses,omitempty"`
 }
@@ -3,15 +3,33 @@ package model
 import "time"
 
 type ProcessLoggingFileds struct {
-	Time     time.Time      `json:"time"`
-	Request  *LogginRequest `json:"request"`
-	Response SetResponse    `json:"response"`
+	Time        time.Time      `json:"time"`
+	Request     *LogginRequest `json:"request"`
+	Template    string         `json:"template,omitempty"`
+	HandleIndex *int           `json:"handle_index,omitempty"`
+	Variant     *int           `json:"variant,omitempty"`
+	Delay       string         `json:"delay,omitempty"`
+	Fault       string         `json:"fault,omitempty"`
+	Proxied     bool           `json:"proxied,omitempty"`
+	Violations  []Violation    `json:"violations,omitempty"`
+	Response    SetResponse    `json:"response"`
 }
 
 type LogginRequest struct {
-	Url        string         `json:"url"`
-	Method     string         `json:"method"`
-	RemoteAddr string         `json:"reqmote_addr"`
-	Headers    map[string]any `json:"headers"`
-	Body       any            `json:"body"`
+	Url        string            `json:"url"`
+	Method     string            `json:"method"`
+	RemoteAddr string            `json:"reqmote_addr"`
+	PathParams map[string]string `json:"path_params,omitempty"`
+	Headers    map[string]any    `json:"headers"`
+	Body       any               `json:"body"`
+}
+
+// JournalFilter describes the criteria used to query the request journal.
+// Empty fields are not checked.
+type JournalFilter struct {
+	Path                 string                `yaml:"Path" json:"Path"`
+	Method               string                `yaml:"Method" json:"Method"`
+	Template             string                `yaml:"Template" json:"Template"`
+	HandleIndex          *int                  `yaml:"Handle" json:"Handle"`
+	MatchRequestTemplate *MatchRequestTemplate `yaml:"MatchRequest" json:"MatchRequest"`
 }
@@ -0,0 +1,8 @@
+package model
+
+// ProxyTemplate forwards the request to an upstream instead of building a response.
+type ProxyTemplate struct {
+	URL           string            `yaml:"URL" json:"URL"`
+	AddHeaders    map[string]string `yaml:"AddHeaders" json:"AddHeaders,omitempty"`
+	RemoveHeaders []string          `yaml:"RemoveHeaders" json:"RemoveHeaders,omitempty"`
+}
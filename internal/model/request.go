@@ -6,6 +6,34 @@ type MatchRequestTemplate struct {
 	MustPathParameters  map[string]any `yaml:"MustPathParameters" json:"MustPathParameters"`
 	MustQueryParameters map[string]any `yaml:"MustQueryParameters" json:"MustQueryParameters"`
 	MustBody            map[string]any `yaml:"MustBodyParameters" json:"MustBodyParameters"`
+
+	// MustBodySchema is a JSON Schema the JSON body must satisfy,
+	// given inline or as the path of a JSON or YAML schema file.
+	MustBodySchema any `yaml:"MustBodySchema" json:"MustBodySchema,omitempty"`
+
+	// MustBodyPaths are conditions on the values a JSONPath expression selects from the body.
+	MustBodyPaths []BodyPathTemplate `yaml:"MustBodyPaths" json:"MustBodyPaths,omitempty"`
+}
+
+// Operators of a body path condition.
+const (
+	BodyPathEquals   = "equals"   // A selected value equals Value, placeholders are allowed.
+	BodyPathContains = "contains" // A selected string contains Value, or a selected array contains an element equal to Value.
+	BodyPathMatches  = "matches"  // A selected value matches the regular expression Value.
+	BodyPathExists   = "exists"   // The path selects at least one value.
+	BodyPathAbsent   = "absent"   // The path selects no value.
+	BodyPathGt       = "gt"       // A selected number is greater than Value.
+	BodyPathLt       = "lt"       // A selected number is less than Value.
+	BodyPathIn       = "in"       // A selected value equals one of the values of the Value list.
+)
+
+// BodyPathTemplate is a condition on the values a JSONPath expression selects from the JSON body,
+// or an XPath expression (a Path starting with "/") selects from the XML body.
+// The condition holds when any selected value satisfies it.
+type BodyPathTemplate struct {
+	Path  string `yaml:"Path" json:"Path"`
+	Op    string `yaml:"Op" json:"Op"`
+	Value any    `yaml:"Value" json:"Value,omitempty"`
 }
 
 type Request struct {
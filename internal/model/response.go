@@ -4,26 +4,66 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 type SetResponse struct {
-	SetStatus  int
-	SetHeaders map[string]string
-	SetBody    map[string]any
-	SetFile    *os.File
+	SetStatus   int
+	SetHeaders  map[string]string
+	SetBody     any
+	SetRawBody  string `json:",omitempty"`
+	SetFile     *os.File
+	SetStream   *Stream        `json:",omitempty"`
+	SetDelay    time.Duration  `json:"-"`
+	SetFault    *FaultTemplate `json:"-"`
+	SetThrottle *Throttle      `json:"-"`
+	SetVariant  *int           `json:"-"` // Index of the response chosen from SetResponses or SetRandomResponses.
+
+	SetHeaderValues map[string][]string `json:",omitempty"` // Headers sent with several values, e.g. Set-Cookie.
 }
 
 type SetResponseTemplate struct {
-	SetStatus  int               `yaml:"SetStatus" json:"SeStatus"`
+	SetStatus  int               `yaml:"SetStatus" json:"SetStatus"`
 	SetHeaders map[string]string `yaml:"SetHeaders" json:"SetHeaders"`
-	SetBody    map[string]any    `yaml:"SetBody" json:"SetBody"`
+	SetBody    any               `yaml:"SetBody" json:"SetBody"`
+	SetRawBody string            `yaml:"SetRawBody" json:"SetRawBody,omitempty"`
 	SetFile    string            `yaml:"SetFile" json:"SetFile"`
+	SetDelay   *DelayTemplate    `yaml:"SetDelay" json:"SetDelay,omitempty"`
+	SetFault   *FaultTemplate    `yaml:"SetFault" json:"SetFault,omitempty"`
+	SetProxy   *ProxyTemplate    `yaml:"SetProxy" json:"SetProxy,omitempty"`
+
+	// SetHeaderValues sets headers with several values, e.g. a Set-Cookie for every cookie.
+	SetHeaderValues map[string][]string `yaml:"SetHeaderValues" json:"SetHeaderValues,omitempty"`
+
+	// SetStream responds with a Server-Sent Events stream.
+	SetStream *StreamTemplate `yaml:"SetStream" json:"SetStream,omitempty"`
+
+	// SetThrottle sends the body slowly, at a limited rate or in chunks.
+	SetThrottle *ThrottleTemplate `yaml:"SetThrottle" json:"SetThrottle,omitempty"`
+
+	// SetSOAPFault responds with a SOAP Fault envelope, by default with status 500.
+	SetSOAPFault *SOAPFaultTemplate `yaml:"SetSOAPFault" json:"SetSOAPFault,omitempty"`
+
+	// SetTemplate enables rendering of SetBody strings, SetRawBody, SetHeaders values
+	// and SetStatusTemplate with text/template.
+	SetTemplate       bool   `yaml:"SetTemplate" json:"SetTemplate,omitempty"`
+	SetStatusTemplate string `yaml:"SetStatusTemplate" json:"SetStatusTemplate,omitempty"`
+
+	// Repeat is the number of consecutive requests a response of SetResponses is returned for, default 1.
+	Repeat int `yaml:"Repeat" json:"Repeat,omitempty"`
+	// Weight is the relative chance of a response of SetRandomResponses to be chosen, default 1.
+	Weight float64 `yaml:"Weight" json:"Weight,omitempty"`
 }
 
+// UnmarshalJSON decodes the response template and checks its body parameters.
+// SeStatus, the former misspelled JSON key of SetStatus, is still accepted.
 func (inst *SetResponseTemplate) UnmarshalJSON(data []byte) error {
 	type Alias SetResponseTemplate
 	aux := &struct {
 		*Alias
+		SeStatus *int `json:"SeStatus"`
 	}{
 		Alias: (*Alias)(inst),
 	}
@@ -31,9 +71,45 @@ func (inst *SetResponseTemplate) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	if inst.SetFile != "" && inst.SetBody != nil {
-		return fmt.Errorf("cannot use parameter 'SetBody' with 'SetFile'")
+	if aux.SeStatus != nil {
+		if inst.SetStatus != 0 {
+			return fmt.Errorf("cannot use parameter 'SeStatus' with 'SetStatus'")
+		}
+		inst.SetStatus = *aux.SeStatus
+	}
+
+	return inst.CheckBody()
+}
+
+func (inst *SetResponseTemplate) UnmarshalYAML(value *yaml.Node) error {
+	type Alias SetResponseTemplate
+	if err := value.Decode((*Alias)(inst)); err != nil {
+		return err
+	}
+
+	if err := inst.CheckBody(); err != nil {
+		return fmt.Errorf("line %d: %w", value.Line, err)
 	}
 
 	return nil
 }
+
+// CheckBody verifies that at most one of SetBody, SetRawBody and SetFile is set,
+// and none of them together with SetProxy, SetSOAPFault or SetStream.
+func (inst *SetResponseTemplate) CheckBody() error {
+	switch {
+	case inst.SetBody != nil && inst.SetFile != "":
+		return fmt.Errorf("cannot use parameter 'SetBody' with 'SetFile'")
+	case inst.SetBody != nil && inst.SetRawBody != "":
+		return fmt.Errorf("cannot use parameter 'SetBody' with 'SetRawBody'")
+	case inst.SetRawBody != "" && inst.SetFile != "":
+		return fmt.Errorf("cannot use parameter 'SetRawBody' with 'SetFile'")
+	case inst.SetProxy != nil && (inst.SetBody != nil || inst.SetRawBody != "" || inst.SetFile != ""):
+		return fmt.Errorf("cannot use parameter 'SetProxy' with 'SetBody', 'SetRawBody' or 'SetFile'")
+	case inst.SetSOAPFault != nil && (inst.SetBody != nil || inst.SetRawBody != "" || inst.SetFile != "" || inst.SetProxy != nil):
+		return fmt.Errorf("cannot use parameter 'SetSOAPFault' with 'SetBody', 'SetRawBody', 'SetFile' or 'SetProxy'")
+	case inst.SetStream != nil && (inst.SetBody != nil || inst.SetRawBody != "" || inst.SetFile != "" || inst.SetProxy != nil || inst.SetSOAPFault != nil):
+		return fmt.Errorf("cannot use parameter 'SetStream' with 'SetBody', 'SetRawBody', 'SetFile', 'SetProxy' or 'SetSOAPFault'")
+	}
+	return nil
+}
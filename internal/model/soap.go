@@ -0,0 +1,15 @@
+package model
+
+// SOAP versions of a SOAP Fault response.
+const (
+	SOAP11 = "1.1"
+	SOAP12 = "1.2"
+)
+
+// SOAPFaultTemplate describes a SOAP Fault response.
+type SOAPFaultTemplate struct {
+	Version string `yaml:"Version" json:"Version,omitempty"` // SOAP version, "1.1" by default or "1.2".
+	Code    string `yaml:"Code" json:"Code,omitempty"`       // Fault code, e.g. "Client" for SOAP 1.1 or "Sender" for SOAP 1.2, a server fault by default.
+	Reason  string `yaml:"Reason" json:"Reason"`             // Human readable fault description.
+	Detail  any    `yaml:"Detail" json:"Detail,omitempty"`   // Application specific detail elements or text.
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// StreamTemplate describes a Server-Sent Events stream sent instead of a body.
+// Durations use the Go duration format (e.g. "500ms", "15s").
+type StreamTemplate struct {
+	Events    []StreamEventTemplate `yaml:"Events" json:"Events"`
+	Repeat    int                   `yaml:"Repeat" json:"Repeat,omitempty"`       // Number of times the events are sent, default 1.
+	Loop      bool                  `yaml:"Loop" json:"Loop,omitempty"`           // Send the events over and over until the client disconnects.
+	KeepAlive string                `yaml:"KeepAlive" json:"KeepAlive,omitempty"` // Interval of keep-alive comments while waiting, none if empty.
+}
+
+// StreamEventTemplate is a single event of a stream. Data may be a string or any JSON value,
+// which is sent as JSON; placeholders and templates are resolved in strings like in SetBody.
+type StreamEventTemplate struct {
+	Event string `yaml:"Event" json:"Event,omitempty"`
+	Data  any    `yaml:"Data" json:"Data"`
+	ID    string `yaml:"ID" json:"ID,omitempty"`
+	Delay string `yaml:"Delay" json:"Delay,omitempty"` // Wait before the event is sent.
+}
+
+// Stream is a Server-Sent Events stream built for a request.
+type Stream struct {
+	Events    []StreamEvent
+	Repeat    int
+	Loop      bool
+	KeepAlive time.Duration
+}
+
+// StreamEvent is a single event of a built stream, with its data rendered.
+type StreamEvent struct {
+	Event string
+	Data  string
+	ID    string
+	Delay time.Duration
+}
@@ -1,6 +1,16 @@
 package model
 
+// UpgradeWebSocket is the Upgrade of a template serving WebSocket connections.
+const UpgradeWebSocket = "websocket"
+
 type Template struct {
+	ID     string           `yaml:"ID" json:"ID"`
 	Path   string           `yaml:"Path" json:"Path"`
 	Handle []HandleTemplate `yaml:"Handle" json:"Handle"`
+	Proxy  string           `yaml:"Proxy" json:"Proxy,omitempty"`
+
+	// Upgrade set to UpgradeWebSocket serves the WebSocket handshakes to the path,
+	// the connections run the WebSocket script.
+	Upgrade   string             `yaml:"Upgrade" json:"Upgrade,omitempty"`
+	WebSocket *WebSocketTemplate `yaml:"WebSocket" json:"WebSocket,omitempty"`
 }
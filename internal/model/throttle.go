@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// ThrottleTemplate slows down the body of a SetBody, SetRawBody or SetFile response.
+// BytesPerSecond caps the throughput, Chunks splits the body into equal chunks sent
+// with ChunkDelay pauses between them; both can be combined. ChunkDelay uses the
+// Go duration format (e.g. "500ms").
+type ThrottleTemplate struct {
+	BytesPerSecond int64  `yaml:"BytesPerSecond" json:"BytesPerSecond,omitempty"`
+	Chunks         int    `yaml:"Chunks" json:"Chunks,omitempty"`
+	ChunkDelay     string `yaml:"ChunkDelay" json:"ChunkDelay,omitempty"`
+}
+
+// Throttle is a compiled ThrottleTemplate.
+type Throttle struct {
+	BytesPerSecond int64         // Maximum throughput of the body, 0 for no limit.
+	Chunks         int           // Number of chunks the body is sent in, 0 to send it at once.
+	ChunkDelay     time.Duration // Pause between the chunks.
+}
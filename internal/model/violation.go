@@ -0,0 +1,8 @@
+package model
+
+// Violation describes a part of a request that does not satisfy the API description.
+type Violation struct {
+	In      string `json:"in"`             // Location of the value: "path", "query", "header" or "body".
+	Name    string `json:"name,omitempty"` // Parameter name, or the JSON pointer of a body value.
+	Message string `json:"message"`        // Description of the violated constraint.
+}
@@ -0,0 +1,43 @@
+package model
+
+// WebSocketTemplate is the script run for every WebSocket connection of a template.
+// Messages are strings sent as they are, or other JSON values sent as JSON, and resolve
+// placeholders like SetBody. In replies, the incoming message is the request body,
+// e.g. ${req.body:id}. Durations use the Go duration format (e.g. "500ms", "15s").
+type WebSocketTemplate struct {
+	SetTemplate bool                       `yaml:"SetTemplate" json:"SetTemplate,omitempty"` // Render the messages with text/template.
+	OnConnect   []WebSocketMessageTemplate `yaml:"OnConnect" json:"OnConnect,omitempty"`     // Messages sent once the connection is open.
+	OnMessage   []WebSocketReplyTemplate   `yaml:"OnMessage" json:"OnMessage,omitempty"`     // Replies to incoming messages, the first match is used.
+	Timers      []WebSocketTimerTemplate   `yaml:"Timers" json:"Timers,omitempty"`           // Messages sent periodically.
+	Close       *WebSocketCloseTemplate    `yaml:"Close" json:"Close,omitempty"`             // Close of the connection by the server, nil to wait for the client.
+}
+
+// WebSocketMessageTemplate is a message sent by the server.
+type WebSocketMessageTemplate struct {
+	Message any    `yaml:"Message" json:"Message"`
+	Delay   string `yaml:"Delay" json:"Delay,omitempty"` // Wait before the message is sent.
+}
+
+// WebSocketReplyTemplate replies to the incoming messages matching MustMessage.
+// A string MustMessage is compared with the text of the message, another value with
+// the message decoded as JSON; placeholders such as ${regexp:...} are supported.
+// Without MustMessage, every message matches.
+type WebSocketReplyTemplate struct {
+	MustMessage any                        `yaml:"MustMessage" json:"MustMessage,omitempty"`
+	Reply       []WebSocketMessageTemplate `yaml:"Reply" json:"Reply,omitempty"`
+	Close       *WebSocketCloseTemplate    `yaml:"Close" json:"Close,omitempty"` // Close of the connection after the reply.
+}
+
+// WebSocketTimerTemplate sends a message at every interval.
+type WebSocketTimerTemplate struct {
+	Every   string `yaml:"Every" json:"Every"`
+	Times   int    `yaml:"Times" json:"Times,omitempty"` // Number of messages sent, 0 until the connection is closed.
+	Message any    `yaml:"Message" json:"Message"`
+}
+
+// WebSocketCloseTemplate closes the connection with a close code.
+type WebSocketCloseTemplate struct {
+	Code   int    `yaml:"Code" json:"Code,omitempty"` // Close code, 1000 (normal closure) if 0.
+	Reason string `yaml:"Reason" json:"Reason,omitempty"`
+	After  string `yaml:"After" json:"After,omitempty"` // Wait before closing, from the connection or the reply.
+}
@@ -6,58 +6,195 @@ import (
 	"mockium/internal/service/matcher"
 	"mockium/internal/transport"
 	"mockium/internal/transport/handler"
+	"mockium/internal/transport/proxy"
 	"mockium/internal/transport/route"
 	"net/http"
 
 	"go.uber.org/zap"
 )
 
+// Options holds the settings shared by all routes built from templates.
+type Options struct {
+	Delay *model.DelayTemplate // Delay of the responses without their own SetDelay, nil for none.
+	Proxy http.Handler         // Upstream of the requests no handle matches in templates without their own Proxy, nil for none.
+
+	// Sequences receives the response lists of the handles with SetResponses, so that they can be reset, nil for none.
+	Sequences service.SequenceStore
+}
+
+// proxyMethods are the methods forwarded to the proxy of a template, for every one the template has no handles for.
+var proxyMethods = []model.Method{
+	model.GET, model.POST, model.DELETE, model.PATCH, model.PUT,
+	http.MethodHead, http.MethodOptions, http.MethodConnect, http.MethodTrace,
+}
+
 // Build is a function type that constructs a router from a template.
-// It takes a logger for logging purposes and a template defining the routing rules,
-// and returns an implementation of transport.Router.
-type Build func(log *zap.Logger, procLogger service.ProcessLogger, template *model.Template) transport.Router
+// It takes a logger for logging purposes, the process logger, scenario store and options
+// shared by all routes, and a template defining the routing rules, and returns an
+// implementation of transport.Router.
+type Build func(log *zap.Logger, procLogger service.ProcessLogger, scenarios service.ScenarioStore, options Options, template *model.Template) transport.Router
 
 // BuildRoutes is the default implementation of the Build function.
 // It creates a router with request matchers and response builders based on the provided template.
 //
 // The function performs the following steps:
-// 1. Creates a two-level mapping of HTTP methods to request matchers and their corresponding response builders
-// 2. Processes each handle from the template to populate the matchers map
-// 3. Creates HTTP handlers for each method using the configured matchers
-// 4. Returns a new router configured with the path and handlers from the template
+// 1. Groups the template handles by HTTP method, keeping their template order and index
+// 2. Pairs each handle's request matcher with its response builder and priority,
+// a handle with SetResponses gets a response list registered in options.Sequences,
+// which continues from the position of the list it replaces if the list did not change,
+// a handle with SetRandomResponses gets a random choice of responses,
+// a handle with SetProxy gets its reverse proxy
+// 3. Creates HTTP handlers for each method using the ordered handles
+// 4. If the template or the options set a proxy, forwards the requests no handle matches,
+// including the requests with a method the template has no handles for, to the upstream
+// 5. If the template upgrades to WebSocket, serves the handshakes with the WebSocket script
+// 6. Returns a new router configured with the path and handlers from the template
 //
 // Parameters:
 //   - log: Logger instance for logging operations
+//   - procLogger: Process logger receiving every served request
+//   - scenarios: Scenario state store, the template scenarios are registered in it
+//   - options: Settings shared by all routes, e.g. the global response delay
 //   - template: Routing template containing path, handles and response configurations
 //
 // Returns:
 //   - Configured router implementing transport.Router interface
-var BuildRoutes Build = func(log *zap.Logger, procLogger service.ProcessLogger, template *model.Template) transport.Router {
-	// matchersMap is a two-level map:
-	// 1st level: HTTP method (e.g., GET, POST)
-	// 2nd level: Map of request matchers to their response builders
-	matchersMap := make(map[model.Method]map[transport.RequestMatcher]transport.ResponseBuilder)
+var BuildRoutes Build = func(log *zap.Logger, procLogger service.ProcessLogger, scenarios service.ScenarioStore, options Options, template *model.Template) transport.Router {
+	// handlesMap groups the template handles by HTTP method,
+	// preserving the order in which they appear in the template
+	handlesMap := make(map[model.Method][]transport.Handle)
 
 	// handlers stores the final HTTP handlers for each method
 	handlers := make(map[model.Method]http.Handler)
 
+	// sequences collects the response lists of the handles with SetResponses by handle index
+	sequences := make(map[int]service.ResponseSequence)
+
 	// Process each handle definition from the template
-	for _, handle := range template.Handle {
-		// Initialize the inner map if it doesn't exist for this method
-		if _, exists := matchersMap[handle.MatchRequestTemplate.MustMethod]; !exists {
-			matchersMap[handle.MatchRequestTemplate.MustMethod] = make(map[transport.RequestMatcher]transport.ResponseBuilder)
+	for idx, handle := range template.Handle {
+		if handle.Scenario != "" {
+			scenarios.Register(handle.Scenario)
 		}
 
-		// Add the matcher and response builder pair to the map
-		matchersMap[handle.MatchRequestTemplate.MustMethod][matcher.NewRequestMatcher(log, &handle.MatchRequestTemplate)] =
-			NewResponseBuilder(handle.SetResponseTemplate)
+		responseTemplate := handle.SetResponseTemplate
+		if responseTemplate.SetDelay == nil {
+			responseTemplate.SetDelay = options.Delay
+		}
+
+		responseBuilder := NewResponseBuilder(responseTemplate)
+		switch {
+		case len(handle.SetResponses) > 0:
+			responseBuilder = NewSequenceBuilder(withDelay(handle.SetResponses, options.Delay), handle.SetResponsesMode)
+			sequences[idx] = responseBuilder
+		case len(handle.SetRandomResponses) > 0:
+			responseBuilder = NewRandomBuilder(withDelay(handle.SetRandomResponses, options.Delay))
+		}
+
+		// A handle whose proxy cannot be created fails its requests instead of answering an empty response
+		reverseProxy, err := handleProxy(log, responseTemplate.SetProxy)
+		if err != nil {
+			log.Error("create handle proxy", zap.String("template", template.ID), zap.Int("handle", idx), zap.Error(err))
+			responseBuilder = &ResponseBuilder{templResp: responseTemplate, err: err}
+		}
+
+		handlesMap[handle.MatchRequestTemplate.MustMethod] = append(handlesMap[handle.MatchRequestTemplate.MustMethod], transport.Handle{
+			Index:                 idx,
+			Priority:              handle.Priority,
+			Scenario:              handle.Scenario,
+			RequiredScenarioState: handle.RequiredScenarioState,
+			NewScenarioState:      handle.NewScenarioState,
+			Matcher:               matcher.NewRequestMatcher(log, &handle.MatchRequestTemplate),
+			Builder:               responseBuilder,
+			Proxy:                 reverseProxy,
+		})
+	}
+
+	// Register the response lists even if there are none, so that the lists of removed handles are dropped
+	if options.Sequences != nil {
+		options.Sequences.Register(template.ID, sequences)
+	}
+
+	fallback := options.Proxy
+	if template.Proxy != "" {
+		if templateProxy, err := proxy.NewReverseProxy(log, template.Proxy); err != nil {
+			log.Error("create template proxy", zap.String("template", template.ID), zap.Error(err))
+		} else {
+			fallback = templateProxy
+		}
 	}
 
-	// Create handlers for each method using the configured matchers
-	for mth, mtch := range matchersMap {
-		handlers[mth] = handler.New(log, procLogger, mtch)
+	// Create handlers for each method using the configured handles
+	for mth, handles := range handlesMap {
+		handlers[mth] = handler.New(log, procLogger, scenarios, template.ID, fallback, handles)
+	}
+
+	// Forward the methods without handles as well, a handler without handles proxies every request
+	if fallback != nil {
+		for _, mth := range proxyMethods {
+			if _, ok := handlers[mth]; !ok {
+				handlers[mth] = handler.New(log, procLogger, scenarios, template.ID, fallback, nil)
+			}
+		}
+	}
+
+	// Serve the WebSocket handshakes, other GET requests go to the GET handles or the proxy
+	if template.Upgrade == model.UpgradeWebSocket && template.WebSocket != nil {
+		handlers[model.GET] = handler.NewWebSocket(log, procLogger, template.ID, NewWebSocketScript(log, template.WebSocket), handlers[model.GET])
 	}
 
 	// Create and return a new router with the configured path and handlers
 	return route.New(template.Path, handlers)
 }
+
+// withDelay returns a copy of the responses, the ones without SetDelay get the given delay.
+func withDelay(responses []model.SetResponseTemplate, delay *model.DelayTemplate) []model.SetResponseTemplate {
+	result := make([]model.SetResponseTemplate, 0, len(responses))
+	for _, response := range responses {
+		if response.SetDelay == nil {
+			response.SetDelay = delay
+		}
+		result = append(result, response)
+	}
+	return result
+}
+
+// handleProxy creates the reverse proxy of a handle with SetProxy.
+// Returns nil if the handle builds its own response, or an error if the proxy cannot be created.
+func handleProxy(log *zap.Logger, template *model.ProxyTemplate) (http.Handler, error) {
+	if template == nil {
+		return nil, nil
+	}
+
+	reverseProxy, err := proxy.NewHandleProxy(log, template)
+	if err != nil {
+		return nil, err
+	}
+	return reverseProxy, nil
+}
+
+// BuildAll builds a router for each template with BuildRoutes. The response lists
+// of the templates that are not in the set anymore, e.g. deleted ones, are dropped
+// from options.Sequences.
+//
+// Parameters:
+//   - log: Logger instance for logging operations
+//   - procLogger: Process logger receiving every served request
+//   - scenarios: Scenario state store, the template scenarios are registered in it
+//   - options: Settings shared by all routes
+//   - templates: Routing templates, the complete set served
+//
+// Returns:
+//   - A router for each template, in the order of the templates
+func BuildAll(log *zap.Logger, procLogger service.ProcessLogger, scenarios service.ScenarioStore, options Options, templates []model.Template) []transport.Router {
+	routes := make([]transport.Router, 0, len(templates))
+	ids := make([]string, 0, len(templates))
+	for _, template := range templates {
+		routes = append(routes, BuildRoutes(log, procLogger, scenarios, options, &template))
+		ids = append(ids, template.ID)
+	}
+
+	if options.Sequences != nil {
+		options.Sequences.Retain(ids)
+	}
+	return routes
+}
@@ -0,0 +1,56 @@
+package builder
+
+import (
+	"mockium/internal/model"
+	"mockium/internal/service/scenario"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestBuildRoutes_ProxyMethods(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Method", r.Method)
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer upstream.Close()
+
+	route := BuildRoutes(zap.NewNop(), nopProcessLogger{}, scenario.New(), Options{}, &model.Template{
+		Path:  "/users",
+		Proxy: upstream.URL,
+		Handle: []model.HandleTemplate{{
+			MatchRequestTemplate: model.MatchRequestTemplate{MustMethod: model.GET},
+			SetResponseTemplate:  model.SetResponseTemplate{SetStatus: http.StatusOK},
+		}},
+	})
+
+	for _, method := range []string{http.MethodPost, http.MethodHead, http.MethodOptions, http.MethodTrace} {
+		t.Run(method, func(t *testing.T) {
+			h := route.Handler(model.Method(method))
+			require.NotNil(t, h)
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(method, "/users", nil))
+			assert.Equal(t, http.StatusTeapot, rec.Code)
+			assert.Equal(t, method, rec.Header().Get("X-Method"))
+		})
+	}
+}
+
+func TestBuildRoutes_InvalidHandleProxy(t *testing.T) {
+	route := BuildRoutes(zap.NewNop(), nopProcessLogger{}, scenario.New(), Options{}, &model.Template{
+		Path: "/users",
+		Handle: []model.HandleTemplate{{
+			MatchRequestTemplate: model.MatchRequestTemplate{MustMethod: model.GET},
+			SetResponseTemplate:  model.SetResponseTemplate{SetProxy: &model.ProxyTemplate{URL: "localhost:8080"}},
+		}},
+	})
+
+	rec := httptest.NewRecorder()
+	route.Handler(model.GET).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
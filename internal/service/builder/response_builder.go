@@ -1,13 +1,22 @@
 package builder
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mockium/internal/model"
+	"mockium/internal/service"
 	"mockium/internal/service/constants"
+	"mockium/internal/service/delay"
+	"mockium/internal/service/formdata"
+	"mockium/internal/service/random"
+	"mockium/internal/service/render"
 	"net/http"
 	"os"
+	"strconv"
+	"sync"
+	"text/template"
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
@@ -17,8 +26,26 @@ import (
 // based on a provided response template. It supports dynamic value substitution
 // using placeholders from the incoming HTTP request.
 type ResponseBuilder struct {
-	log       *zap.Logger               // Logger for error or debug output (optional, not used in current logic).
-	templResp model.SetResponseTemplate // Template used to build the response.
+	log       *zap.Logger                   // Logger for error or debug output (optional, not used in current logic).
+	templResp model.SetResponseTemplate     // Template used to build the response.
+	delay     *delay.Delay                  // Compiled response delay, nil if the response is not delayed.
+	throttle  *model.Throttle               // Compiled body throttle, nil if the body is sent at once.
+	templates map[string]*template.Template // Parsed text/templates by their text, nil if the response is not templated.
+	variants  []*ResponseBuilder            // Builders of the SetResponses or SetRandomResponses of a handle, nil for a single response.
+	sequence  *sequence                     // Position in the SetResponses, nil unless the variants are returned in turn.
+	weights   []float64                     // Cumulative weights of the SetRandomResponses, nil unless the variants are chosen at random.
+	err       error                         // Error compiling the response delay or templates.
+}
+
+// sequence is the state of a response list, the SetResponses of a handle.
+// The position is kept under a lock, so concurrent requests get consecutive responses.
+type sequence struct {
+	mu      sync.Mutex
+	repeats []int  // Number of requests each response is returned for.
+	total   int    // Sum of the repeats, the length of one pass over the list.
+	mode    string // What follows the last response, see model.ResponsesLast.
+	key     string // The responses and the mode encoded, a rebuilt list only continues an equal one.
+	calls   int    // Number of responses built since the start or the last reset.
 }
 
 // NewResponseBuilder creates a new instance of ResponseBuilder with the given response template.
@@ -28,9 +55,176 @@ type ResponseBuilder struct {
 //
 // Returns a pointer to a ResponseBuilder.
 func NewResponseBuilder(templResp model.SetResponseTemplate) *ResponseBuilder {
+	templResp, err := withSOAPFault(templResp)
+	if err != nil {
+		return &ResponseBuilder{templResp: templResp, err: err}
+	}
+
+	d, err := delay.New(templResp.SetDelay)
+	if err != nil {
+		return &ResponseBuilder{templResp: templResp, err: err}
+	}
+
+	if err := checkStream(templResp.SetStream); err != nil {
+		return &ResponseBuilder{templResp: templResp, err: err}
+	}
+
+	throttle, err := newThrottle(templResp.SetThrottle)
+	if err != nil {
+		return &ResponseBuilder{templResp: templResp, err: err}
+	}
+
+	templates, err := compileTemplates(templResp)
 	return &ResponseBuilder{
 		templResp: templResp,
+		delay:     d,
+		throttle:  throttle,
+		templates: templates,
+		err:       err,
+	}
+}
+
+// NewSequenceBuilder creates a ResponseBuilder returning the responses in turn,
+// each one for Repeat consecutive requests.
+//
+// Parameters:
+//   - templResps: the listed responses, SetResponses of a handle.
+//   - mode: what follows the last response, model.ResponsesLast if empty.
+//
+// Returns a pointer to a ResponseBuilder.
+func NewSequenceBuilder(templResps []model.SetResponseTemplate, mode string) *ResponseBuilder {
+	if mode == "" {
+		mode = model.ResponsesLast
+	}
+
+	builder := newVariantsBuilder("SetResponses", templResps)
+	builder.sequence = &sequence{mode: mode}
+	if key, err := json.Marshal(map[string]any{"responses": templResps, "mode": mode}); err == nil {
+		builder.sequence.key = string(key)
+	}
+	for _, templResp := range templResps {
+		repeat := max(templResp.Repeat, 1)
+		builder.sequence.repeats = append(builder.sequence.repeats, repeat)
+		builder.sequence.total += repeat
+	}
+	return builder
+}
+
+// NewRandomBuilder creates a ResponseBuilder choosing one of the responses at random
+// for every request, in proportion to their Weight.
+//
+// Parameters:
+//   - templResps: the weighted responses, SetRandomResponses of a handle.
+//
+// Returns a pointer to a ResponseBuilder.
+func NewRandomBuilder(templResps []model.SetResponseTemplate) *ResponseBuilder {
+	builder := newVariantsBuilder("SetRandomResponses", templResps)
+
+	total := 0.0
+	for _, templResp := range templResps {
+		weight := templResp.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		if weight < 0 && builder.err == nil {
+			builder.err = fmt.Errorf("parameter 'Weight' must not be negative")
+		}
+
+		total += weight
+		builder.weights = append(builder.weights, total)
+	}
+	return builder
+}
+
+// newVariantsBuilder creates the builders of the responses of a handle response list.
+// The first error of the responses, or of an empty list, is the error of the list.
+func newVariantsBuilder(name string, templResps []model.SetResponseTemplate) *ResponseBuilder {
+	builder := &ResponseBuilder{}
+	if len(templResps) == 0 {
+		builder.err = fmt.Errorf("parameter '%s' is empty", name)
+	}
+
+	for _, templResp := range templResps {
+		variant := NewResponseBuilder(templResp)
+		if variant.err != nil && builder.err == nil {
+			builder.err = variant.err
+		}
+		builder.variants = append(builder.variants, variant)
+	}
+	return builder
+}
+
+// Reset moves a response list back to its first response, other responses are not affected.
+func (inst *ResponseBuilder) Reset() {
+	if inst.sequence == nil {
+		return
+	}
+
+	inst.sequence.mu.Lock()
+	defer inst.sequence.mu.Unlock()
+	inst.sequence.calls = 0
+}
+
+// Continue takes over the position of the response list built for the same handle
+// before the templates were rebuilt, so that a reload does not start the list over.
+// The position is shared with the previous list, and only taken over if its responses
+// and mode did not change. It must be called before the builder serves requests.
+func (inst *ResponseBuilder) Continue(previous service.ResponseSequence) {
+	prev, ok := previous.(*ResponseBuilder)
+	if !ok || prev.sequence == nil || inst.sequence == nil {
+		return
 	}
+
+	if inst.sequence.key != "" && inst.sequence.key == prev.sequence.key {
+		inst.sequence = prev.sequence
+	}
+}
+
+// variant chooses the index of the next response of a response list:
+// the next one in turn, or a random one by weight.
+//
+// Returns -1 if the list is exhausted and ends with model.ResponsesNotFound.
+func (inst *ResponseBuilder) variant() int {
+	if inst.sequence != nil {
+		return inst.sequence.next()
+	}
+
+	r := random.Float64() * inst.weights[len(inst.weights)-1]
+	for i, weight := range inst.weights {
+		if r < weight {
+			return i
+		}
+	}
+	return len(inst.weights) - 1
+}
+
+// next returns the index of the next listed response, -1 if the list
+// is exhausted and ends with model.ResponsesNotFound.
+func (inst *sequence) next() int {
+	inst.mu.Lock()
+	call := inst.calls
+	switch {
+	case inst.mode == model.ResponsesCycle:
+		inst.calls = (call + 1) % inst.total
+	case call < inst.total:
+		inst.calls++
+	}
+	inst.mu.Unlock()
+
+	if call >= inst.total {
+		if inst.mode == model.ResponsesNotFound {
+			return -1
+		}
+		return len(inst.repeats) - 1
+	}
+
+	for i, repeat := range inst.repeats {
+		if call < repeat {
+			return i
+		}
+		call -= repeat
+	}
+	return len(inst.repeats) - 1
 }
 
 // Build constructs a model.SetResponse object from the template and the provided HTTP request.
@@ -40,14 +234,61 @@ func NewResponseBuilder(templResp model.SetResponseTemplate) *ResponseBuilder {
 //   - req: the incoming HTTP request used for extracting dynamic values.
 //
 // Returns a constructed SetResponse object or an error if placeholder resolution fails.
+// With SetTemplate enabled, the body, headers and status are rendered with text/template first.
+// The response delay, if any, is sampled for every call, and so is whether the
+// configured fault fires. The body throttle is passed on as compiled. A response
+// list builds its next or a random response, recording its index in SetVariant,
+// or a 404 Not Found response once it is exhausted and ends with
+// model.ResponsesNotFound.
 func (inst *ResponseBuilder) Build(req *http.Request) (*model.SetResponse, error) {
+	if inst.err != nil {
+		return nil, inst.err
+	}
+
+	if inst.variants != nil {
+		i := inst.variant()
+		if i < 0 {
+			return &model.SetResponse{SetStatus: http.StatusNotFound, SetRawBody: "not found"}, nil
+		}
+
+		response, err := inst.variants[i].Build(req)
+		if err != nil {
+			return nil, err
+		}
+		response.SetVariant = &i
+		return response, nil
+	}
+
+	var data *render.Data
+	if inst.templResp.SetTemplate {
+		var err error
+		if data, err = render.NewData(req); err != nil {
+			return nil, err
+		}
+	}
+
 	response := &model.SetResponse{}
 	if inst.templResp.SetBody != nil {
-		resp, err := inst.build(inst.templResp.SetBody, req)
+		resp, err := inst.build(inst.templResp.SetBody, req, data)
 		if err != nil {
 			return nil, err
 		}
 		response.SetBody = resp
+	} else if inst.templResp.SetRawBody != "" {
+		response.SetRawBody = inst.templResp.SetRawBody
+		if data != nil {
+			rendered, err := inst.render(inst.templResp.SetRawBody, data)
+			if err != nil {
+				return nil, err
+			}
+			response.SetRawBody = rendered
+		}
+	} else if inst.templResp.SetStream != nil {
+		stream, err := inst.buildStream(req, data)
+		if err != nil {
+			return nil, err
+		}
+		response.SetStream = stream
 	} else if inst.templResp.SetFile != "" {
 		f, err := os.Open(inst.templResp.SetFile)
 		if err != nil {
@@ -58,57 +299,86 @@ func (inst *ResponseBuilder) Build(req *http.Request) (*model.SetResponse, error
 	}
 
 	response.SetHeaders = inst.templResp.SetHeaders
+	response.SetHeaderValues = inst.templResp.SetHeaderValues
 	response.SetStatus = inst.templResp.SetStatus
 
+	if data != nil {
+		if err := inst.renderHeadersAndStatus(response, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if inst.delay != nil {
+		response.SetDelay = inst.delay.Sample()
+	}
+
+	response.SetThrottle = inst.throttle
+
+	if fault := inst.templResp.SetFault; fault != nil && (fault.Probability == nil || random.Float64() < *fault.Probability) {
+		response.SetFault = fault
+	}
+
 	return response, nil
 }
 
-// build recursively constructs the response body map, resolving any dynamic
-// placeholders using values from the request.
+// build recursively constructs the response body, resolving any dynamic
+// placeholders using values from the request. The body may be any JSON value;
+// placeholders are resolved in string values of objects and arrays at any depth.
 //
 // Parameters:
-//   - templResp: a nested map representing the body structure with possible placeholders.
+//   - templResp: a JSON value representing the body structure with possible placeholders.
 //   - req: the HTTP request from which values can be extracted.
+//   - data: the request data for rendering string values as templates, nil if the response is not templated.
 //
-// Returns a fully resolved map or an error if a placeholder fails to resolve.
-func (inst *ResponseBuilder) build(templResp map[string]any, req *http.Request) (map[string]any, error) {
-	if len(templResp) == 0 {
-		return nil, nil
-	}
+// Returns a fully resolved value or an error if a placeholder fails to resolve.
+func (inst *ResponseBuilder) build(templResp any, req *http.Request, data *render.Data) (any, error) {
+	switch fieldValT := templResp.(type) {
+	case string:
+		if data != nil {
+			rendered, err := inst.render(fieldValT, data)
+			if err != nil {
+				return nil, err
+			}
+			fieldValT = rendered
+		}
 
-	response := make(map[string]any, len(templResp))
-	for filedName, fieldValue := range templResp {
-		switch fieldValT := fieldValue.(type) {
-		case string:
-			if constants.RegexpResponseValuePlaceholder.MatchString(fieldValT) {
-				placeholders := constants.RegexpResponseValuePlaceholder.FindStringSubmatch(fieldValT)
-				if placeholderValue, err := inst.valueByPlacehoders(placeholders, req); err != nil {
-					return nil, err
-				} else {
-					response[filedName] = placeholderValue
-				}
-				continue
+		if constants.RegexpResponseValuePlaceholder.MatchString(fieldValT) {
+			placeholders := constants.RegexpResponseValuePlaceholder.FindStringSubmatch(fieldValT)
+			return inst.valueByPlacehoders(placeholders, req)
+		}
+		return fieldValT, nil
+	case map[string]any:
+		response := make(map[string]any, len(fieldValT))
+		for filedName, fieldValue := range fieldValT {
+			buildetValue, err := inst.build(fieldValue, req, data)
+			if err != nil {
+				return nil, err
 			}
-			response[filedName] = fieldValT
-		case map[string]any:
-			buildetMap, err := inst.build(fieldValT, req)
+			response[filedName] = buildetValue
+		}
+		return response, nil
+	case []any:
+		response := make([]any, len(fieldValT))
+		for i, item := range fieldValT {
+			buildetValue, err := inst.build(item, req, data)
 			if err != nil {
 				return nil, err
 			}
-			response[filedName] = buildetMap
-		default:
-			response[filedName] = fieldValT
+			response[i] = buildetValue
 		}
+		return response, nil
+	default:
+		return fieldValT, nil
 	}
-
-	return response, nil
 }
 
 // valueByPlacehoders resolves a value from the HTTP request based on the parsed
 // placeholder format.
 //
 // Expected format for placeholders: {{<type>:<key>}}
-// Supported types: headers, query, path, form, body
+// Supported types: headers, query, path, form, body, file, fileSize
+// The file placeholders resolve to the name and the size of the first file uploaded
+// with the field name, or nil if there is none.
 //
 // Parameters:
 //   - placeholders: array of matched strings from the placeholder regex.
@@ -129,12 +399,35 @@ func (inst *ResponseBuilder) valueByPlacehoders(placeholders []string, req *http
 		vars := mux.Vars(req)
 		return vars[placeholders[3]], nil
 	case string(constants.Form):
+		if formdata.IsFormData(req.Header.Get("Content-Type")) {
+			form, err := formdata.ParseRequest(req)
+			if err != nil {
+				return nil, err
+			}
+			return form.Values.Get(placeholders[3]), nil
+		}
 		return req.FormValue(placeholders[3]), nil
+	case string(constants.File), string(constants.FileSize):
+		form, err := formdata.ParseRequest(req)
+		if err != nil {
+			return nil, err
+		}
+
+		file, ok := form.File(placeholders[3])
+		if !ok {
+			return nil, nil
+		}
+		if placeholders[2] == string(constants.FileSize) {
+			return file.Size, nil
+		}
+		return file.Name, nil
 	case string(constants.Body):
 		body, err := io.ReadAll(req.Body)
 		if err != nil {
 			return nil, err
 		}
+		// Restore the body, it is read again for every body placeholder.
+		req.Body = io.NopCloser(bytes.NewReader(body))
 
 		mBody := make(map[string]any)
 		if err := json.Unmarshal(body, &mBody); err != nil {
@@ -145,3 +438,127 @@ func (inst *ResponseBuilder) valueByPlacehoders(placeholders []string, req *http
 	}
 	return nil, fmt.Errorf("unexpected placeholder: %s", placeholders[2])
 }
+
+// renderHeadersAndStatus renders the response headers and SetStatusTemplate.
+//
+// Parameters:
+//   - response: the response being built, its headers are replaced with rendered copies.
+//   - data: the request data.
+//
+// Returns an error if a template fails or the rendered status is not a number.
+func (inst *ResponseBuilder) renderHeadersAndStatus(response *model.SetResponse, data *render.Data) error {
+	if response.SetHeaders != nil {
+		headers := make(map[string]string, len(response.SetHeaders))
+		for name, value := range response.SetHeaders {
+			rendered, err := inst.render(value, data)
+			if err != nil {
+				return err
+			}
+			headers[name] = rendered
+		}
+		response.SetHeaders = headers
+	}
+
+	if inst.templResp.SetStatusTemplate != "" {
+		rendered, err := inst.render(inst.templResp.SetStatusTemplate, data)
+		if err != nil {
+			return err
+		}
+
+		status, err := strconv.Atoi(rendered)
+		if err != nil {
+			return fmt.Errorf("rendered status '%s' is not a number", rendered)
+		}
+		response.SetStatus = status
+	}
+
+	return nil
+}
+
+// render executes the template parsed for the text, texts without template actions are returned as is.
+func (inst *ResponseBuilder) render(text string, data *render.Data) (string, error) {
+	tmpl, ok := inst.templates[text]
+	if !ok {
+		return text, nil
+	}
+	return render.Execute(tmpl, data)
+}
+
+// compileTemplates parses every text/template of a response with SetTemplate enabled:
+// SetStatusTemplate, header values, string values of the body and the raw body.
+//
+// Parameters:
+//   - templResp: the response template.
+//
+// Returns the parsed templates by their text, or the first syntax error.
+func compileTemplates(templResp model.SetResponseTemplate) (map[string]*template.Template, error) {
+	if !templResp.SetTemplate {
+		return nil, nil
+	}
+
+	templates := make(map[string]*template.Template)
+	add := func(text string) error {
+		if _, ok := templates[text]; ok || !render.IsTemplate(text) {
+			return nil
+		}
+
+		tmpl, err := render.Parse(text)
+		if err != nil {
+			return err
+		}
+		templates[text] = tmpl
+		return nil
+	}
+
+	var addBody func(value any) error
+	addBody = func(value any) error {
+		switch v := value.(type) {
+		case string:
+			return add(v)
+		case map[string]any:
+			for _, item := range v {
+				if err := addBody(item); err != nil {
+					return err
+				}
+			}
+		case []any:
+			for _, item := range v {
+				if err := addBody(item); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := add(templResp.SetStatusTemplate); err != nil {
+		return nil, err
+	}
+
+	for _, value := range templResp.SetHeaders {
+		if err := add(value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := addBody(templResp.SetBody); err != nil {
+		return nil, err
+	}
+
+	if err := add(templResp.SetRawBody); err != nil {
+		return nil, err
+	}
+
+	if templResp.SetStream != nil {
+		for _, event := range templResp.SetStream.Events {
+			if err := addBody(event.Data); err != nil {
+				return nil, err
+			}
+			if err := add(event.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return templates, nil
+}
@@ -4,11 +4,16 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"mime/multipart"
 	"mockium/internal/model"
+	"mockium/internal/service/random"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
@@ -57,8 +62,8 @@ func TestBuild_WithBodyTemplate(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, http.StatusOK, resp.SetStatus)
 	assert.Equal(t, "application/json", resp.SetHeaders["Content-Type"])
-	assert.Equal(t, "Hello, World!", resp.SetBody["message"])
-	assert.Equal(t, "value", resp.SetBody["nested"].(map[string]any)["key"])
+	assert.Equal(t, "Hello, World!", resp.SetBody.(map[string]any)["message"])
+	assert.Equal(t, "value", resp.SetBody.(map[string]any)["nested"].(map[string]any)["key"])
 }
 
 func TestBuild_WithFileTemplate(t *testing.T) {
@@ -180,7 +185,7 @@ func TestBuild_WithPlaceholders(t *testing.T) {
 
 			resp, err := builder.Build(req)
 			require.NoError(t, err)
-			assert.Equal(t, tt.expectedValue, resp.SetBody[tt.expectedKey])
+			assert.Equal(t, tt.expectedValue, resp.SetBody.(map[string]any)[tt.expectedKey])
 		})
 	}
 }
@@ -202,11 +207,47 @@ func TestBuild_WithNestedPlaceholders(t *testing.T) {
 	resp, err := builder.Build(req)
 	require.NoError(t, err)
 
-	user := resp.SetBody["user"].(map[string]any)
+	user := resp.SetBody.(map[string]any)["user"].(map[string]any)
 	assert.Equal(t, "John Doe", user["name"])
 	assert.Equal(t, "NY", user["location"])
 }
 
+func TestBuild_WithFilePlaceholders(t *testing.T) {
+	template := model.SetResponseTemplate{
+		SetBody: map[string]any{
+			"title":    "${req.form:title}",
+			"name":     "${req.file:photo}",
+			"size":     "${req.fileSize:photo}",
+			"missing":  "${req.file:other}",
+			"template": "{{.Files.photo.Name}} {{.Files.photo.Size}} {{.Form.Get \"title\"}}",
+		},
+		SetTemplate: true,
+	}
+	builder := NewResponseBuilder(template)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	require.NoError(t, writer.WriteField("title", "holiday"))
+	part, err := writer.CreateFormFile("photo", "beach.png")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("abc"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest("POST", "/upload", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := builder.Build(req)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"title":    "holiday",
+		"name":     "beach.png",
+		"size":     int64(3),
+		"missing":  nil,
+		"template": "beach.png 3 holiday",
+	}, resp.SetBody)
+}
+
 func TestBuild_WithInvalidPlaceholder(t *testing.T) {
 	template := model.SetResponseTemplate{
 		SetBody: map[string]any{
@@ -257,3 +298,344 @@ func TestValueByPlaceholders_InvalidPlaceholder(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "unexpected placeholder: invalid")
 }
+
+func TestBuild_WithDelay(t *testing.T) {
+	template := model.SetResponseTemplate{}
+	require.NoError(t, json.Unmarshal([]byte(`{"SetStatus": 200, "SetDelay": "150ms"}`), &template))
+
+	resp, err := NewResponseBuilder(template).Build(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 150*time.Millisecond, resp.SetDelay)
+
+	template = model.SetResponseTemplate{}
+	require.NoError(t, json.Unmarshal([]byte(`{"SetDelay": {"Distribution": "uniform", "Min": "10ms", "Max": "20ms"}}`), &template))
+
+	resp, err = NewResponseBuilder(template).Build(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, resp.SetDelay, 10*time.Millisecond)
+	assert.LessOrEqual(t, resp.SetDelay, 20*time.Millisecond)
+}
+
+func TestBuild_WithInvalidDelay(t *testing.T) {
+	template := model.SetResponseTemplate{SetDelay: &model.DelayTemplate{Fixed: "soon"}}
+
+	_, err := NewResponseBuilder(template).Build(httptest.NewRequest("GET", "/", nil))
+	assert.Error(t, err)
+}
+
+func TestBuild_WithFaultProbability(t *testing.T) {
+	never, always := 0.0, 1.0
+
+	resp, err := NewResponseBuilder(model.SetResponseTemplate{
+		SetFault: &model.FaultTemplate{Type: model.FaultCloseConnection, Probability: &never},
+	}).Build(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	assert.Nil(t, resp.SetFault)
+
+	resp, err = NewResponseBuilder(model.SetResponseTemplate{
+		SetFault: &model.FaultTemplate{Type: model.FaultCloseConnection, Probability: &always},
+	}).Build(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	require.NotNil(t, resp.SetFault)
+	assert.Equal(t, model.FaultCloseConnection, resp.SetFault.Type)
+}
+
+func TestBuild_WithTemplate(t *testing.T) {
+	template := model.SetResponseTemplate{
+		SetTemplate:       true,
+		SetStatusTemplate: `{{if eq (.Query.Get "fail") "true"}}500{{else}}201{{end}}`,
+		SetHeaders: map[string]string{
+			"Location": "/users/{{.Path.id}}",
+		},
+		SetBody: map[string]any{
+			"greeting": `Hello, {{.Body.name}}!`,
+			"nested": map[string]any{
+				"page": `{{add (.Query.Get "page") 1}}`,
+			},
+			"name":  "${req.body:name}",
+			"count": 1,
+		},
+	}
+
+	newRequest := func(query string) *http.Request {
+		req := httptest.NewRequest("POST", "/users/42"+query, strings.NewReader(`{"name":"test"}`))
+		req.Header.Set("Content-Type", "application/json")
+		return mux.SetURLVars(req, map[string]string{"id": "42"})
+	}
+
+	resp, err := NewResponseBuilder(template).Build(newRequest("?page=1"))
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, resp.SetStatus)
+	assert.Equal(t, "/users/42", resp.SetHeaders["Location"])
+	assert.Equal(t, "Hello, test!", resp.SetBody.(map[string]any)["greeting"])
+	assert.Equal(t, "2", resp.SetBody.(map[string]any)["nested"].(map[string]any)["page"])
+	assert.Equal(t, "test", resp.SetBody.(map[string]any)["name"])
+	assert.Equal(t, 1, resp.SetBody.(map[string]any)["count"])
+	assert.Equal(t, "/users/{{.Path.id}}", template.SetHeaders["Location"])
+
+	resp, err = NewResponseBuilder(template).Build(newRequest("?page=1&fail=true"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.SetStatus)
+}
+
+func TestBuild_WithInvalidTemplate(t *testing.T) {
+	template := model.SetResponseTemplate{
+		SetTemplate: true,
+		SetBody:     map[string]any{"name": "{{.Body.name"},
+	}
+
+	_, err := NewResponseBuilder(template).Build(httptest.NewRequest("GET", "/", nil))
+	assert.Error(t, err)
+
+	template = model.SetResponseTemplate{
+		SetTemplate:       true,
+		SetStatusTemplate: "{{.Method}}",
+	}
+
+	_, err = NewResponseBuilder(template).Build(httptest.NewRequest("GET", "/", nil))
+	assert.Error(t, err)
+}
+
+func TestBuild_WithArrayBody(t *testing.T) {
+	template := model.SetResponseTemplate{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"SetBody": [
+			{"id": 1, "location": "${req.query:location}", "tags": ["${req.headers:X-Tag}", "static"]},
+			"${req.query:location}",
+			2
+		]
+	}`), &template))
+
+	req := httptest.NewRequest("GET", "/?location=NY", nil)
+	req.Header.Set("X-Tag", "new")
+
+	resp, err := NewResponseBuilder(template).Build(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{
+		map[string]any{"id": float64(1), "location": "NY", "tags": []any{"new", "static"}},
+		"NY",
+		float64(2),
+	}, resp.SetBody)
+}
+
+func TestBuild_WithScalarBody(t *testing.T) {
+	resp, err := NewResponseBuilder(model.SetResponseTemplate{SetBody: "${req.query:name}"}).
+		Build(httptest.NewRequest("GET", "/?name=test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "test", resp.SetBody)
+}
+
+func TestBuild_WithRawBody(t *testing.T) {
+	template := model.SetResponseTemplate{
+		SetRawBody: "<h1>${req.query:name}</h1>",
+	}
+
+	resp, err := NewResponseBuilder(template).Build(httptest.NewRequest("GET", "/?name=test", nil))
+	require.NoError(t, err)
+	assert.Nil(t, resp.SetBody)
+	assert.Equal(t, "<h1>${req.query:name}</h1>", resp.SetRawBody)
+
+	template = model.SetResponseTemplate{
+		SetTemplate: true,
+		SetRawBody:  `<h1>{{.Query.Get "name"}}</h1>`,
+	}
+
+	resp, err = NewResponseBuilder(template).Build(httptest.NewRequest("GET", "/?name=test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "<h1>test</h1>", resp.SetRawBody)
+}
+
+func TestBuild_WithSOAPFault(t *testing.T) {
+	req := httptest.NewRequest("POST", "/ws", nil)
+
+	builder := NewResponseBuilder(model.SetResponseTemplate{
+		SetSOAPFault: &model.SOAPFaultTemplate{
+			Code:   "Client",
+			Reason: "Invalid id < 0",
+			Detail: map[string]any{"error": map[string]any{"@xmlns": "urn:orders", "code": 42}},
+		},
+	})
+	resp, err := builder.Build(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.SetStatus)
+	assert.Equal(t, "text/xml; charset=utf-8", resp.SetHeaders["Content-Type"])
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault>`+
+		`<faultcode>soap:Client</faultcode><faultstring>Invalid id &lt; 0</faultstring>`+
+		`<detail><error xmlns="urn:orders"><code>42</code></error></detail>`+
+		`</soap:Fault></soap:Body></soap:Envelope>`, resp.SetRawBody)
+
+	builder = NewResponseBuilder(model.SetResponseTemplate{
+		SetStatus:    http.StatusBadRequest,
+		SetHeaders:   map[string]string{"X-Trace": "1"},
+		SetSOAPFault: &model.SOAPFaultTemplate{Version: model.SOAP12, Reason: "Unavailable"},
+	})
+	resp, err = builder.Build(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, resp.SetStatus)
+	assert.Equal(t, map[string]string{"X-Trace": "1", "Content-Type": "application/soap+xml; charset=utf-8"}, resp.SetHeaders)
+	assert.Contains(t, resp.SetRawBody, `<env:Code><env:Value>env:Receiver</env:Value></env:Code>`)
+	assert.Contains(t, resp.SetRawBody, `<env:Reason><env:Text xml:lang="en">Unavailable</env:Text></env:Reason>`)
+	assert.NotContains(t, resp.SetRawBody, "Detail")
+}
+
+func TestBuild_WithStream(t *testing.T) {
+	req := httptest.NewRequest("GET", "/events?user=alice", nil)
+
+	builder := NewResponseBuilder(model.SetResponseTemplate{
+		SetStream: &model.StreamTemplate{
+			KeepAlive: "15s",
+			Events: []model.StreamEventTemplate{
+				{Event: "hello", Data: "${req.query:user}", ID: "${req.query:user}"},
+				{Data: map[string]any{"user": "${req.query:user}", "n": 1}, Delay: "100ms"},
+			},
+		},
+	})
+	resp, err := builder.Build(req)
+	require.NoError(t, err)
+	require.NotNil(t, resp.SetStream)
+
+	assert.Equal(t, 1, resp.SetStream.Repeat)
+	assert.Equal(t, 15*time.Second, resp.SetStream.KeepAlive)
+	assert.Equal(t, []model.StreamEvent{
+		{Event: "hello", Data: "alice", ID: "alice"},
+		{Data: `{"n":1,"user":"alice"}`, Delay: 100 * time.Millisecond},
+	}, resp.SetStream.Events)
+
+	builder = NewResponseBuilder(model.SetResponseTemplate{
+		SetTemplate: true,
+		SetStream: &model.StreamTemplate{
+			Events: []model.StreamEventTemplate{{Data: `hi {{.Query.Get "user"}}`}},
+		},
+	})
+	resp, err = builder.Build(req)
+	require.NoError(t, err)
+	assert.Equal(t, "hi alice", resp.SetStream.Events[0].Data)
+}
+
+func TestBuild_WithThrottle(t *testing.T) {
+	builder := NewResponseBuilder(model.SetResponseTemplate{
+		SetRawBody:  "slow",
+		SetThrottle: &model.ThrottleTemplate{BytesPerSecond: 1024, Chunks: 4, ChunkDelay: "250ms"},
+	})
+	resp, err := builder.Build(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, &model.Throttle{BytesPerSecond: 1024, Chunks: 4, ChunkDelay: 250 * time.Millisecond}, resp.SetThrottle)
+
+	_, err = NewResponseBuilder(model.SetResponseTemplate{
+		SetRawBody:  "slow",
+		SetThrottle: &model.ThrottleTemplate{ChunkDelay: "1s"},
+	}).Build(httptest.NewRequest("GET", "/", nil))
+	assert.Error(t, err)
+}
+
+func TestBuild_WithSequence(t *testing.T) {
+	responses := []model.SetResponseTemplate{
+		{SetStatus: http.StatusServiceUnavailable, Repeat: 2},
+		{SetStatus: http.StatusOK, SetBody: map[string]any{"page": 1}},
+		{SetStatus: http.StatusOK, SetBody: map[string]any{"page": 2}},
+	}
+
+	tests := []struct {
+		mode string
+		want []int
+	}{
+		{mode: "", want: []int{503, 503, 200, 200, 200}},
+		{mode: model.ResponsesCycle, want: []int{503, 503, 200, 200, 503, 503}},
+		{mode: model.ResponsesNotFound, want: []int{503, 503, 200, 200, 404, 404}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			builder := NewSequenceBuilder(responses, tt.mode)
+
+			statuses := make([]int, 0, len(tt.want))
+			for range tt.want {
+				resp, err := builder.Build(httptest.NewRequest("GET", "/", nil))
+				require.NoError(t, err)
+				statuses = append(statuses, resp.SetStatus)
+			}
+			assert.Equal(t, tt.want, statuses)
+
+			builder.Reset()
+			resp, err := builder.Build(httptest.NewRequest("GET", "/", nil))
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusServiceUnavailable, resp.SetStatus)
+		})
+	}
+}
+
+func TestBuild_WithSequenceConcurrent(t *testing.T) {
+	builder := NewSequenceBuilder([]model.SetResponseTemplate{
+		{SetStatus: http.StatusServiceUnavailable, Repeat: 50},
+		{SetStatus: http.StatusOK},
+	}, model.ResponsesNotFound)
+
+	var wg sync.WaitGroup
+	statuses := make(chan int, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := builder.Build(httptest.NewRequest("GET", "/", nil))
+			if assert.NoError(t, err) {
+				statuses <- resp.SetStatus
+			}
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+
+	counts := make(map[int]int)
+	for status := range statuses {
+		counts[status]++
+	}
+	assert.Equal(t, map[int]int{http.StatusServiceUnavailable: 50, http.StatusOK: 1, http.StatusNotFound: 49}, counts)
+}
+
+func TestBuild_WithEmptySequence(t *testing.T) {
+	_, err := NewSequenceBuilder(nil, "").Build(httptest.NewRequest("GET", "/", nil))
+	assert.Error(t, err)
+}
+
+func TestBuild_WithRandomResponses(t *testing.T) {
+	builder := NewRandomBuilder([]model.SetResponseTemplate{
+		{SetStatus: http.StatusOK, Weight: 90},
+		{SetStatus: http.StatusTooManyRequests, Weight: 7},
+		{SetStatus: http.StatusInternalServerError, Weight: 3},
+	})
+
+	sample := func() ([]int, map[int]int) {
+		variants := make([]int, 0, 1000)
+		counts := make(map[int]int)
+		for i := 0; i < 1000; i++ {
+			resp, err := builder.Build(httptest.NewRequest("GET", "/", nil))
+			require.NoError(t, err)
+			require.NotNil(t, resp.SetVariant)
+			variants = append(variants, *resp.SetVariant)
+			counts[resp.SetStatus]++
+		}
+		return variants, counts
+	}
+
+	random.Seed(7)
+	first, counts := sample()
+	assert.InDelta(t, 900, counts[http.StatusOK], 50)
+	assert.InDelta(t, 70, counts[http.StatusTooManyRequests], 30)
+	assert.InDelta(t, 30, counts[http.StatusInternalServerError], 20)
+
+	random.Seed(7)
+	second, _ := sample()
+	assert.Equal(t, first, second)
+}
+
+func TestBuild_WithInvalidRandomResponses(t *testing.T) {
+	for _, responses := range [][]model.SetResponseTemplate{nil, {{Weight: -1}}} {
+		_, err := NewRandomBuilder(responses).Build(httptest.NewRequest("GET", "/", nil))
+		assert.Error(t, err)
+	}
+}
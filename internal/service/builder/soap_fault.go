@@ -0,0 +1,99 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"mockium/internal/model"
+	"mockium/internal/service/constants"
+	"mockium/internal/service/xmltree"
+	"net/http"
+	"strings"
+)
+
+// SOAP envelope namespaces.
+const (
+	soap11Namespace = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12Namespace = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// withSOAPFault turns the SetSOAPFault of a response into its envelope in SetRawBody.
+// The SOAP Content-Type is set unless SetHeaders has one, and the status is 500 unless SetStatus is set.
+//
+// Parameters:
+//   - templResp: the response template.
+//
+// Returns the response template without SetSOAPFault, or an error if the fault is invalid.
+func withSOAPFault(templResp model.SetResponseTemplate) (model.SetResponseTemplate, error) {
+	fault := templResp.SetSOAPFault
+	if fault == nil {
+		return templResp, nil
+	}
+
+	detail, err := xmltree.MarshalContent(fault.Detail)
+	if err != nil {
+		return templResp, fmt.Errorf("SOAP fault detail: %w", err)
+	}
+
+	buf := &bytes.Buffer{}
+	escape := func(text string) string {
+		escaped := &strings.Builder{}
+		xml.EscapeText(escaped, []byte(text))
+		return escaped.String()
+	}
+
+	var contentType string
+	switch fault.Version {
+	case "", model.SOAP11:
+		contentType = constants.ContentTypeTextXML + "; charset=utf-8"
+		fmt.Fprintf(buf, `<soap:Envelope xmlns:soap="%s"><soap:Body><soap:Fault>`, soap11Namespace)
+		fmt.Fprintf(buf, "<faultcode>%s</faultcode>", escape(faultCode(fault.Code, "soap", "Server")))
+		fmt.Fprintf(buf, "<faultstring>%s</faultstring>", escape(fault.Reason))
+		if len(detail) > 0 {
+			fmt.Fprintf(buf, "<detail>%s</detail>", detail)
+		}
+		buf.WriteString("</soap:Fault></soap:Body></soap:Envelope>")
+	case model.SOAP12:
+		contentType = constants.ContentTypeApplicationSOAPXML + "; charset=utf-8"
+		fmt.Fprintf(buf, `<env:Envelope xmlns:env="%s"><env:Body><env:Fault>`, soap12Namespace)
+		fmt.Fprintf(buf, "<env:Code><env:Value>%s</env:Value></env:Code>", escape(faultCode(fault.Code, "env", "Receiver")))
+		fmt.Fprintf(buf, `<env:Reason><env:Text xml:lang="en">%s</env:Text></env:Reason>`, escape(fault.Reason))
+		if len(detail) > 0 {
+			fmt.Fprintf(buf, "<env:Detail>%s</env:Detail>", detail)
+		}
+		buf.WriteString("</env:Fault></env:Body></env:Envelope>")
+	default:
+		return templResp, fmt.Errorf("unexpected SOAP version '%s', expected '%s' or '%s'", fault.Version, model.SOAP11, model.SOAP12)
+	}
+
+	templResp.SetSOAPFault = nil
+	templResp.SetRawBody = xml.Header + buf.String()
+
+	if templResp.SetStatus == 0 {
+		templResp.SetStatus = http.StatusInternalServerError
+	}
+
+	headers := make(map[string]string, len(templResp.SetHeaders)+1)
+	hasContentType := false
+	for name, value := range templResp.SetHeaders {
+		headers[name] = value
+		hasContentType = hasContentType || http.CanonicalHeaderKey(name) == "Content-Type"
+	}
+	if !hasContentType {
+		headers["Content-Type"] = contentType
+	}
+	templResp.SetHeaders = headers
+
+	return templResp, nil
+}
+
+// faultCode qualifies a fault code with the envelope prefix, e.g. "Client" as "soap:Client".
+func faultCode(code, prefix, defaultCode string) string {
+	if code == "" {
+		code = defaultCode
+	}
+	if strings.Contains(code, ":") {
+		return code
+	}
+	return prefix + ":" + code
+}
@@ -0,0 +1,121 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"mockium/internal/model"
+	"mockium/internal/service/render"
+	"net/http"
+	"time"
+)
+
+// checkStream verifies the events of a stream and its durations.
+//
+// Parameters:
+//   - stream: the stream to validate, nil if the response is not a stream.
+//
+// Returns an error if the stream is invalid.
+func checkStream(stream *model.StreamTemplate) error {
+	if stream == nil {
+		return nil
+	}
+
+	if len(stream.Events) == 0 {
+		return fmt.Errorf("parameter 'SetStream' requires 'Events'")
+	}
+
+	if stream.Repeat < 0 {
+		return fmt.Errorf("stream 'Repeat' must not be negative")
+	}
+
+	if stream.Loop && stream.Repeat != 0 {
+		return fmt.Errorf("cannot use stream 'Loop' with 'Repeat'")
+	}
+
+	if _, err := parseDuration(stream.KeepAlive); err != nil {
+		return fmt.Errorf("stream 'KeepAlive': %w", err)
+	}
+
+	var delays time.Duration
+	for i, event := range stream.Events {
+		delay, err := parseDuration(event.Delay)
+		if err != nil {
+			return fmt.Errorf("stream event %d 'Delay': %w", i, err)
+		}
+		delays += delay
+	}
+
+	// A loop without delays would send events as fast as the connection allows.
+	if stream.Loop && delays == 0 {
+		return fmt.Errorf("stream 'Loop' requires an event 'Delay'")
+	}
+
+	return nil
+}
+
+// buildStream renders the events of the stream for the request. Data strings resolve
+// placeholders like SetBody, other data values are sent as JSON.
+//
+// Parameters:
+//   - req: the HTTP request from which values can be extracted.
+//   - data: the request data for rendering templates, nil if the response is not templated.
+//
+// Returns the built stream or an error if a placeholder or template fails.
+func (inst *ResponseBuilder) buildStream(req *http.Request, data *render.Data) (*model.Stream, error) {
+	templ := inst.templResp.SetStream
+
+	keepAlive, _ := parseDuration(templ.KeepAlive)
+	stream := &model.Stream{
+		Events:    make([]model.StreamEvent, 0, len(templ.Events)),
+		Repeat:    max(templ.Repeat, 1),
+		Loop:      templ.Loop,
+		KeepAlive: keepAlive,
+	}
+
+	for _, event := range templ.Events {
+		value, err := inst.build(event.Data, req, data)
+		if err != nil {
+			return nil, err
+		}
+
+		text, ok := value.(string)
+		if !ok {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			text = string(encoded)
+		}
+
+		id, err := inst.build(event.ID, req, data)
+		if err != nil {
+			return nil, err
+		}
+
+		delay, _ := parseDuration(event.Delay)
+		stream.Events = append(stream.Events, model.StreamEvent{
+			Event: event.Event,
+			Data:  text,
+			ID:    fmt.Sprint(id),
+			Delay: delay,
+		})
+	}
+
+	return stream, nil
+}
+
+// parseDuration parses a Go duration, an empty text is no duration.
+func parseDuration(text string) (time.Duration, error) {
+	if text == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration '%s' must not be negative", text)
+	}
+	return d, nil
+}
@@ -1,17 +1,28 @@
 package builder
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"mockium/internal/model"
+	"mockium/internal/service/comparer"
+	"mockium/internal/service/delay"
+	"mockium/internal/service/matcher"
+	"mockium/internal/service/schema"
+	"mockium/internal/transport/proxy"
+	"net/http"
 	"os"
-	"regexp"
+	"path/filepath"
+	"reflect"
+	"strings"
 
 	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
 )
 
 // TemplateBuilder is responsible for loading and validating template definitions
-// from JSON files in a specified directory.
+// from JSON and YAML files in a specified directory.
 type TemplateBuilder struct {
 	log *zap.Logger // Logger for validation and loading diagnostics (currently unused).
 }
@@ -28,62 +39,139 @@ func NewTemplateBuilder(log *zap.Logger) *TemplateBuilder {
 	}
 }
 
-// Build reads all JSON template files from the given directory path, unmarshals them,
-// and validates the resulting templates.
+// Build reads all JSON (.json) and YAML (.yaml, .yml) template files from the given
+// directory path, unmarshals them, and validates the resulting templates.
+// Templates without an explicit ID are identified by their file name.
 //
 // Parameters:
-//   - path: directory path where template JSON files are located.
+//   - path: directory path where template files are located.
 //
 // Returns a slice of model.Template and an error if reading or validation fails.
+// Decoding errors report the file name and, where known, the line number.
 func (inst *TemplateBuilder) Build(path string) ([]model.Template, error) {
 	dir, err := os.ReadDir(path)
 	if err != nil {
 		return nil, err
 	}
 
-	re, err := regexp.Compile(`.json`)
-	if err != nil {
-		return nil, err
-	}
-
 	templates := make([]model.Template, 0)
 	for _, file := range dir {
 		if file.IsDir() {
 			continue
 		}
-		template := model.Template{}
-		if re.MatchString(file.Name()) {
-			f, err := os.ReadFile(fmt.Sprintf("%s/%s", path, file.Name()))
-			if err != nil {
-				return nil, fmt.Errorf("%s, file: %s", err.Error(), file.Name())
-			}
 
-			if err := json.Unmarshal(f, &template); err != nil {
-				return nil, fmt.Errorf("%s, file: %s", err.Error(), file.Name())
-			}
+		unmarshal, ok := inst.unmarshaler(file.Name())
+		if !ok {
+			continue
+		}
 
-			templates = append(templates, template)
+		f, err := os.ReadFile(filepath.Join(path, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("%s, file: %s", err.Error(), file.Name())
 		}
+
+		template := model.Template{}
+		if err := unmarshal(f, &template); err != nil {
+			return nil, fmt.Errorf("%s, file: %s", err.Error(), file.Name())
+		}
+
+		if template.ID == "" {
+			template.ID = file.Name()
+		}
+
+		templates = append(templates, template)
 	}
 
-	if err := inst.validate(templates); err != nil {
+	if err := inst.Validate(templates); err != nil {
 		return nil, err
 	}
 
 	return templates, nil
 }
 
-// validate performs structural validation of templates including:
+// unmarshaler selects the decoding function for a template file by its extension.
+//
+// Returns false if the file is not a supported template file.
+func (inst *TemplateBuilder) unmarshaler(fileName string) (func(data []byte, template *model.Template) error, bool) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".json":
+		return inst.unmarshalJSON, true
+	case ".yaml", ".yml":
+		return inst.unmarshalYAML, true
+	default:
+		return nil, false
+	}
+}
+
+// unmarshalJSON decodes a JSON template, adding the line number to syntax and type errors.
+func (inst *TemplateBuilder) unmarshalJSON(data []byte, template *model.Template) error {
+	err := json.Unmarshal(data, template)
+	if err == nil {
+		return nil
+	}
+
+	var offset int64 = -1
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	}
+
+	if offset < 0 || offset > int64(len(data)) {
+		return err
+	}
+
+	return fmt.Errorf("line %d: %w", bytes.Count(data[:offset], []byte("\n"))+1, err)
+}
+
+// unmarshalYAML decodes a YAML template. Errors produced by the YAML decoder
+// already contain the line number.
+func (inst *TemplateBuilder) unmarshalYAML(data []byte, template *model.Template) error {
+	return yaml.Unmarshal(data, template)
+}
+
+// Validate performs structural validation of templates including:
+//   - ensuring template IDs are unique
 //   - setting default HTTP method if not specified
-//   - ensuring only one of SetBody or SetFile is used in a response
+//   - ensuring only one of SetBody, SetRawBody or SetFile is used in a response
+//   - compiling match placeholders
+//   - loading body schemas and compiling body path conditions
+//   - ensuring scenario states are only used together with a scenario
+//   - checking response delays, faults and body throttles
+//   - building SOAP Fault envelopes
+//   - checking response lists (SetResponses, SetRandomResponses), their end mode and weights
+//   - parsing response text/templates
+//   - checking proxy upstream URLs
+//   - checking the WebSocket script of a template with Upgrade
 //   - checking for valid HTTP methods
 //
 // Parameters:
 //   - templates: the slice of templates to validate.
 //
 // Returns an error if validation fails.
-func (inst *TemplateBuilder) validate(templates []model.Template) error {
+func (inst *TemplateBuilder) Validate(templates []model.Template) error {
+	ids := make(map[string]struct{}, len(templates))
 	for _, template := range templates {
+		if template.ID != "" {
+			if _, exists := ids[template.ID]; exists {
+				return fmt.Errorf("duplicate template ID '%s'", template.ID)
+			}
+			ids[template.ID] = struct{}{}
+		}
+
+		if template.Proxy != "" {
+			if _, err := proxy.NewReverseProxy(inst.log, template.Proxy); err != nil {
+				return fmt.Errorf("parameter 'Proxy': %w", err)
+			}
+		}
+
+		if err := checkWebSocket(template); err != nil {
+			return err
+		}
+
 		for _, handle := range template.Handle {
 
 			if handle.MatchRequestTemplate.MustMethod == "" {
@@ -94,8 +182,212 @@ func (inst *TemplateBuilder) validate(templates []model.Template) error {
 				}
 			}
 
-			if handle.SetResponseTemplate.SetBody != nil && handle.SetResponseTemplate.SetFile != "" {
-				return fmt.Errorf("cannot use parameter 'SetBody' with 'SetFile'")
+			if bodySchema := handle.MatchRequestTemplate.MustBodySchema; bodySchema != nil {
+				if _, _, err := schema.Load(bodySchema); err != nil {
+					return fmt.Errorf("parameter 'MustBodySchema': %w", err)
+				}
+			}
+
+			if err := inst.checkPlaceholders(handle.MatchRequestTemplate); err != nil {
+				return err
+			}
+
+			for _, bodyPath := range handle.MatchRequestTemplate.MustBodyPaths {
+				if _, err := matcher.NewBodyPath(comparer.New(), bodyPath); err != nil {
+					return fmt.Errorf("parameter 'MustBodyPaths': %w", err)
+				}
+			}
+
+			if handle.Scenario == "" && (handle.RequiredScenarioState != "" || handle.NewScenarioState != "") {
+				return fmt.Errorf("parameters 'RequiredScenarioState' and 'NewScenarioState' require 'Scenario'")
+			}
+
+			if err := inst.checkResponses(handle); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkResponses verifies the response of a handle, or its response list
+// (SetResponses or SetRandomResponses).
+//
+// Parameters:
+//   - handle: the handle to validate.
+//
+// Returns the first invalid response error.
+func (inst *TemplateBuilder) checkResponses(handle model.HandleTemplate) error {
+	if handle.SetResponsesMode != "" && len(handle.SetResponses) == 0 {
+		return fmt.Errorf("parameter 'SetResponsesMode' requires 'SetResponses'")
+	}
+
+	name, responses := "SetResponses", handle.SetResponses
+	switch {
+	case len(handle.SetResponses) > 0 && len(handle.SetRandomResponses) > 0:
+		return fmt.Errorf("cannot use parameter 'SetResponses' with 'SetRandomResponses'")
+	case len(handle.SetRandomResponses) > 0:
+		name, responses = "SetRandomResponses", handle.SetRandomResponses
+	case len(handle.SetResponses) == 0:
+		if handle.SetResponseTemplate.Repeat != 0 {
+			return fmt.Errorf("parameter 'Repeat' requires 'SetResponses'")
+		}
+		if handle.SetResponseTemplate.Weight != 0 {
+			return fmt.Errorf("parameter 'Weight' requires 'SetRandomResponses'")
+		}
+		return inst.checkResponse(handle.SetResponseTemplate)
+	}
+
+	if !reflect.ValueOf(handle.SetResponseTemplate).IsZero() {
+		return fmt.Errorf("cannot use parameter '%s' with 'SetResponse'", name)
+	}
+
+	switch handle.SetResponsesMode {
+	case "", model.ResponsesLast, model.ResponsesCycle, model.ResponsesNotFound:
+	default:
+		return fmt.Errorf("unexpected 'SetResponsesMode' '%s'", handle.SetResponsesMode)
+	}
+
+	for i, response := range responses {
+		switch {
+		case response.Repeat < 0:
+			return fmt.Errorf("parameter '%s' %d: 'Repeat' must not be negative", name, i)
+		case response.Repeat != 0 && name != "SetResponses":
+			return fmt.Errorf("parameter '%s' %d: parameter 'Repeat' requires 'SetResponses'", name, i)
+		case response.Weight < 0:
+			return fmt.Errorf("parameter '%s' %d: 'Weight' must not be negative", name, i)
+		case response.Weight != 0 && name != "SetRandomResponses":
+			return fmt.Errorf("parameter '%s' %d: parameter 'Weight' requires 'SetRandomResponses'", name, i)
+		case response.SetProxy != nil:
+			return fmt.Errorf("parameter '%s' %d: cannot use parameter 'SetProxy'", name, i)
+		}
+
+		if err := inst.checkResponse(response); err != nil {
+			return fmt.Errorf("parameter '%s' %d: %w", name, i, err)
+		}
+	}
+	return nil
+}
+
+// checkResponse verifies a response: its body parameters, delay, stream, throttle, fault, SOAP Fault,
+// text/templates and proxy upstream.
+//
+// Parameters:
+//   - response: the response to validate.
+//
+// Returns an error if the response is invalid.
+func (inst *TemplateBuilder) checkResponse(response model.SetResponseTemplate) error {
+	if err := response.CheckBody(); err != nil {
+		return err
+	}
+
+	if _, err := delay.New(response.SetDelay); err != nil {
+		return err
+	}
+
+	for name := range response.SetHeaderValues {
+		for setName := range response.SetHeaders {
+			if http.CanonicalHeaderKey(name) == http.CanonicalHeaderKey(setName) {
+				return fmt.Errorf("header '%s' is set by both 'SetHeaders' and 'SetHeaderValues'", name)
+			}
+		}
+	}
+
+	if err := checkStream(response.SetStream); err != nil {
+		return err
+	}
+
+	if fault := response.SetFault; fault != nil && fault.Type == model.FaultTruncate && response.SetStream != nil {
+		return fmt.Errorf("cannot use parameter 'SetStream' with fault '%s'", model.FaultTruncate)
+	}
+
+	if _, err := newThrottle(response.SetThrottle); err != nil {
+		return err
+	}
+
+	if response.SetThrottle != nil && (response.SetStream != nil || response.SetProxy != nil) {
+		return fmt.Errorf("cannot use parameter 'SetThrottle' with 'SetStream' or 'SetProxy'")
+	}
+
+	if fault := response.SetFault; fault != nil && fault.Type == model.FaultTruncate && response.SetThrottle != nil {
+		return fmt.Errorf("cannot use parameter 'SetThrottle' with fault '%s'", model.FaultTruncate)
+	}
+
+	if err := inst.checkFault(response.SetFault); err != nil {
+		return err
+	}
+
+	if response.SetStatusTemplate != "" && !response.SetTemplate {
+		return fmt.Errorf("parameter 'SetStatusTemplate' requires 'SetTemplate'")
+	}
+
+	withFault, err := withSOAPFault(response)
+	if err != nil {
+		return err
+	}
+
+	if _, err := compileTemplates(withFault); err != nil {
+		return err
+	}
+
+	if setProxy := response.SetProxy; setProxy != nil {
+		if _, err := proxy.NewHandleProxy(inst.log, setProxy); err != nil {
+			return fmt.Errorf("parameter 'SetProxy': %w", err)
+		}
+
+		if fault := response.SetFault; fault != nil && fault.Type == model.FaultTruncate {
+			return fmt.Errorf("cannot use parameter 'SetProxy' with fault '%s'", model.FaultTruncate)
+		}
+	}
+	return nil
+}
+
+// checkPlaceholders verifies that the placeholders of the match criteria compile,
+// e.g. ${regexp:...} and ${range:min..max}, and so do the array and strict object wrappers.
+//
+// Parameters:
+//   - match: the match criteria of a handle.
+//
+// Returns the first invalid placeholder error.
+func (inst *TemplateBuilder) checkPlaceholders(match model.MatchRequestTemplate) error {
+	for _, values := range []map[string]any{match.MustHeaders, match.MustPathParameters, match.MustQueryParameters, match.MustBody} {
+		if err := checkPattern(values); err != nil {
+			return err
+		}
+	}
+
+	for _, bodyPath := range match.MustBodyPaths {
+		if bodyPath.Op == model.BodyPathEquals {
+			if err := checkPattern(bodyPath.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkPattern verifies the placeholders of an expected value, including the ones
+// nested in maps and arrays, and the array and strict object wrappers.
+//
+// Returns the first invalid placeholder error.
+func checkPattern(value any) error {
+	switch v := value.(type) {
+	case string:
+		_, err := comparer.Compile(v)
+		return err
+	case map[string]any:
+		for _, item := range v {
+			if err := checkPattern(item); err != nil {
+				return err
+			}
+		}
+		if _, err := comparer.CompileObject(v); err != nil {
+			return err
+		}
+	case []any:
+		for _, item := range v {
+			if err := checkPattern(item); err != nil {
+				return err
 			}
 		}
 	}
@@ -116,3 +408,35 @@ func (inst *TemplateBuilder) checkMethod(metod model.Method) error {
 		return fmt.Errorf("unexpected method")
 	}
 }
+
+// checkFault verifies that the fault type is supported and its parameters are consistent.
+//
+// Parameters:
+//   - fault: the fault to validate, nil if the response has no fault.
+//
+// Returns an error if the fault is invalid.
+func (inst *TemplateBuilder) checkFault(fault *model.FaultTemplate) error {
+	if fault == nil {
+		return nil
+	}
+
+	switch fault.Type {
+	case model.FaultCloseConnection, model.FaultConnectionReset, model.FaultGarbage, model.FaultTruncate:
+	default:
+		return fmt.Errorf("unexpected fault type '%s'", fault.Type)
+	}
+
+	if fault.Probability != nil && (*fault.Probability < 0 || *fault.Probability > 1) {
+		return fmt.Errorf("fault 'Probability' must be between 0 and 1")
+	}
+
+	if fault.TruncateAfter < 0 || fault.GarbageSize < 0 {
+		return fmt.Errorf("fault sizes must not be negative")
+	}
+
+	if fault.TruncateAfter != 0 && fault.Type != model.FaultTruncate {
+		return fmt.Errorf("fault 'TruncateAfter' requires type '%s'", model.FaultTruncate)
+	}
+
+	return nil
+}
@@ -33,7 +33,7 @@ func TestTemplateBuilder_SuccessEmptyMethod(t *testing.T) {
 		},
 	}
 
-	err := builder.validate([]model.Template{template})
+	err := builder.Validate([]model.Template{template})
 	assert.NoError(t, err)
 }
 
@@ -57,6 +57,467 @@ func TestTemplateBuilder_ErrorValidate(t *testing.T) {
 		},
 	}
 
-	err := builder.validate([]model.Template{template})
+	err := builder.Validate([]model.Template{template})
 	assert.Error(t, err)
 }
+
+func TestTemplateBuilder_SuccessBuildYAML(t *testing.T) {
+	templates, err := NewTemplateBuilder(zap.NewNop()).Build("testdata_template_builder/success_yaml")
+	assert.NoError(t, err)
+	assert.Len(t, templates, 2)
+
+	byPath := make(map[string]model.Template)
+	for _, template := range templates {
+		byPath[template.Path] = template
+	}
+
+	users := byPath["/users"]
+	if assert.Len(t, users.Handle, 1) {
+		assert.Equal(t, model.GET, users.Handle[0].MatchRequestTemplate.MustMethod)
+		assert.Equal(t, "name", users.Handle[0].MatchRequestTemplate.MustQueryParameters["sort"])
+		assert.Equal(t, 200, users.Handle[0].SetResponseTemplate.SetStatus)
+		assert.Equal(t, "x0rx3", users.Handle[0].SetResponseTemplate.SetBody.(map[string]any)["username"])
+	}
+
+	orders := byPath["/orders"]
+	if assert.Len(t, orders.Handle, 1) {
+		assert.Equal(t, 201, orders.Handle[0].SetResponseTemplate.SetStatus)
+	}
+}
+
+func TestTemplateBuilder_ErrorYAMLBodyWithFile(t *testing.T) {
+	_, err := NewTemplateBuilder(zap.NewNop()).Build("testdata_template_builder/error_yaml_body_file")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot use parameter 'SetBody' with 'SetFile'")
+	assert.Contains(t, err.Error(), "line 6")
+	assert.Contains(t, err.Error(), "file: test.yaml")
+}
+
+func TestTemplateBuilder_ErrorYAMLSyntax(t *testing.T) {
+	_, err := NewTemplateBuilder(zap.NewNop()).Build("testdata_template_builder/error_yaml_syntax")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "line")
+	assert.Contains(t, err.Error(), "file: test.yml")
+}
+
+func TestTemplateBuilder_DefaultIDFromFileName(t *testing.T) {
+	templates, err := NewTemplateBuilder(zap.NewNop()).Build("testdata_template_builder/success")
+	assert.NoError(t, err)
+	if assert.Len(t, templates, 1) {
+		assert.Equal(t, "test.json", templates[0].ID)
+	}
+}
+
+func TestTemplateBuilder_ErrorDuplicateID(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	err := builder.Validate([]model.Template{
+		{ID: "users", Path: "/users"},
+		{ID: "users", Path: "/accounts"},
+	})
+	assert.Error(t, err)
+}
+
+func TestTemplateBuilder_ErrorScenarioStateWithoutScenario(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	template := model.Template{
+		Path: "/order",
+		Handle: []model.HandleTemplate{
+			{RequiredScenarioState: "pending"},
+		},
+	}
+
+	err := builder.Validate([]model.Template{template})
+	assert.Error(t, err)
+}
+
+func TestTemplateBuilder_ErrorValidateDelay(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	template := model.Template{
+		Path: "/slow",
+		Handle: []model.HandleTemplate{
+			{
+				SetResponseTemplate: model.SetResponseTemplate{
+					SetDelay: &model.DelayTemplate{Distribution: model.DelayUniform, Min: "2s", Max: "1s"},
+				},
+			},
+		},
+	}
+
+	err := builder.Validate([]model.Template{template})
+	assert.Error(t, err)
+}
+
+func TestTemplateBuilder_ErrorValidateFault(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	probability := 1.5
+	faults := []*model.FaultTemplate{
+		{Type: "explode"},
+		{Type: model.FaultCloseConnection, Probability: &probability},
+		{Type: model.FaultGarbage, TruncateAfter: 10},
+		{Type: model.FaultTruncate, TruncateAfter: -1},
+	}
+
+	for _, fault := range faults {
+		template := model.Template{
+			Path: "/flaky",
+			Handle: []model.HandleTemplate{
+				{SetResponseTemplate: model.SetResponseTemplate{SetFault: fault}},
+			},
+		}
+
+		err := builder.Validate([]model.Template{template})
+		assert.Error(t, err, fault.Type)
+	}
+}
+
+func TestTemplateBuilder_ErrorValidateTemplate(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	responses := []model.SetResponseTemplate{
+		{SetTemplate: true, SetHeaders: map[string]string{"X-Id": "{{.Path.id"}},
+		{SetStatusTemplate: "{{.Query.Get \"status\"}}"},
+	}
+
+	for _, response := range responses {
+		template := model.Template{
+			Path:   "/users/{id}",
+			Handle: []model.HandleTemplate{{SetResponseTemplate: response}},
+		}
+
+		err := builder.Validate([]model.Template{template})
+		assert.Error(t, err)
+	}
+}
+
+func TestTemplateBuilder_ErrorRawBodyWithBody(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	responses := []model.SetResponseTemplate{
+		{SetBody: []any{}, SetRawBody: "text"},
+		{SetRawBody: "text", SetFile: "file.txt"},
+	}
+
+	for _, response := range responses {
+		template := model.Template{
+			Path:   "/raw",
+			Handle: []model.HandleTemplate{{SetResponseTemplate: response}},
+		}
+
+		err := builder.Validate([]model.Template{template})
+		assert.Error(t, err)
+	}
+}
+
+func TestTemplateBuilder_ErrorValidateProxy(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	templates := []model.Template{
+		{Path: "/users", Proxy: "localhost:8080"},
+		{Path: "/users", Handle: []model.HandleTemplate{
+			{SetResponseTemplate: model.SetResponseTemplate{SetProxy: &model.ProxyTemplate{}}},
+		}},
+		{Path: "/users", Handle: []model.HandleTemplate{
+			{SetResponseTemplate: model.SetResponseTemplate{
+				SetProxy: &model.ProxyTemplate{URL: "http://localhost:8080"},
+				SetBody:  map[string]any{},
+			}},
+		}},
+	}
+
+	for _, template := range templates {
+		err := builder.Validate([]model.Template{template})
+		assert.Error(t, err)
+	}
+
+	err := builder.Validate([]model.Template{{Path: "/users", Proxy: "http://localhost:8080"}})
+	assert.NoError(t, err)
+}
+
+func TestTemplateBuilder_ErrorValidateBodySchema(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	schemas := []any{
+		"missing.json",
+		map[string]any{"type": "string", "pattern": "["},
+		map[string]any{"$ref": "#/$defs/missing"},
+		[]any{},
+	}
+
+	for _, bodySchema := range schemas {
+		template := model.Template{
+			Path: "/orders",
+			Handle: []model.HandleTemplate{{
+				MatchRequestTemplate: model.MatchRequestTemplate{MustBodySchema: bodySchema},
+			}},
+		}
+
+		err := builder.Validate([]model.Template{template})
+		assert.Error(t, err)
+	}
+
+	err := builder.Validate([]model.Template{{Path: "/orders", Handle: []model.HandleTemplate{{
+		MatchRequestTemplate: model.MatchRequestTemplate{MustBodySchema: map[string]any{"type": "object"}},
+	}}}})
+	assert.NoError(t, err)
+}
+
+func TestTemplateBuilder_ErrorValidateBodyPaths(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	paths := []model.BodyPathTemplate{
+		{Path: "user.age", Op: model.BodyPathExists},
+		{Path: "$.user.age", Op: "between", Value: 1},
+		{Path: "$.user.age", Op: model.BodyPathGt, Value: "18"},
+		{Path: "$.user.name", Op: model.BodyPathMatches, Value: "["},
+		{Path: "$.user.role", Op: model.BodyPathIn, Value: "admin"},
+		{Path: "$.user.name", Op: model.BodyPathEquals},
+	}
+
+	for _, path := range paths {
+		template := model.Template{
+			Path: "/users",
+			Handle: []model.HandleTemplate{{
+				MatchRequestTemplate: model.MatchRequestTemplate{MustBodyPaths: []model.BodyPathTemplate{path}},
+			}},
+		}
+
+		err := builder.Validate([]model.Template{template})
+		assert.Error(t, err, path)
+	}
+}
+
+func TestTemplateBuilder_ErrorValidatePlaceholder(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	matches := []model.MatchRequestTemplate{
+		{MustHeaders: map[string]any{"X-Id": "${regexp:[}"}},
+		{MustQueryParameters: map[string]any{"limit": "${range:10..1}"}},
+		{MustBody: map[string]any{"items": []any{map[string]any{"qty": "${gt:many}"}}}},
+		{MustBody: map[string]any{"id": "${type:uuid}"}},
+		{MustBodyPaths: []model.BodyPathTemplate{{Path: "$.id", Op: model.BodyPathEquals, Value: "${not:${regexp:(}}"}}},
+	}
+
+	for _, match := range matches {
+		template := model.Template{
+			Path:   "/users",
+			Handle: []model.HandleTemplate{{MatchRequestTemplate: match}},
+		}
+
+		err := builder.Validate([]model.Template{template})
+		assert.Error(t, err, match)
+	}
+}
+
+func TestTemplateBuilder_ErrorValidateBodyWrapper(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	bodies := []map[string]any{
+		{"tags": map[string]any{"$array": "sorted", "values": []any{"a"}}},
+		{"tags": map[string]any{"$array": "exact"}},
+		{"user": map[string]any{"$strict": "yes"}},
+	}
+
+	for _, body := range bodies {
+		template := model.Template{
+			Path:   "/orders",
+			Handle: []model.HandleTemplate{{MatchRequestTemplate: model.MatchRequestTemplate{MustBody: body}}},
+		}
+
+		err := builder.Validate([]model.Template{template})
+		assert.Error(t, err, body)
+	}
+}
+
+func TestTemplateBuilder_ErrorValidateSOAPFault(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	responses := []model.SetResponseTemplate{
+		{SetSOAPFault: &model.SOAPFaultTemplate{Version: "2.0"}},
+		{SetSOAPFault: &model.SOAPFaultTemplate{Detail: map[string]any{"@id": "1"}}},
+		{SetSOAPFault: &model.SOAPFaultTemplate{}, SetBody: map[string]any{}},
+	}
+
+	for _, response := range responses {
+		template := model.Template{
+			Path:   "/ws",
+			Handle: []model.HandleTemplate{{SetResponseTemplate: response}},
+		}
+
+		err := builder.Validate([]model.Template{template})
+		assert.Error(t, err)
+	}
+}
+
+func TestTemplateBuilder_ErrorValidateResponses(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	handles := []model.HandleTemplate{
+		{SetResponsesMode: model.ResponsesCycle},
+		{SetResponseTemplate: model.SetResponseTemplate{Repeat: 2}},
+		{SetResponseTemplate: model.SetResponseTemplate{SetStatus: 200}, SetResponses: []model.SetResponseTemplate{{SetStatus: 503}}},
+		{SetResponses: []model.SetResponseTemplate{{SetStatus: 503}}, SetResponsesMode: "random"},
+		{SetResponses: []model.SetResponseTemplate{{SetStatus: 503, Repeat: -1}}},
+		{SetResponses: []model.SetResponseTemplate{{SetProxy: &model.ProxyTemplate{URL: "http://localhost:8080"}}}},
+		{SetResponses: []model.SetResponseTemplate{{SetStatus: 200}, {SetFault: &model.FaultTemplate{Type: "unknown"}}}},
+		{SetResponseTemplate: model.SetResponseTemplate{Weight: 2}},
+		{SetResponses: []model.SetResponseTemplate{{SetStatus: 200, Weight: 2}}},
+		{SetRandomResponses: []model.SetResponseTemplate{{SetStatus: 200, Repeat: 2}}},
+		{SetRandomResponses: []model.SetResponseTemplate{{SetStatus: 200, Weight: -1}}},
+		{SetRandomResponses: []model.SetResponseTemplate{{SetStatus: 200}}, SetResponses: []model.SetResponseTemplate{{SetStatus: 500}}},
+		{SetRandomResponses: []model.SetResponseTemplate{{SetStatus: 200}}, SetResponsesMode: model.ResponsesCycle},
+	}
+
+	for _, handle := range handles {
+		template := model.Template{
+			Path:   "/orders",
+			Handle: []model.HandleTemplate{handle},
+		}
+
+		err := builder.Validate([]model.Template{template})
+		assert.Error(t, err)
+	}
+
+	valid := model.Template{
+		Path: "/orders",
+		Handle: []model.HandleTemplate{{
+			SetResponses:     []model.SetResponseTemplate{{SetStatus: 503, Repeat: 2}, {SetStatus: 200}},
+			SetResponsesMode: model.ResponsesNotFound,
+		}, {
+			SetRandomResponses: []model.SetResponseTemplate{{SetStatus: 200, Weight: 0.9}, {SetStatus: 500, Weight: 0.1}},
+		}},
+	}
+	assert.NoError(t, builder.Validate([]model.Template{valid}))
+}
+
+func TestTemplateBuilder_ErrorValidateStream(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	event := model.StreamEventTemplate{Data: "tick", Delay: "1s"}
+	responses := []model.SetResponseTemplate{
+		{SetStream: &model.StreamTemplate{}},
+		{SetStream: &model.StreamTemplate{Events: []model.StreamEventTemplate{event}, Repeat: -1}},
+		{SetStream: &model.StreamTemplate{Events: []model.StreamEventTemplate{event}, Repeat: 2, Loop: true}},
+		{SetStream: &model.StreamTemplate{Events: []model.StreamEventTemplate{event}, KeepAlive: "often"}},
+		{SetStream: &model.StreamTemplate{Events: []model.StreamEventTemplate{{Data: "tick", Delay: "-1s"}}}},
+		{SetStream: &model.StreamTemplate{Events: []model.StreamEventTemplate{{Data: "tick"}}, Loop: true}},
+		{SetStream: &model.StreamTemplate{Events: []model.StreamEventTemplate{event}}, SetRawBody: "tick"},
+		{SetStream: &model.StreamTemplate{Events: []model.StreamEventTemplate{event}}, SetFault: &model.FaultTemplate{Type: model.FaultTruncate, TruncateAfter: 1}},
+	}
+
+	for _, response := range responses {
+		template := model.Template{
+			Path:   "/events",
+			Handle: []model.HandleTemplate{{SetResponseTemplate: response}},
+		}
+
+		err := builder.Validate([]model.Template{template})
+		assert.Error(t, err)
+	}
+
+	valid := model.Template{
+		Path: "/events",
+		Handle: []model.HandleTemplate{{SetResponseTemplate: model.SetResponseTemplate{
+			SetStream: &model.StreamTemplate{Events: []model.StreamEventTemplate{event}, Loop: true, KeepAlive: "15s"},
+		}}},
+	}
+	assert.NoError(t, builder.Validate([]model.Template{valid}))
+}
+
+func TestTemplateBuilder_ErrorValidateWebSocket(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	message := model.WebSocketMessageTemplate{Message: "hello"}
+	scripts := []model.Template{
+		{Upgrade: "h2c", WebSocket: &model.WebSocketTemplate{}},
+		{Upgrade: model.UpgradeWebSocket},
+		{WebSocket: &model.WebSocketTemplate{}},
+		{Upgrade: model.UpgradeWebSocket, WebSocket: &model.WebSocketTemplate{OnConnect: []model.WebSocketMessageTemplate{{}}}},
+		{Upgrade: model.UpgradeWebSocket, WebSocket: &model.WebSocketTemplate{OnConnect: []model.WebSocketMessageTemplate{{Message: "hi", Delay: "soon"}}}},
+		{Upgrade: model.UpgradeWebSocket, WebSocket: &model.WebSocketTemplate{SetTemplate: true, OnConnect: []model.WebSocketMessageTemplate{{Message: "{{.Query"}}}},
+		{Upgrade: model.UpgradeWebSocket, WebSocket: &model.WebSocketTemplate{OnMessage: []model.WebSocketReplyTemplate{{MustMessage: "ping"}}}},
+		{Upgrade: model.UpgradeWebSocket, WebSocket: &model.WebSocketTemplate{OnMessage: []model.WebSocketReplyTemplate{{MustMessage: "${regexp:[}", Reply: []model.WebSocketMessageTemplate{message}}}}},
+		{Upgrade: model.UpgradeWebSocket, WebSocket: &model.WebSocketTemplate{Timers: []model.WebSocketTimerTemplate{{Message: "tick"}}}},
+		{Upgrade: model.UpgradeWebSocket, WebSocket: &model.WebSocketTemplate{Timers: []model.WebSocketTimerTemplate{{Every: "1s", Times: -1, Message: "tick"}}}},
+		{Upgrade: model.UpgradeWebSocket, WebSocket: &model.WebSocketTemplate{Close: &model.WebSocketCloseTemplate{Code: 1005}}},
+		{Upgrade: model.UpgradeWebSocket, WebSocket: &model.WebSocketTemplate{Close: &model.WebSocketCloseTemplate{Code: 5000}}},
+		{Upgrade: model.UpgradeWebSocket, WebSocket: &model.WebSocketTemplate{Close: &model.WebSocketCloseTemplate{After: "-1s"}}},
+	}
+
+	for _, template := range scripts {
+		template.Path = "/ws"
+		err := builder.Validate([]model.Template{template})
+		assert.Error(t, err)
+	}
+
+	valid := model.Template{
+		Path:    "/ws",
+		Upgrade: model.UpgradeWebSocket,
+		WebSocket: &model.WebSocketTemplate{
+			OnConnect: []model.WebSocketMessageTemplate{message},
+			OnMessage: []model.WebSocketReplyTemplate{
+				{MustMessage: map[string]any{"type": "${regexp:^sub}"}, Reply: []model.WebSocketMessageTemplate{message}},
+				{MustMessage: "bye", Close: &model.WebSocketCloseTemplate{Code: 4000, Reason: "bye"}},
+			},
+			Timers: []model.WebSocketTimerTemplate{{Every: "30s", Message: map[string]any{"type": "heartbeat"}}},
+			Close:  &model.WebSocketCloseTemplate{After: "5m"},
+		},
+	}
+	assert.NoError(t, builder.Validate([]model.Template{valid}))
+}
+
+func TestTemplateBuilder_ValidateHeaderValues(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	template := func(response model.SetResponseTemplate) []model.Template {
+		return []model.Template{{Path: "/login", Handle: []model.HandleTemplate{{SetResponseTemplate: response}}}}
+	}
+
+	assert.NoError(t, builder.Validate(template(model.SetResponseTemplate{
+		SetHeaders:      map[string]string{"Content-Type": "text/plain"},
+		SetHeaderValues: map[string][]string{"Set-Cookie": {"a=1", "b=2"}},
+	})))
+
+	err := builder.Validate(template(model.SetResponseTemplate{
+		SetHeaders:      map[string]string{"set-cookie": "a=1"},
+		SetHeaderValues: map[string][]string{"Set-Cookie": {"b=2"}},
+	}))
+	assert.ErrorContains(t, err, "'SetHeaders' and 'SetHeaderValues'")
+}
+
+func TestTemplateBuilder_ErrorValidateThrottle(t *testing.T) {
+	builder := NewTemplateBuilder(zap.NewNop())
+
+	responses := []model.SetResponseTemplate{
+		{SetRawBody: "slow", SetThrottle: &model.ThrottleTemplate{}},
+		{SetRawBody: "slow", SetThrottle: &model.ThrottleTemplate{BytesPerSecond: -1}},
+		{SetRawBody: "slow", SetThrottle: &model.ThrottleTemplate{Chunks: -2}},
+		{SetRawBody: "slow", SetThrottle: &model.ThrottleTemplate{Chunks: 2, ChunkDelay: "later"}},
+		{SetRawBody: "slow", SetThrottle: &model.ThrottleTemplate{BytesPerSecond: 100, ChunkDelay: "1s"}},
+		{SetProxy: &model.ProxyTemplate{URL: "http://localhost:8080"}, SetThrottle: &model.ThrottleTemplate{BytesPerSecond: 100}},
+		{SetStream: &model.StreamTemplate{Events: []model.StreamEventTemplate{{Data: "tick"}}}, SetThrottle: &model.ThrottleTemplate{BytesPerSecond: 100}},
+		{SetRawBody: "slow", SetThrottle: &model.ThrottleTemplate{Chunks: 2}, SetFault: &model.FaultTemplate{Type: model.FaultTruncate, TruncateAfter: 1}},
+	}
+
+	for _, response := range responses {
+		template := model.Template{
+			Path:   "/download",
+			Handle: []model.HandleTemplate{{SetResponseTemplate: response}},
+		}
+
+		err := builder.Validate([]model.Template{template})
+		assert.Error(t, err)
+	}
+
+	valid := model.Template{
+		Path: "/download",
+		Handle: []model.HandleTemplate{{SetResponseTemplate: model.SetResponseTemplate{
+			SetRawBody:  "slow",
+			SetThrottle: &model.ThrottleTemplate{BytesPerSecond: 1024, Chunks: 10, ChunkDelay: "100ms"},
+		}}},
+	}
+	assert.NoError(t, builder.Validate([]model.Template{valid}))
+}
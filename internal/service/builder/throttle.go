@@ -0,0 +1,40 @@
+package builder
+
+import (
+	"fmt"
+	"mockium/internal/model"
+)
+
+// newThrottle verifies the throttle of a response and compiles its pause.
+//
+// Parameters:
+//   - throttle: the throttle to compile, nil if the body is sent at once.
+//
+// Returns the compiled throttle, nil if there is none, or an error if the throttle is invalid.
+func newThrottle(throttle *model.ThrottleTemplate) (*model.Throttle, error) {
+	if throttle == nil {
+		return nil, nil
+	}
+
+	if throttle.BytesPerSecond < 0 || throttle.Chunks < 0 {
+		return nil, fmt.Errorf("throttle 'BytesPerSecond' and 'Chunks' must not be negative")
+	}
+
+	if throttle.BytesPerSecond == 0 && throttle.Chunks == 0 {
+		return nil, fmt.Errorf("parameter 'SetThrottle' requires 'BytesPerSecond' or 'Chunks'")
+	}
+
+	delay, err := parseDuration(throttle.ChunkDelay)
+	if err != nil {
+		return nil, fmt.Errorf("throttle 'ChunkDelay': %w", err)
+	}
+	if delay > 0 && throttle.Chunks == 0 {
+		return nil, fmt.Errorf("throttle 'ChunkDelay' requires 'Chunks'")
+	}
+
+	return &model.Throttle{
+		BytesPerSecond: throttle.BytesPerSecond,
+		Chunks:         throttle.Chunks,
+		ChunkDelay:     delay,
+	}, nil
+}
@@ -0,0 +1,169 @@
+package builder
+
+import (
+	"fmt"
+	"mockium/internal/model"
+	"mockium/internal/service/matcher"
+	"mockium/internal/transport"
+	"mockium/internal/transport/websocket"
+
+	"go.uber.org/zap"
+)
+
+// checkWebSocket verifies the Upgrade of a template and its WebSocket script.
+//
+// Parameters:
+//   - template: the template to validate.
+//
+// Returns an error if the script is invalid or does not match the Upgrade.
+func checkWebSocket(template model.Template) error {
+	switch {
+	case template.Upgrade != "" && template.Upgrade != model.UpgradeWebSocket:
+		return fmt.Errorf("unexpected 'Upgrade' '%s'", template.Upgrade)
+	case template.Upgrade == model.UpgradeWebSocket && template.WebSocket == nil:
+		return fmt.Errorf("parameter 'Upgrade' requires 'WebSocket'")
+	case template.Upgrade == "" && template.WebSocket != nil:
+		return fmt.Errorf("parameter 'WebSocket' requires 'Upgrade: %s'", model.UpgradeWebSocket)
+	case template.WebSocket == nil:
+		return nil
+	}
+
+	ws := template.WebSocket
+	for i, message := range ws.OnConnect {
+		if err := checkWebSocketMessage(ws, message); err != nil {
+			return fmt.Errorf("parameter 'OnConnect' %d: %w", i, err)
+		}
+	}
+
+	for i, reply := range ws.OnMessage {
+		if err := checkPattern(reply.MustMessage); err != nil {
+			return fmt.Errorf("parameter 'OnMessage' %d: %w", i, err)
+		}
+		if len(reply.Reply) == 0 && reply.Close == nil {
+			return fmt.Errorf("parameter 'OnMessage' %d: requires 'Reply' or 'Close'", i)
+		}
+		for _, message := range reply.Reply {
+			if err := checkWebSocketMessage(ws, message); err != nil {
+				return fmt.Errorf("parameter 'OnMessage' %d: %w", i, err)
+			}
+		}
+		if err := checkWebSocketClose(reply.Close); err != nil {
+			return fmt.Errorf("parameter 'OnMessage' %d: %w", i, err)
+		}
+	}
+
+	for i, timer := range ws.Timers {
+		every, err := parseDuration(timer.Every)
+		if err != nil {
+			return fmt.Errorf("parameter 'Timers' %d: 'Every': %w", i, err)
+		}
+		if every == 0 {
+			return fmt.Errorf("parameter 'Timers' %d: requires 'Every'", i)
+		}
+		if timer.Times < 0 {
+			return fmt.Errorf("parameter 'Timers' %d: 'Times' must not be negative", i)
+		}
+		if err := checkWebSocketMessage(ws, model.WebSocketMessageTemplate{Message: timer.Message}); err != nil {
+			return fmt.Errorf("parameter 'Timers' %d: %w", i, err)
+		}
+	}
+
+	return checkWebSocketClose(ws.Close)
+}
+
+// checkWebSocketMessage verifies a message of the script, its delay and templates.
+func checkWebSocketMessage(ws *model.WebSocketTemplate, message model.WebSocketMessageTemplate) error {
+	if message.Message == nil {
+		return fmt.Errorf("requires 'Message'")
+	}
+	if _, err := parseDuration(message.Delay); err != nil {
+		return fmt.Errorf("'Delay': %w", err)
+	}
+	return NewResponseBuilder(messageResponse(ws, message.Message)).err
+}
+
+// checkWebSocketClose verifies the close code and reason, and the delay before closing.
+func checkWebSocketClose(closing *model.WebSocketCloseTemplate) error {
+	if closing == nil {
+		return nil
+	}
+
+	// Codes 1004-1006 and 1015 are reserved, they are never sent in a close frame.
+	if closing.Code != 0 && (closing.Code < websocket.CloseNormal || closing.Code > 4999 ||
+		(closing.Code >= 1004 && closing.Code <= 1006) || closing.Code == 1015) {
+		return fmt.Errorf("unexpected close 'Code' %d", closing.Code)
+	}
+	if len(closing.Reason) > 123 {
+		return fmt.Errorf("close 'Reason' must not be longer than 123 bytes")
+	}
+	if _, err := parseDuration(closing.After); err != nil {
+		return fmt.Errorf("close 'After': %w", err)
+	}
+	return nil
+}
+
+// NewWebSocketScript creates the script run for every connection of a WebSocket template.
+// The messages are built by response builders, as the SetBody of a response.
+//
+// Parameters:
+//   - log: a structured logger used by the message matchers.
+//   - ws: the validated WebSocket section of a template.
+//
+// Returns a pointer to the script.
+func NewWebSocketScript(log *zap.Logger, ws *model.WebSocketTemplate) *transport.WebSocketScript {
+	script := &transport.WebSocketScript{
+		OnConnect: newWebSocketMessages(ws, ws.OnConnect),
+		Close:     newWebSocketClose(ws.Close),
+	}
+
+	for _, reply := range ws.OnMessage {
+		script.OnMessage = append(script.OnMessage, transport.WebSocketReply{
+			Matcher:  matcher.NewMessageMatcher(log, reply.MustMessage),
+			Messages: newWebSocketMessages(ws, reply.Reply),
+			Close:    newWebSocketClose(reply.Close),
+		})
+	}
+
+	for _, timer := range ws.Timers {
+		every, _ := parseDuration(timer.Every)
+		script.Timers = append(script.Timers, transport.WebSocketTimer{
+			Every:   every,
+			Times:   timer.Times,
+			Builder: NewResponseBuilder(messageResponse(ws, timer.Message)),
+		})
+	}
+
+	return script
+}
+
+// newWebSocketMessages creates the builders of the messages sent in turn.
+func newWebSocketMessages(ws *model.WebSocketTemplate, templates []model.WebSocketMessageTemplate) []transport.WebSocketMessage {
+	messages := make([]transport.WebSocketMessage, 0, len(templates))
+	for _, message := range templates {
+		delay, _ := parseDuration(message.Delay)
+		messages = append(messages, transport.WebSocketMessage{
+			Builder: NewResponseBuilder(messageResponse(ws, message.Message)),
+			Delay:   delay,
+		})
+	}
+	return messages
+}
+
+// newWebSocketClose converts the close of the script, the close code defaults to a normal closure.
+func newWebSocketClose(closing *model.WebSocketCloseTemplate) *transport.WebSocketClose {
+	if closing == nil {
+		return nil
+	}
+
+	after, _ := parseDuration(closing.After)
+	code := closing.Code
+	if code == 0 {
+		code = websocket.CloseNormal
+	}
+	return &transport.WebSocketClose{Code: code, Reason: closing.Reason, After: after}
+}
+
+// messageResponse is the response template building a message as its SetBody.
+func messageResponse(ws *model.WebSocketTemplate, message any) model.SetResponseTemplate {
+	return model.SetResponseTemplate{SetBody: message, SetTemplate: ws.SetTemplate}
+}
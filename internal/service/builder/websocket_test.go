@@ -0,0 +1,110 @@
+package builder
+
+import (
+	"context"
+	"mockium/internal/model"
+	"mockium/internal/service/scenario"
+	"mockium/internal/transport/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestNewWebSocketScript(t *testing.T) {
+	script := NewWebSocketScript(zaptest.NewLogger(t), &model.WebSocketTemplate{
+		SetTemplate: true,
+		OnConnect:   []model.WebSocketMessageTemplate{{Message: `hello {{.Query.Get "user"}}`, Delay: "10ms"}},
+		OnMessage: []model.WebSocketReplyTemplate{{
+			MustMessage: map[string]any{"type": "subscribe"},
+			Reply:       []model.WebSocketMessageTemplate{{Message: map[string]any{"channel": "${req.body:channel}", "status": "ok"}}},
+			Close:       &model.WebSocketCloseTemplate{After: "1s"},
+		}},
+		Timers: []model.WebSocketTimerTemplate{{Every: "30s", Message: "tick"}},
+		Close:  &model.WebSocketCloseTemplate{Code: 4000, Reason: "bye"},
+	})
+
+	req := httptest.NewRequest("GET", "/ws?user=alice", nil)
+	require.Len(t, script.OnConnect, 1)
+	assert.Equal(t, 10*time.Millisecond, script.OnConnect[0].Delay)
+	resp, err := script.OnConnect[0].Builder.Build(req)
+	require.NoError(t, err)
+	assert.Equal(t, "hello alice", resp.SetBody)
+
+	require.Len(t, script.OnMessage, 1)
+	reply := script.OnMessage[0]
+	assert.True(t, reply.Matcher.Match([]byte(`{"type": "subscribe", "channel": "orders"}`)))
+	assert.False(t, reply.Matcher.Match([]byte(`{"type": "unsubscribe"}`)))
+
+	message := httptest.NewRequest("GET", "/ws", strings.NewReader(`{"type": "subscribe", "channel": "orders"}`))
+	message.Header.Set("Content-Type", "application/json")
+	resp, err = reply.Messages[0].Builder.Build(message)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"channel": "orders", "status": "ok"}, resp.SetBody)
+
+	assert.Equal(t, 1000, reply.Close.Code)
+	assert.Equal(t, time.Second, reply.Close.After)
+
+	require.Len(t, script.Timers, 1)
+	assert.Equal(t, 30*time.Second, script.Timers[0].Every)
+	assert.Equal(t, 4000, script.Close.Code)
+	assert.Equal(t, "bye", script.Close.Reason)
+}
+
+type nopProcessLogger struct{}
+
+func (nopProcessLogger) Log(*model.ProcessLoggingFileds) {}
+
+func TestBuildRoutes_WebSocket(t *testing.T) {
+	template := &model.Template{
+		ID:      "ws.yaml",
+		Path:    "/ws",
+		Upgrade: model.UpgradeWebSocket,
+		Handle: []model.HandleTemplate{{
+			MatchRequestTemplate: model.MatchRequestTemplate{MustMethod: model.GET},
+			SetResponseTemplate:  model.SetResponseTemplate{SetRawBody: "plain"},
+		}},
+		WebSocket: &model.WebSocketTemplate{
+			OnMessage: []model.WebSocketReplyTemplate{{
+				MustMessage: "${regexp:^echo }",
+				Reply:       []model.WebSocketMessageTemplate{{Message: "${req.body:text}"}},
+			}, {
+				Reply: []model.WebSocketMessageTemplate{{Message: map[string]any{"got": "${req.body:text}"}}},
+				Close: &model.WebSocketCloseTemplate{Code: 4000},
+			}},
+		},
+	}
+
+	// The connection outlives the test by a moment, since httptest does not track hijacked connections.
+	router := BuildRoutes(zap.NewNop(), nopProcessLogger{}, scenario.New(), Options{}, template)
+	srv := httptest.NewServer(router.Handler(model.GET))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "GET requests without upgrade are served by the handles")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, srv.URL, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"text": "hi"}`)))
+	_, message, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"got": "hi"}`, string(message))
+
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, 4000, closeErr.Code)
+}
@@ -0,0 +1,165 @@
+package comparer
+
+import "fmt"
+
+// Keys of the wrapper objects changing how arrays and objects are compared.
+const (
+	// ArrayKey selects the array mode: {"$array": "containsAll", "values": [...]}.
+	ArrayKey = "$array"
+	// ArrayValuesKey holds the expected elements of an array wrapper.
+	ArrayValuesKey = "values"
+	// StrictKey rejects the keys of the actual object that are not expected: {"$strict": true, ...}.
+	StrictKey = "$strict"
+)
+
+// Array modes.
+const (
+	ArrayExact       = "exact"       // Same length, elements in the same order.
+	ArrayAnyOrder    = "anyOrder"    // Same length, elements in any order.
+	ArrayContainsAll = "containsAll" // Every expected element is present, in any order, extra elements allowed.
+	ArrayContainsAny = "containsAny" // At least one expected element is present.
+	ArrayEvery       = "every"       // Every element matches one of the expected values, e.g. a pattern.
+)
+
+// CompileObject compiles the array and strict object wrappers.
+//
+// Parameters:
+//   - object: an expected object, its values already precompiled.
+//
+// Returns a Predicate for a wrapper, the object itself otherwise,
+// or an error if the wrapper is invalid.
+func CompileObject(object map[string]any) (any, error) {
+	if mode, ok := object[ArrayKey]; ok {
+		return compileArray(mode, object)
+	}
+
+	strict, ok := object[StrictKey]
+	if !ok {
+		return object, nil
+	}
+
+	isStrict, ok := strict.(bool)
+	if !ok {
+		return nil, fmt.Errorf("'%s' must be a boolean", StrictKey)
+	}
+
+	fields := make(map[string]any, len(object))
+	for key, value := range object {
+		if key != StrictKey {
+			fields[key] = value
+		}
+	}
+	if !isStrict {
+		return fields, nil
+	}
+
+	comparer := New()
+	return predicate(func(actual any) bool {
+		actualMap, ok := actual.(map[string]any)
+		if !ok || !comparer.compareMaps(fields, actualMap) {
+			return false
+		}
+		for key := range actualMap {
+			if _, expected := fields[key]; !expected {
+				return false
+			}
+		}
+		return true
+	}), nil
+}
+
+// compileArray compiles an array wrapper.
+func compileArray(mode any, object map[string]any) (Predicate, error) {
+	values, ok := object[ArrayValuesKey].([]any)
+	if !ok {
+		return nil, fmt.Errorf("'%s' requires the '%s' list", ArrayKey, ArrayValuesKey)
+	}
+
+	for key := range object {
+		if key != ArrayKey && key != ArrayValuesKey {
+			return nil, fmt.Errorf("unexpected key '%s' of '%s'", key, ArrayKey)
+		}
+	}
+
+	comparer := New()
+	var match func(actual []any) bool
+	switch mode {
+	case ArrayExact:
+		match = func(actual []any) bool { return comparer.compareSlices(values, actual) }
+	case ArrayAnyOrder:
+		match = func(actual []any) bool {
+			return len(actual) == len(values) && comparer.matchElements(values, actual) == len(values)
+		}
+	case ArrayContainsAll:
+		match = func(actual []any) bool { return comparer.matchElements(values, actual) == len(values) }
+	case ArrayContainsAny:
+		match = func(actual []any) bool {
+			for _, expected := range values {
+				for _, item := range actual {
+					if comparer.Compare(expected, item) {
+						return true
+					}
+				}
+			}
+			return false
+		}
+	case ArrayEvery:
+		match = func(actual []any) bool {
+			for _, item := range actual {
+				matched := false
+				for _, expected := range values {
+					if comparer.Compare(expected, item) {
+						matched = true
+						break
+					}
+				}
+				if !matched {
+					return false
+				}
+			}
+			return true
+		}
+	default:
+		return nil, fmt.Errorf("unexpected '%s' mode '%v'", ArrayKey, mode)
+	}
+
+	return predicate(func(actual any) bool {
+		items, ok := actual.([]any)
+		return ok && match(items)
+	}), nil
+}
+
+// matchElements pairs the expected elements with distinct actual elements they match,
+// so that patterns matching several elements do not take the element another one needs.
+//
+// Returns the number of expected elements paired.
+func (inst *Comparer) matchElements(expected, actual []any) int {
+	// owner[j] is the index of the expected element paired with actual[j], -1 if none.
+	owner := make([]int, len(actual))
+	for j := range owner {
+		owner[j] = -1
+	}
+
+	var assign func(i int, visited []bool) bool
+	assign = func(i int, visited []bool) bool {
+		for j := range actual {
+			if visited[j] || !inst.Compare(expected[i], actual[j]) {
+				continue
+			}
+			visited[j] = true
+			if owner[j] < 0 || assign(owner[j], visited) {
+				owner[j] = i
+				return true
+			}
+		}
+		return false
+	}
+
+	paired := 0
+	for i := range expected {
+		if assign(i, make([]bool, len(actual))) {
+			paired++
+		}
+	}
+	return paired
+}
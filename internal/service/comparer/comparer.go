@@ -17,15 +17,17 @@ func New() *Comparer {
 // It supports:
 // - Regular expression matching for strings
 // - Deep comparison of maps and slices
-// - Special placeholder values (like AnyValuePlaceholder)
+// - Special placeholder values (like AnyValuePlaceholder) and precompiled placeholders (see Compile)
 // - Type-aware comparison of numeric values
 type Comparer struct{}
 
 // Compare checks if the actual value matches the expected pattern.
 // It supports:
 //   - Regexp matching when expected is *regexp.Regexp
+//   - Precompiled placeholders when expected is a Predicate
 //   - Direct comparison for primitive types
 //   - Special AnyValuePlaceholder that matches any value
+//   - Special FileParamName that matches an uploaded file of a multipart/form-data body
 //   - Deep comparison for slices and maps
 //   - Cross-type numeric comparisons (int vs float)
 //
@@ -42,9 +44,14 @@ func (inst *Comparer) Compare(expected, actual any) bool {
 			return exp.MatchString(str)
 		}
 		return false
+	case Predicate:
+		return exp.Match(actual)
 	case string:
-		if exp == constants.AnyValuePlaceholder {
+		switch exp {
+		case constants.AnyValuePlaceholder:
 			return true
+		case constants.FileParamName:
+			return isFile(actual)
 		}
 		return exp == actual
 	case []any:
@@ -107,6 +114,7 @@ func (inst *Comparer) compareSlices(expected, actual []any) bool {
 //   - Each corresponding value matches according to Compare()
 //
 // Note: This is not a symmetric comparison - extra keys in actual are ignored.
+// A key expected to be ${absent} must not exist in actual.
 //
 // Parameters:
 //   - expected: The map containing expected keys/patterns
@@ -116,9 +124,27 @@ func (inst *Comparer) compareSlices(expected, actual []any) bool {
 //   - true if all expected keys and values match, false otherwise
 func (inst *Comparer) compareMaps(expected, actual map[string]any) bool {
 	for key, expVal := range expected {
-		if actVal, exists := actual[key]; !exists || !inst.Compare(expVal, actVal) {
+		actVal, exists := actual[key]
+		if !exists {
+			if !IsAbsent(expVal) {
+				return false
+			}
+			continue
+		}
+
+		if !inst.Compare(expVal, actVal) {
 			return false
 		}
 	}
 	return true
 }
+
+// isFile reports whether the value is an uploaded file of a parsed multipart/form-data body.
+func isFile(value any) bool {
+	file, ok := value.(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = file[constants.FileKeyName].(string)
+	return ok
+}
@@ -79,3 +79,143 @@ func TestComparer_compare(t *testing.T) {
 		})
 	}
 }
+
+func TestCompile(t *testing.T) {
+	comparer := New()
+
+	tests := []struct {
+		placeholder string
+		actual      any
+		want        bool
+	}{
+		{"${regexp:^[a-z]+$}", "abc", true},
+		{"${not:abc}", "abd", true},
+		{"${not:abc}", "abc", false},
+		{"${not:${regexp:^a}}", "bcd", true},
+		{"${oneOf:a,b,c}", "b", true},
+		{"${oneOf:1, 2}", float64(2), true},
+		{"${oneOf:a,b,c}", "d", false},
+		{"${range:1..100}", "42", true},
+		{"${range:1..100}", float64(100), true},
+		{"${range:1..100}", float64(101), false},
+		{"${range:-1.5..0}", "-1", true},
+		{"${gt:18}", float64(19), true},
+		{"${gt:18}", "18", false},
+		{"${lt:18}", 17, true},
+		{"${lt:18}", "abc", false},
+		{"${contains:ell}", "hello", true},
+		{"${contains:b}", []any{"a", "b"}, true},
+		{"${contains:c}", []any{"a", "b"}, false},
+		{"${startsWith:Bearer }", "Bearer token", true},
+		{"${endsWith:.json}", "file.xml", false},
+		{"${caseInsensitive:Json}", "JSON", true},
+		{"${type:number}", float64(1), true},
+		{"${type:number}", "1.5", true},
+		{"${type:string}", float64(1), false},
+		{"${type:bool}", "true", true},
+		{"${type:array|object}", map[string]any{}, true},
+		{"${type:null}", nil, true},
+		{"${absent}", "value", false},
+		{"${uuid}", "3fa85f64-5717-4562-b3fc-2c963f66afa6", true},
+		{"${uuid}", "3fa85f64", false},
+		{"${email}", "user@example.com", true},
+		{"${email}", "user", false},
+		{"${iso8601}", "2024-05-01T10:00:00Z", true},
+		{"${iso8601}", "2024-05-01", true},
+		{"${iso8601}", "01.05.2024", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.placeholder, func(t *testing.T) {
+			expected, err := Compile(tt.placeholder)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, comparer.Compare(expected, tt.actual), tt.actual)
+		})
+	}
+}
+
+func TestCompile_Literal(t *testing.T) {
+	for _, value := range []string{"plain", constants.AnyValuePlaceholder, constants.FileParamName, "${unknown:x}"} {
+		compiled, err := Compile(value)
+		assert.NoError(t, err)
+		assert.Equal(t, value, compiled)
+	}
+}
+
+func TestCompile_Error(t *testing.T) {
+	for _, value := range []string{"${regexp:[}", "${range:1}", "${range:5..1}", "${gt:x}", "${type:date}", "${not:${regexp:(}}"} {
+		_, err := Compile(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestComparer_Absent(t *testing.T) {
+	comparer := New()
+	absent, err := Compile("${absent}")
+	assert.NoError(t, err)
+
+	assert.True(t, IsAbsent(absent))
+	assert.True(t, comparer.Compare(map[string]any{"coupon": absent}, map[string]any{"id": 1.0}))
+	assert.False(t, comparer.Compare(map[string]any{"coupon": absent}, map[string]any{"coupon": ""}))
+}
+
+func TestCompileObject_Array(t *testing.T) {
+	comparer := New()
+	pattern := regexp.MustCompile("^A")
+
+	tests := []struct {
+		name   string
+		mode   string
+		values []any
+		actual any
+		want   bool
+	}{
+		{"exact", ArrayExact, []any{"a", "b"}, []any{"a", "b"}, true},
+		{"exact order", ArrayExact, []any{"a", "b"}, []any{"b", "a"}, false},
+		{"any order", ArrayAnyOrder, []any{"a", "b"}, []any{"b", "a"}, true},
+		{"any order extra", ArrayAnyOrder, []any{"a", "b"}, []any{"b", "a", "c"}, false},
+		{"any order pattern", ArrayAnyOrder, []any{pattern, "AB"}, []any{"AB", "AC"}, true},
+		{"contains all", ArrayContainsAll, []any{"c", "a"}, []any{"a", "b", "c"}, true},
+		{"contains all missing", ArrayContainsAll, []any{"a", "d"}, []any{"a", "b", "c"}, false},
+		{"contains all duplicates", ArrayContainsAll, []any{"a", "a"}, []any{"a", "b"}, false},
+		{"contains any", ArrayContainsAny, []any{"x", "b"}, []any{"a", "b"}, true},
+		{"contains any none", ArrayContainsAny, []any{"x", "y"}, []any{"a", "b"}, false},
+		{"every", ArrayEvery, []any{pattern}, []any{"AB", "AC"}, true},
+		{"every mismatch", ArrayEvery, []any{pattern}, []any{"AB", "BC"}, false},
+		{"not an array", ArrayContainsAny, []any{"a"}, "a", false},
+		{"objects", ArrayContainsAll, []any{map[string]any{"sku": "B"}}, []any{map[string]any{"sku": "A"}, map[string]any{"sku": "B", "qty": 1.0}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := CompileObject(map[string]any{ArrayKey: tt.mode, ArrayValuesKey: tt.values})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, comparer.Compare(expected, tt.actual))
+		})
+	}
+}
+
+func TestCompileObject_Strict(t *testing.T) {
+	comparer := New()
+
+	strict, err := CompileObject(map[string]any{StrictKey: true, "id": 1.0})
+	assert.NoError(t, err)
+	assert.True(t, comparer.Compare(strict, map[string]any{"id": 1.0}))
+	assert.False(t, comparer.Compare(strict, map[string]any{"id": 1.0, "name": "Ann"}))
+
+	lenient, err := CompileObject(map[string]any{StrictKey: false, "id": 1.0})
+	assert.NoError(t, err)
+	assert.True(t, comparer.Compare(lenient, map[string]any{"id": 1.0, "name": "Ann"}))
+}
+
+func TestCompileObject_Error(t *testing.T) {
+	for _, object := range []map[string]any{
+		{ArrayKey: "sorted", ArrayValuesKey: []any{}},
+		{ArrayKey: ArrayExact},
+		{ArrayKey: ArrayExact, ArrayValuesKey: []any{}, "extra": 1},
+		{StrictKey: "yes"},
+	} {
+		_, err := CompileObject(object)
+		assert.Error(t, err, object)
+	}
+}
@@ -0,0 +1,250 @@
+package comparer
+
+import (
+	"fmt"
+	"mockium/internal/service/constants"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Predicate is a precompiled placeholder checking a single actual value.
+type Predicate interface {
+	Match(actual any) bool
+}
+
+// predicate adapts a function to the Predicate interface.
+type predicate func(actual any) bool
+
+func (inst predicate) Match(actual any) bool {
+	return inst(actual)
+}
+
+// absent is the ${absent} placeholder. It is satisfied only by a missing value,
+// which the matchers check with IsAbsent, so it never matches a present value.
+type absent struct{}
+
+func (absent) Match(any) bool {
+	return false
+}
+
+// regexpPlaceholder matches a placeholder: ${name} or ${name:argument}.
+var regexpPlaceholder = regexp.MustCompile(`^\$\{([a-zA-Z0-9]+)(?::(.*))?\}$`)
+
+// uuidPattern matches the textual form of a UUID.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// iso8601Layouts are the accepted ISO 8601 date and time layouts.
+var iso8601Layouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02T15:04", time.DateOnly}
+
+// Compile precompiles a placeholder of the matching language, e.g. ${regexp:^a} or ${range:1..100}.
+//
+// Parameters:
+//   - value: the expected value of a template.
+//
+// Returns a *regexp.Regexp for ${regexp:...}, a Predicate for the other placeholders,
+// or the value itself if it is not a known placeholder; an error if the placeholder argument is invalid.
+func Compile(value string) (any, error) {
+	groups := regexpPlaceholder.FindStringSubmatch(value)
+	if groups == nil {
+		return value, nil
+	}
+
+	name, arg := groups[1], groups[2]
+	switch name {
+	case constants.RegexpValuePlaceholder:
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("placeholder '%s': %w", value, err)
+		}
+		return re, nil
+	case "not":
+		inner, err := Compile(arg)
+		if err != nil {
+			return nil, err
+		}
+		comparer := New()
+		return predicate(func(actual any) bool { return !comparer.Compare(inner, actual) }), nil
+	case "oneOf":
+		options := strings.Split(arg, ",")
+		return predicate(func(actual any) bool {
+			text, ok := toText(actual)
+			if !ok {
+				return false
+			}
+			for _, option := range options {
+				if text == strings.TrimSpace(option) {
+					return true
+				}
+			}
+			return false
+		}), nil
+	case "range":
+		bounds := strings.SplitN(arg, "..", 2)
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("placeholder '%s': range must be 'min..max'", value)
+		}
+		lower, errLower := strconv.ParseFloat(strings.TrimSpace(bounds[0]), 64)
+		upper, errUpper := strconv.ParseFloat(strings.TrimSpace(bounds[1]), 64)
+		if errLower != nil || errUpper != nil || lower > upper {
+			return nil, fmt.Errorf("placeholder '%s': range must be 'min..max' numbers", value)
+		}
+		return predicate(func(actual any) bool {
+			number, ok := toNumber(actual)
+			return ok && number >= lower && number <= upper
+		}), nil
+	case "gt", "lt":
+		limit, err := strconv.ParseFloat(strings.TrimSpace(arg), 64)
+		if err != nil {
+			return nil, fmt.Errorf("placeholder '%s': expected a number", value)
+		}
+		return predicate(func(actual any) bool {
+			number, ok := toNumber(actual)
+			return ok && (name == "gt" && number > limit || name == "lt" && number < limit)
+		}), nil
+	case "contains":
+		return predicate(func(actual any) bool {
+			if items, ok := actual.([]any); ok {
+				for _, item := range items {
+					if text, ok := toText(item); ok && text == arg {
+						return true
+					}
+				}
+				return false
+			}
+			text, ok := toText(actual)
+			return ok && strings.Contains(text, arg)
+		}), nil
+	case "startsWith":
+		return textPredicate(func(text string) bool { return strings.HasPrefix(text, arg) }), nil
+	case "endsWith":
+		return textPredicate(func(text string) bool { return strings.HasSuffix(text, arg) }), nil
+	case "caseInsensitive":
+		return textPredicate(func(text string) bool { return strings.EqualFold(text, arg) }), nil
+	case "type":
+		types := strings.Split(arg, "|")
+		for _, t := range types {
+			if !knownType(t) {
+				return nil, fmt.Errorf("placeholder '%s': unexpected type '%s'", value, t)
+			}
+		}
+		return predicate(func(actual any) bool {
+			for _, t := range types {
+				if hasType(t, actual) {
+					return true
+				}
+			}
+			return false
+		}), nil
+	case "absent":
+		return absent{}, nil
+	case "uuid":
+		return textPredicate(uuidPattern.MatchString), nil
+	case "email":
+		return textPredicate(func(text string) bool {
+			address, err := mail.ParseAddress(text)
+			return err == nil && address.Address == text
+		}), nil
+	case "iso8601":
+		return textPredicate(func(text string) bool {
+			for _, layout := range iso8601Layouts {
+				if _, err := time.Parse(layout, text); err == nil {
+					return true
+				}
+			}
+			return false
+		}), nil
+	}
+
+	// Unknown names are compared literally, ${file} is handled by Comparer.Compare.
+	return value, nil
+}
+
+// IsAbsent reports whether the expected value is the ${absent} placeholder,
+// which is the only one satisfied by a missing value.
+func IsAbsent(expected any) bool {
+	_, ok := expected.(absent)
+	return ok
+}
+
+// textPredicate creates a predicate checking the textual form of string, number and boolean values.
+func textPredicate(check func(text string) bool) Predicate {
+	return predicate(func(actual any) bool {
+		text, ok := toText(actual)
+		return ok && check(text)
+	})
+}
+
+// toText returns the textual form of string, number and boolean values.
+func toText(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// toNumber returns the value of numbers and of numbers written as text, e.g. query parameters.
+func toNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// knownType reports whether the name is supported by the ${type:...} placeholder.
+func knownType(name string) bool {
+	switch name {
+	case "number", "string", "bool", "array", "object", "null":
+		return true
+	}
+	return false
+}
+
+// hasType reports whether the value is of the type. Numbers and booleans written as text
+// count too, as headers, query and path parameters are always text.
+func hasType(name string, value any) bool {
+	switch name {
+	case "number":
+		_, ok := toNumber(value)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "bool":
+		switch v := value.(type) {
+		case bool:
+			return true
+		case string:
+			_, err := strconv.ParseBool(v)
+			return err == nil
+		}
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return false
+}
@@ -7,15 +7,18 @@ import (
 
 // RegexpResponseValuePlaceholder is a regular expression that matches a specific format for response value placeholders.
 // The format is: ${req.<param_type>:<param_name>}
-// where <param_type> can be one of the following: headers, queryParams, pathParams, formParams, body
+// where <param_type> can be one of the following: headers, queryParams, pathParams, formParams, body,
+// file (name of an uploaded file), fileSize (size of an uploaded file in bytes)
 // and <param_name> can be any alphanumeric string, underscore, or hyphen.
 var RegexpResponseValuePlaceholder = regexp.MustCompile(
-	fmt.Sprintf("^\\$\\{(req)\\.(%s|%s|%s|%s|%s):([a-zA-Z0-9_-]+|\\*)\\}$",
+	fmt.Sprintf("^\\$\\{(req)\\.(%s|%s|%s|%s|%s|%s|%s):([a-zA-Z0-9_-]+|\\*)\\}$",
 		Headers,
 		Query,
 		Path,
 		Form,
 		Body,
+		FileSize,
+		File,
 	),
 )
 
@@ -44,15 +47,26 @@ const (
 	FileParamName = "${file}"
 )
 
+// Keys of an uploaded file in a parsed multipart/form-data body.
+const (
+	FileKeyName        = "filename"
+	FileKeyContentType = "contentType"
+	FileKeySize        = "size"
+	FileKeySHA256      = "sha256"
+	FileKeyMD5         = "md5"
+)
+
 type Parameter string
 
 // The following constants represent the different types of placeholders that can be used.
 const (
-	Headers Parameter = "headers"
-	Query   Parameter = "query"
-	Path    Parameter = "path"
-	Form    Parameter = "form"
-	Body    Parameter = "body"
+	Headers  Parameter = "headers"
+	Query    Parameter = "query"
+	Path     Parameter = "path"
+	Form     Parameter = "form"
+	Body     Parameter = "body"
+	File     Parameter = "file"
+	FileSize Parameter = "fileSize"
 )
 
 const (
@@ -69,6 +83,7 @@ const (
 	ContentTypeApplicationXML         = "application/xml"
 	ContentTypeApplicationProblemJSON = "application/problem+json"
 	ContentTypeApplicationProblemXML  = "application/problem+xml"
+	ContentTypeApplicationSOAPXML     = "application/soap+xml"
 
 	// Forms
 	ContentTypeFormURLEncoded = "application/x-www-form-urlencoded"
@@ -100,3 +115,8 @@ const (
 	ContentTypeFontTTF   = "font/ttf"
 	ContentTypeFontOTF   = "font/otf"
 )
+
+const (
+	// HeaderSOAPAction is the header with the action of a SOAP 1.1 request.
+	HeaderSOAPAction = "SOAPAction"
+)
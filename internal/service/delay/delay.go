@@ -0,0 +1,159 @@
+package delay
+
+import (
+	"fmt"
+	"math"
+	"mockium/internal/model"
+	"mockium/internal/service/random"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Delay samples response delays from a fixed value or a distribution.
+type Delay struct {
+	distribution string        // Distribution name, empty for a fixed delay.
+	fixed        time.Duration // Fixed delay.
+	min          time.Duration // Lower bound of the uniform distribution.
+	max          time.Duration // Upper bound of the uniform distribution, cap of the other ones.
+	median       time.Duration // Median of the lognormal distribution.
+	sigma        float64       // Shape of the lognormal distribution.
+	mean         time.Duration // Mean of the normal distribution.
+	stdDev       time.Duration // Standard deviation of the normal distribution.
+}
+
+// New compiles a delay template.
+//
+// Returns an error if the template is inconsistent or a duration cannot be parsed.
+func New(template *model.DelayTemplate) (*Delay, error) {
+	if template == nil {
+		return nil, nil
+	}
+
+	if template.Fixed != "" && template.Distribution != "" {
+		return nil, fmt.Errorf("cannot use parameter 'Fixed' with 'Distribution'")
+	}
+
+	var err error
+	d := &Delay{distribution: template.Distribution}
+
+	if d.max, err = parseDuration("Max", template.Max); err != nil {
+		return nil, err
+	}
+
+	switch template.Distribution {
+	case "":
+		if template.Fixed == "" {
+			return nil, fmt.Errorf("delay requires 'Fixed' or 'Distribution'")
+		}
+		if d.fixed, err = parseDuration("Fixed", template.Fixed); err != nil {
+			return nil, err
+		}
+	case model.DelayUniform:
+		if template.Min == "" || template.Max == "" {
+			return nil, fmt.Errorf("uniform delay requires 'Min' and 'Max'")
+		}
+		if d.min, err = parseDuration("Min", template.Min); err != nil {
+			return nil, err
+		}
+		if d.min > d.max {
+			return nil, fmt.Errorf("uniform delay 'Min' is greater than 'Max'")
+		}
+	case model.DelayLognormal:
+		if template.Median == "" {
+			return nil, fmt.Errorf("lognormal delay requires 'Median'")
+		}
+		if d.median, err = parseDuration("Median", template.Median); err != nil {
+			return nil, err
+		}
+		if template.Sigma < 0 {
+			return nil, fmt.Errorf("lognormal delay 'Sigma' must not be negative")
+		}
+		d.sigma = template.Sigma
+	case model.DelayNormal:
+		if template.Mean == "" {
+			return nil, fmt.Errorf("normal delay requires 'Mean'")
+		}
+		if d.mean, err = parseDuration("Mean", template.Mean); err != nil {
+			return nil, err
+		}
+		if d.stdDev, err = parseDuration("StdDev", template.StdDev); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unexpected delay distribution '%s'", template.Distribution)
+	}
+
+	return d, nil
+}
+
+// Parse reads a delay template from its compact form, used by command line flags:
+//   - "200ms" - fixed delay
+//   - "uniform:100ms,300ms" - uniform delay between min and max
+//   - "lognormal:200ms,0.5" - lognormal delay with median and sigma
+//   - "normal:200ms,50ms" - normal delay with mean and standard deviation
+func Parse(spec string) (*model.DelayTemplate, error) {
+	distribution, params, found := strings.Cut(spec, ":")
+	if !found {
+		return &model.DelayTemplate{Fixed: spec}, nil
+	}
+
+	values := strings.Split(params, ",")
+	if len(values) != 2 {
+		return nil, fmt.Errorf("delay '%s' requires two comma separated parameters", spec)
+	}
+
+	switch distribution {
+	case model.DelayUniform:
+		return &model.DelayTemplate{Distribution: distribution, Min: values[0], Max: values[1]}, nil
+	case model.DelayLognormal:
+		sigma, err := strconv.ParseFloat(values[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lognormal delay sigma '%s'", values[1])
+		}
+		return &model.DelayTemplate{Distribution: distribution, Median: values[0], Sigma: sigma}, nil
+	case model.DelayNormal:
+		return &model.DelayTemplate{Distribution: distribution, Mean: values[0], StdDev: values[1]}, nil
+	default:
+		return nil, fmt.Errorf("unexpected delay distribution '%s'", distribution)
+	}
+}
+
+// Sample returns the next delay.
+func (inst *Delay) Sample() time.Duration {
+	var d time.Duration
+	switch inst.distribution {
+	case model.DelayUniform:
+		d = inst.min + time.Duration(random.Int63n(int64(inst.max-inst.min)+1))
+	case model.DelayLognormal:
+		d = time.Duration(float64(inst.median) * math.Exp(inst.sigma*random.NormFloat64()))
+	case model.DelayNormal:
+		d = inst.mean + time.Duration(float64(inst.stdDev)*random.NormFloat64())
+	default:
+		return inst.fixed
+	}
+
+	if d < 0 {
+		d = 0
+	}
+	if inst.max > 0 && d > inst.max {
+		d = inst.max
+	}
+	return d
+}
+
+// parseDuration parses a non-negative duration, an empty value is zero.
+func parseDuration(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid delay '%s': %w", name, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("delay '%s' must not be negative", name)
+	}
+	return d, nil
+}
@@ -0,0 +1,90 @@
+package delay
+
+import (
+	"mockium/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		template *model.DelayTemplate
+	}{
+		{name: "Empty", template: &model.DelayTemplate{}},
+		{name: "Invalid duration", template: &model.DelayTemplate{Fixed: "soon"}},
+		{name: "Negative duration", template: &model.DelayTemplate{Fixed: "-1s"}},
+		{name: "Fixed with distribution", template: &model.DelayTemplate{Fixed: "1s", Distribution: model.DelayUniform, Min: "1s", Max: "2s"}},
+		{name: "Uniform without max", template: &model.DelayTemplate{Distribution: model.DelayUniform, Min: "1s"}},
+		{name: "Uniform min greater than max", template: &model.DelayTemplate{Distribution: model.DelayUniform, Min: "2s", Max: "1s"}},
+		{name: "Lognormal without median", template: &model.DelayTemplate{Distribution: model.DelayLognormal, Sigma: 0.5}},
+		{name: "Lognormal negative sigma", template: &model.DelayTemplate{Distribution: model.DelayLognormal, Median: "1s", Sigma: -1}},
+		{name: "Normal without mean", template: &model.DelayTemplate{Distribution: model.DelayNormal, StdDev: "1s"}},
+		{name: "Unknown distribution", template: &model.DelayTemplate{Distribution: "poisson"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.template)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDelay_Sample(t *testing.T) {
+	tests := []struct {
+		name     string
+		template *model.DelayTemplate
+		min      time.Duration
+		max      time.Duration
+	}{
+		{name: "Fixed", template: &model.DelayTemplate{Fixed: "150ms"}, min: 150 * time.Millisecond, max: 150 * time.Millisecond},
+		{name: "Uniform", template: &model.DelayTemplate{Distribution: model.DelayUniform, Min: "100ms", Max: "300ms"}, min: 100 * time.Millisecond, max: 300 * time.Millisecond},
+		{name: "Lognormal capped", template: &model.DelayTemplate{Distribution: model.DelayLognormal, Median: "200ms", Sigma: 2, Max: "1s"}, min: 0, max: time.Second},
+		{name: "Normal", template: &model.DelayTemplate{Distribution: model.DelayNormal, Mean: "100ms", StdDev: "200ms"}, min: 0, max: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := New(tt.template)
+			require.NoError(t, err)
+
+			for i := 0; i < 1000; i++ {
+				sample := d.Sample()
+				assert.GreaterOrEqual(t, sample, tt.min)
+				assert.LessOrEqual(t, sample, tt.max)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    *model.DelayTemplate
+		wantErr bool
+	}{
+		{spec: "200ms", want: &model.DelayTemplate{Fixed: "200ms"}},
+		{spec: "uniform:100ms,300ms", want: &model.DelayTemplate{Distribution: model.DelayUniform, Min: "100ms", Max: "300ms"}},
+		{spec: "lognormal:200ms,0.5", want: &model.DelayTemplate{Distribution: model.DelayLognormal, Median: "200ms", Sigma: 0.5}},
+		{spec: "normal:200ms,50ms", want: &model.DelayTemplate{Distribution: model.DelayNormal, Mean: "200ms", StdDev: "50ms"}},
+		{spec: "lognormal:200ms,wide", wantErr: true},
+		{spec: "uniform:100ms", wantErr: true},
+		{spec: "poisson:1s,2s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := Parse(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Package formdata parses multipart/form-data request bodies into their fields
+// and the descriptions of the uploaded files.
+package formdata
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mockium/internal/service/constants"
+	"net/http"
+	"net/url"
+)
+
+// File describes an uploaded file. The content itself is not kept.
+type File struct {
+	Name        string // File name sent by the client, without directories.
+	ContentType string // Content-Type of the part, empty if the client sent none.
+	Size        int64  // Size of the content in bytes.
+	SHA256      string // Hex encoded SHA-256 checksum of the content.
+	MD5         string // Hex encoded MD5 checksum of the content.
+}
+
+// Fields returns the file as a map compared with the MustBody of a template, e.g.
+// {"filename": "${regexp:\\.png$}", "size": "${range:1..1024}"}.
+func (inst File) Fields() map[string]any {
+	return map[string]any{
+		constants.FileKeyName:        inst.Name,
+		constants.FileKeyContentType: inst.ContentType,
+		constants.FileKeySize:        inst.Size,
+		constants.FileKeySHA256:      inst.SHA256,
+		constants.FileKeyMD5:         inst.MD5,
+	}
+}
+
+// Form is a parsed multipart/form-data body.
+type Form struct {
+	Values url.Values        // Values of the fields that are not files.
+	Files  map[string][]File // Uploaded files by their field name.
+}
+
+// IsFormData reports whether the Content-Type is multipart/form-data.
+func IsFormData(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == constants.ContentTypeFormData
+}
+
+// ParseRequest parses the multipart/form-data body of the request.
+// The body is read and restored, so it can still be read afterwards.
+//
+// Parameters:
+//   - req: the incoming HTTP request.
+//
+// Returns the parsed form, or an error if the body is not valid multipart/form-data.
+func ParseRequest(req *http.Request) (*Form, error) {
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	if mediaType != constants.ContentTypeFormData || params["boundary"] == "" {
+		return nil, fmt.Errorf("unexpected Content-Type '%s'", req.Header.Get("Content-Type"))
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, errors.New("empty multipart body")
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return Parse(bytes.NewReader(body), params["boundary"])
+}
+
+// Parse parses a multipart/form-data body.
+//
+// Parameters:
+//   - body: the body to parse.
+//   - boundary: the boundary parameter of the Content-Type.
+//
+// Returns the parsed form or an error if the body is malformed.
+func Parse(body io.Reader, boundary string) (*Form, error) {
+	form := &Form{
+		Values: make(url.Values),
+		Files:  make(map[string][]File),
+	}
+
+	reader := multipart.NewReader(body, boundary)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return form, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := part.FormName()
+		if name == "" {
+			part.Close()
+			continue
+		}
+
+		if part.FileName() == "" {
+			value, err := io.ReadAll(part)
+			part.Close()
+			if err != nil {
+				return nil, err
+			}
+			form.Values.Add(name, string(value))
+			continue
+		}
+
+		file, err := readFile(part)
+		part.Close()
+		if err != nil {
+			return nil, err
+		}
+		form.Files[name] = append(form.Files[name], file)
+	}
+}
+
+// readFile reads the content of a file part, computing its size and checksums.
+func readFile(part *multipart.Part) (File, error) {
+	sha := sha256.New()
+	sum := md5.New()
+
+	size, err := io.Copy(io.MultiWriter(sha, sum), part)
+	if err != nil {
+		return File{}, err
+	}
+
+	return File{
+		Name:        part.FileName(),
+		ContentType: part.Header.Get("Content-Type"),
+		Size:        size,
+		SHA256:      hex.EncodeToString(sha.Sum(nil)),
+		MD5:         hex.EncodeToString(sum.Sum(nil)),
+	}, nil
+}
+
+// Tree returns the form as a map compared with the MustBody of a template.
+// A field has its last value, like a form-urlencoded body. A file is the map returned
+// by File.Fields, and the files of a field sent more than once are a list.
+func (inst *Form) Tree() map[string]any {
+	tree := make(map[string]any, len(inst.Values)+len(inst.Files))
+	for name, values := range inst.Values {
+		tree[name] = values[len(values)-1]
+	}
+
+	for name, files := range inst.Files {
+		if len(files) == 1 {
+			tree[name] = files[0].Fields()
+			continue
+		}
+
+		list := make([]any, 0, len(files))
+		for _, file := range files {
+			list = append(list, file.Fields())
+		}
+		tree[name] = list
+	}
+	return tree
+}
+
+// File returns the first file uploaded with the field name, and false if there is none.
+func (inst *Form) File(name string) (File, bool) {
+	files := inst.Files[name]
+	if len(files) == 0 {
+		return File{}, false
+	}
+	return files[0], true
+}
@@ -0,0 +1,77 @@
+package formdata
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRequest(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	require.NoError(t, writer.WriteField("title", "first"))
+	require.NoError(t, writer.WriteField("title", "second"))
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="avatar"; filename="dir/me.png"`)
+	header.Set("Content-Type", "image/png")
+	part, err := writer.CreatePart(header)
+	require.NoError(t, err)
+	_, err = part.Write([]byte("abc"))
+	require.NoError(t, err)
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		part, err := writer.CreateFormFile("docs", name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(name))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest("POST", "/upload", bytes.NewReader(body.Bytes()))
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	assert.True(t, IsFormData(req.Header.Get("Content-Type")))
+
+	form, err := ParseRequest(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"first", "second"}, form.Values["title"])
+
+	avatar, ok := form.File("avatar")
+	require.True(t, ok)
+	assert.Equal(t, File{
+		Name:        "me.png",
+		ContentType: "image/png",
+		Size:        3,
+		SHA256:      "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		MD5:         "900150983cd24fb0d6963f7d28e17f72",
+	}, avatar)
+
+	_, ok = form.File("missing")
+	assert.False(t, ok)
+
+	tree := form.Tree()
+	assert.Equal(t, "second", tree["title"])
+	assert.Equal(t, int64(3), tree["avatar"].(map[string]any)["size"])
+	assert.Len(t, tree["docs"], 2)
+
+	restored, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body.Bytes(), restored)
+}
+
+func TestParseRequest_Error(t *testing.T) {
+	for _, contentType := range []string{"application/json", "multipart/form-data", "multipart/form-data; boundary=x\"y"} {
+		req := httptest.NewRequest("POST", "/upload", bytes.NewReader([]byte("not multipart")))
+		req.Header.Set("Content-Type", contentType)
+
+		_, err := ParseRequest(req)
+		assert.Error(t, err, contentType)
+	}
+}
@@ -1,6 +1,9 @@
 package service
 
-import "mockium/internal/model"
+import (
+	"mockium/internal/model"
+	"net/http"
+)
 
 type Comparer interface {
 	Compare(expected, actual any) bool
@@ -9,3 +12,52 @@ type Comparer interface {
 type ProcessLogger interface {
 	Log(logReq *model.ProcessLoggingFileds)
 }
+
+type TemplateValidator interface {
+	Validate(templates []model.Template) error
+}
+
+type TemplateStore interface {
+	List() []model.Template
+	Get(id string) (model.Template, bool)
+	Put(template model.Template) (model.Template, bool, error)
+	Delete(id string) (bool, error)
+	Reset() error
+}
+
+type RequestJournal interface {
+	Find(accept func(*model.ProcessLoggingFileds) bool) []*model.ProcessLoggingFileds
+	Clear()
+}
+
+type ScenarioStore interface {
+	Lock()
+	Unlock()
+	State(name string) string
+	SetState(name, state string)
+	Register(name string)
+	States() map[string]string
+	Update(name, state string)
+	Reset(name string) bool
+	ResetAll()
+}
+
+type ResponseSequence interface {
+	Reset()
+	Continue(previous ResponseSequence)
+}
+
+type SequenceStore interface {
+	Register(template string, sequences map[int]ResponseSequence)
+	Retain(templates []string)
+	Reset(template string) bool
+	ResetAll()
+}
+
+type ExchangeRecorder interface {
+	Record(exchange *model.Exchange) error
+}
+
+type RequestValidator interface {
+	Validate(req *http.Request) []model.Violation
+}
@@ -0,0 +1,216 @@
+// Package jsonpath evaluates JSONPath expressions on decoded JSON documents.
+//
+// The supported subset covers the root "$", child members ".name" and "['name']",
+// array indices "[0]" and "[-1]", wildcards ".*" and "[*]", and recursive descent "..name".
+package jsonpath
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// segmentKind is the kind of a path step.
+type segmentKind int
+
+const (
+	segmentChild    segmentKind = iota // Member of an object by its name.
+	segmentIndex                       // Element of an array by its index, negative from the end.
+	segmentWildcard                    // Every member of an object or element of an array.
+)
+
+// segment is a single step of a path.
+type segment struct {
+	kind      segmentKind
+	name      string
+	index     int
+	recursive bool // The step applies to the current values and all their descendants.
+}
+
+// Path is a compiled JSONPath expression.
+type Path struct {
+	expr     string
+	segments []segment
+}
+
+// Compile parses a JSONPath expression.
+//
+// Parameters:
+//   - expr: the expression, e.g. "$.items[*].sku" or "$..id".
+//
+// Returns the compiled path or an error if the expression is invalid or not supported.
+func Compile(expr string) (*Path, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("jsonpath '%s' must start with '$'", expr)
+	}
+
+	path := &Path{expr: expr}
+	for rest := expr[1:]; rest != ""; {
+		var s segment
+		var err error
+		switch {
+		case strings.HasPrefix(rest, "..["):
+			s, rest, err = parseBracket(rest[2:])
+			s.recursive = true
+		case strings.HasPrefix(rest, ".."):
+			s, rest, err = parseMember(rest[2:])
+			s.recursive = true
+		case strings.HasPrefix(rest, "."):
+			s, rest, err = parseMember(rest[1:])
+		case strings.HasPrefix(rest, "["):
+			s, rest, err = parseBracket(rest)
+		default:
+			err = fmt.Errorf("unexpected '%s'", rest)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("jsonpath '%s': %w", expr, err)
+		}
+
+		path.segments = append(path.segments, s)
+	}
+
+	return path, nil
+}
+
+// String returns the source expression.
+func (inst *Path) String() string {
+	return inst.expr
+}
+
+// Find evaluates the path on a document decoded the way encoding/json decodes into any.
+//
+// Parameters:
+//   - doc: the document.
+//
+// Returns the matched values in document order, objects members sorted by name; empty if nothing matches.
+func (inst *Path) Find(doc any) []any {
+	values := []any{doc}
+	for _, s := range inst.segments {
+		if s.recursive {
+			values = descendants(values)
+		}
+
+		var next []any
+		for _, value := range values {
+			next = append(next, s.apply(value)...)
+		}
+		values = next
+	}
+	return values
+}
+
+// apply returns the values the step selects from a single value.
+func (inst segment) apply(value any) []any {
+	switch inst.kind {
+	case segmentChild:
+		if object, ok := value.(map[string]any); ok {
+			if member, ok := object[inst.name]; ok {
+				return []any{member}
+			}
+		}
+	case segmentIndex:
+		if array, ok := value.([]any); ok {
+			index := inst.index
+			if index < 0 {
+				index += len(array)
+			}
+			if index >= 0 && index < len(array) {
+				return []any{array[index]}
+			}
+		}
+	case segmentWildcard:
+		return children(value)
+	}
+	return nil
+}
+
+// children returns the members of an object sorted by name, or the elements of an array.
+func children(value any) []any {
+	switch v := value.(type) {
+	case map[string]any:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		members := make([]any, 0, len(v))
+		for _, name := range names {
+			members = append(members, v[name])
+		}
+		return members
+	case []any:
+		return v
+	}
+	return nil
+}
+
+// descendants returns the values together with all their nested values.
+func descendants(values []any) []any {
+	var all []any
+	for _, value := range values {
+		all = append(all, value)
+		all = append(all, descendants(children(value))...)
+	}
+	return all
+}
+
+// parseMember parses a dot step: a member name or "*".
+//
+// Returns the step and the rest of the expression.
+func parseMember(expr string) (segment, string, error) {
+	end := strings.IndexAny(expr, ".[")
+	if end < 0 {
+		end = len(expr)
+	}
+
+	switch name := expr[:end]; name {
+	case "":
+		return segment{}, "", fmt.Errorf("missing member name")
+	case "*":
+		return segment{kind: segmentWildcard}, expr[end:], nil
+	default:
+		return segment{kind: segmentChild, name: name}, expr[end:], nil
+	}
+}
+
+// parseBracket parses a bracket step: "[*]", a quoted member name or an index.
+//
+// Returns the step and the rest of the expression.
+func parseBracket(expr string) (segment, string, error) {
+	end := closingBracket(expr)
+	if end < 0 {
+		return segment{}, "", fmt.Errorf("missing ']'")
+	}
+
+	content, rest := strings.TrimSpace(expr[1:end]), expr[end+1:]
+	switch {
+	case content == "*":
+		return segment{kind: segmentWildcard}, rest, nil
+	case len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0]:
+		return segment{kind: segmentChild, name: content[1 : len(content)-1]}, rest, nil
+	}
+
+	index, err := strconv.Atoi(content)
+	if err != nil {
+		return segment{}, "", fmt.Errorf("unsupported selector '[%s]'", content)
+	}
+	return segment{kind: segmentIndex, index: index}, rest, nil
+}
+
+// closingBracket returns the index of the bracket closing the one at the start of s, skipping quoted names.
+func closingBracket(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote != 0 && s[i] == quote:
+			quote = 0
+		case quote == 0 && (s[i] == '\'' || s[i] == '"'):
+			quote = s[i]
+		case quote == 0 && s[i] == ']':
+			return i
+		}
+	}
+	return -1
+}
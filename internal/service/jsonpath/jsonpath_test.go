@@ -0,0 +1,50 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPath_Find(t *testing.T) {
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"user": {"name": "Ann", "age": 30, "first.name": "A"},
+		"items": [{"sku": "ABC", "qty": 1}, {"sku": "XYZ", "qty": 2, "parts": [{"sku": "P1"}]}]
+	}`), &doc))
+
+	tests := []struct {
+		expr string
+		want []any
+	}{
+		{"$", []any{doc}},
+		{"$.user.age", []any{float64(30)}},
+		{"$['user']['first.name']", []any{"A"}},
+		{`$["user"].name`, []any{"Ann"}},
+		{"$.items[0].sku", []any{"ABC"}},
+		{"$.items[-1].qty", []any{float64(2)}},
+		{"$.items[5].sku", nil},
+		{"$.items[*].sku", []any{"ABC", "XYZ"}},
+		{"$.user.*", []any{float64(30), "A", "Ann"}},
+		{"$..sku", []any{"ABC", "XYZ", "P1"}},
+		{"$..[0].sku", []any{"ABC", "P1"}},
+		{"$.missing.name", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			path, err := Compile(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, path.Find(doc))
+		})
+	}
+}
+
+func TestCompile_Error(t *testing.T) {
+	for _, expr := range []string{"", "user.name", "$.", "$.items[", "$.items[?(@.qty > 1)]", "$x"} {
+		_, err := Compile(expr)
+		assert.Error(t, err, expr)
+	}
+}
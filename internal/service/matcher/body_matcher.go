@@ -1,11 +1,15 @@
 package matcher
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"io"
+	"mime"
 	"mockium/internal/service"
 	"mockium/internal/service/constants"
+	"mockium/internal/service/formdata"
+	"mockium/internal/service/xmltree"
 	"net/http"
 
 	"go.uber.org/zap"
@@ -13,12 +17,18 @@ import (
 
 // BodyMatcher is responsible for comparing HTTP request headers and body
 // against expected values provided at initialization.
-// It supports JSON and form-urlencoded content types.
+// It supports JSON, XML, form-urlencoded and multipart/form-data content types.
 type BodyMatcher struct {
 	log          *zap.Logger      // Logger for error and debug output.
 	comparer     service.Comparer // Interface for deep comparison of values.
 	matchHeaders map[string]any   // Expected HTTP headers to match.
-	matchBody    map[string]any   // Expected HTTP body content to match.
+	matchBody    any              // Expected HTTP body content to match, nil to skip the comparison.
+	paths        []*BodyPath      // Conditions on the values selected from the body by JSONPath.
+}
+
+// cachedBody is the parsed request body cached into the request's context.
+type cachedBody struct {
+	value any
 }
 
 // NewBodyMatcher creates and returns a new instance of BodyMatcher.
@@ -26,31 +36,30 @@ type BodyMatcher struct {
 //   - log: logger used for internal logging.
 //   - compare: implementation of the service.Comparer interface for matching bodies.
 //   - matchHeaders: a map of expected headers (e.g., "Content-Type").
-//   - matchBody: a map representing the expected structure/content of the request body.
-func NewBodyMatcher(log *zap.Logger, compare service.Comparer, matchHeaders, matchBody map[string]any) *BodyMatcher {
+//   - matchBody: the expected structure/content of the request body, usually a map; nil to only check the paths.
+//   - paths: conditions on the values selected from the body by JSONPath expressions.
+func NewBodyMatcher(log *zap.Logger, compare service.Comparer, matchHeaders map[string]any, matchBody any, paths []*BodyPath) *BodyMatcher {
 	return &BodyMatcher{
 		log:          log,
 		comparer:     compare,
 		matchHeaders: matchHeaders,
 		matchBody:    matchBody,
+		paths:        paths,
 	}
 }
 
 // Match checks whether the provided HTTP request satisfies the expected header and body criteria.
 // Returns true if the Content-Type is correct and the request body matches the expected structure.
+// The Content-Type is compared by its media type, so its parameters such as the multipart boundary may differ.
 func (inst *BodyMatcher) Match(req *http.Request) bool {
 	actualContentType := req.Header.Get("Content-Type")
 	expectedContentType, ok := inst.matchHeaders["Content-Type"]
 
-	if ok && expectedContentType != "" && actualContentType == "" {
-		return false
-	}
-
-	if str, isStr := expectedContentType.(string); isStr && str != "" {
-		if actualContentType == expectedContentType {
-			return inst.compare(actualContentType, req)
+	if ok && expectedContentType != "" {
+		if actualContentType == "" || !contentTypeMatches(inst.comparer, expectedContentType, actualContentType) {
+			return false
 		}
-		return false
+		return inst.compare(actualContentType, req)
 	}
 
 	if actualContentType != "" {
@@ -62,60 +71,111 @@ func (inst *BodyMatcher) Match(req *http.Request) bool {
 	return false
 }
 
-// compare attempts to extract and parse the request body based on the given Content-Type,
-// and compares the parsed body to the expected matchBody using the configured comparer.
-// The parsed body is cached into the request's context for reuse.
+// compare parses the request body based on the given Content-Type, and compares it to the
+// expected matchBody using the configured comparer and checks the body path conditions.
 func (inst *BodyMatcher) compare(headerVal string, req *http.Request) bool {
-	if cached, ok := req.Context().Value(ctxtBodyCacheKey{}).(map[string]any); cached != nil && ok {
-		return inst.comparer.Compare(inst.matchBody, cached)
+	body, ok := parseBody(inst.log, headerVal, req)
+	if !ok {
+		return false
 	}
 
-	cached := make(map[string]any)
-	switch headerVal {
-	case constants.ContentTypeFormURLEncoded:
+	if inst.matchBody != nil && !inst.comparer.Compare(inst.matchBody, body) {
+		return false
+	}
+
+	for _, path := range inst.paths {
+		if !path.Match(body) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseBody extracts and parses the request body based on the given Content-Type.
+// The parsed body is cached into the request's context for reuse, so that every
+// body matcher of a handle sees the same body and parses it once.
+//
+// Returns the parsed body, and false if it cannot be parsed.
+func parseBody(log *zap.Logger, headerVal string, req *http.Request) (any, bool) {
+	if cached, ok := req.Context().Value(ctxtBodyCacheKey{}).(cachedBody); ok {
+		return cached.value, true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(headerVal)
+	if err != nil {
+		mediaType = headerVal
+	}
+
+	var parsed any
+	switch {
+	case mediaType == constants.ContentTypeFormURLEncoded:
 		if req.PostForm == nil {
 			if err := req.ParseForm(); err != nil {
-				inst.log.Error("parse form", zap.Error(err))
-				return false
+				log.Error("parse form", zap.Error(err))
+				return nil, false
 			}
 		}
 
+		form := make(map[string]any)
 		for key, values := range req.PostForm {
 			for _, value := range values {
-				cached[key] = value
+				form[key] = value
 			}
 		}
+		parsed = form
 
-	case constants.ContentTypeApplicationJSON:
-		if cached, ok := req.Context().Value(ctxtBodyCacheKey{}).([]byte); ok {
-			mBody := make(map[string]any)
-			if err := json.Unmarshal(cached, &mBody); err != nil {
-				inst.log.Error("parse body", zap.Error(err), zap.String("url", req.URL.Path))
-				return false
-			}
+	case mediaType == constants.ContentTypeFormData:
+		form, err := formdata.ParseRequest(req)
+		if err != nil {
+			log.Error("parse multipart form", zap.Error(err), zap.String("url", req.URL.Path))
+			return nil, false
+		}
+		parsed = form.Tree()
 
-			return inst.comparer.Compare(inst.matchBody, mBody)
+	case mediaType == constants.ContentTypeApplicationJSON:
+		body, ok := readBody(log, req)
+		if !ok {
+			return nil, false
 		}
 
-		body, err := io.ReadAll(req.Body)
-		if err != nil {
-			inst.log.Warn("failed to read body", zap.String("error", err.Error()))
-			return false
+		if err := json.Unmarshal(body, &parsed); err != nil {
+			log.Error("parse body", zap.Error(err), zap.String("url", req.URL.Path))
+			return nil, false
 		}
-		defer req.Body.Close()
 
-		if err := json.Unmarshal(body, &cached); err != nil {
-			inst.log.Error("parse body", zap.Error(err), zap.String("url", req.URL.Path))
-			return false
+	case xmltree.IsXML(headerVal):
+		body, ok := readBody(log, req)
+		if !ok {
+			return nil, false
 		}
 
+		tree, err := xmltree.Parse(bytes.NewReader(body))
+		if err != nil {
+			log.Error("parse body", zap.Error(err), zap.String("url", req.URL.Path))
+			return nil, false
+		}
+		parsed = tree
+
 	default:
-		inst.log.Warn("can't parse body with unexpected Content-Type header", zap.String("header", headerVal))
-		return false
+		log.Warn("can't parse body with unexpected Content-Type header", zap.String("header", headerVal))
+		return nil, false
 	}
 
-	ctx := context.WithValue(req.Context(), ctxtBodyCacheKey{}, cached)
+	ctx := context.WithValue(req.Context(), ctxtBodyCacheKey{}, cachedBody{value: parsed})
 	*req = *req.WithContext(ctx)
 
-	return inst.comparer.Compare(inst.matchBody, cached)
+	return parsed, true
+}
+
+// readBody reads the request body and restores it for the response builder and other matchers.
+func readBody(log *zap.Logger, req *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		log.Warn("failed to read body", zap.String("error", err.Error()))
+		return nil, false
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, true
 }
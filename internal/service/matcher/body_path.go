@@ -0,0 +1,153 @@
+package matcher
+
+import (
+	"fmt"
+	"mockium/internal/model"
+	"mockium/internal/service"
+	"mockium/internal/service/jsonpath"
+	"mockium/internal/service/xmltree"
+	"regexp"
+	"strings"
+)
+
+// valueFinder selects values from a parsed request body.
+type valueFinder interface {
+	Find(doc any) []any
+}
+
+// BodyPath checks a condition on the values a JSONPath or XPath expression selects from the request body.
+type BodyPath struct {
+	comparer service.Comparer // Comparer of the selected values with the expected one.
+	path     valueFinder      // Compiled JSONPath or XPath expression.
+	op       string           // Condition operator, one of the model.BodyPath* constants.
+	value    any              // Expected value, precompiled for the equals operator.
+	number   float64          // Expected number of the gt and lt operators.
+	re       *regexp.Regexp   // Compiled regular expression of the matches operator.
+}
+
+// NewBodyPath compiles a body path condition.
+//
+// Parameters:
+//   - compare: implementation of the service.Comparer interface for the equals, contains and in operators.
+//   - template: the condition; for the equals operator its value may contain precompiled placeholders.
+//
+// Expressions starting with "/" are XPath expressions for XML bodies, the others JSONPath expressions.
+//
+// Returns the condition, or an error if the expression, the operator or its value is invalid.
+func NewBodyPath(compare service.Comparer, template model.BodyPathTemplate) (*BodyPath, error) {
+	var path valueFinder
+	var err error
+	if strings.HasPrefix(template.Path, "/") {
+		path, err = xmltree.CompileXPath(template.Path)
+	} else {
+		path, err = jsonpath.Compile(template.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bodyPath := &BodyPath{comparer: compare, path: path, op: template.Op, value: template.Value}
+	switch template.Op {
+	case model.BodyPathExists, model.BodyPathAbsent:
+	case model.BodyPathEquals, model.BodyPathContains:
+		if template.Value == nil {
+			return nil, fmt.Errorf("body path '%s': operator '%s' requires 'Value'", template.Path, template.Op)
+		}
+	case model.BodyPathMatches:
+		pattern, ok := template.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("body path '%s': operator '%s' requires a regular expression", template.Path, template.Op)
+		}
+		if bodyPath.re, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("body path '%s': %w", template.Path, err)
+		}
+	case model.BodyPathGt, model.BodyPathLt:
+		number, ok := toFloat(template.Value)
+		if !ok {
+			return nil, fmt.Errorf("body path '%s': operator '%s' requires a number", template.Path, template.Op)
+		}
+		bodyPath.number = number
+	case model.BodyPathIn:
+		if _, ok := template.Value.([]any); !ok {
+			return nil, fmt.Errorf("body path '%s': operator '%s' requires a list", template.Path, template.Op)
+		}
+	default:
+		return nil, fmt.Errorf("body path '%s': unexpected operator '%s'", template.Path, template.Op)
+	}
+
+	return bodyPath, nil
+}
+
+// Match checks the condition on the body, it holds when any selected value satisfies it.
+//
+// Parameters:
+//   - body: the request body decoded from JSON or XML, or the form fields.
+func (inst *BodyPath) Match(body any) bool {
+	values := inst.path.Find(body)
+	switch inst.op {
+	case model.BodyPathExists:
+		return len(values) > 0
+	case model.BodyPathAbsent:
+		return len(values) == 0
+	}
+
+	for _, value := range values {
+		if inst.matchValue(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchValue checks the condition on a single selected value.
+func (inst *BodyPath) matchValue(value any) bool {
+	switch inst.op {
+	case model.BodyPathEquals:
+		return inst.comparer.Compare(inst.value, value)
+	case model.BodyPathContains:
+		switch v := value.(type) {
+		case string:
+			substr, ok := inst.value.(string)
+			return ok && strings.Contains(v, substr)
+		case []any:
+			for _, item := range v {
+				if inst.comparer.Compare(inst.value, item) {
+					return true
+				}
+			}
+		}
+	case model.BodyPathMatches:
+		switch value.(type) {
+		case string, float64, bool:
+			return inst.re.MatchString(fmt.Sprint(value))
+		}
+	case model.BodyPathGt:
+		number, ok := toFloat(value)
+		return ok && number > inst.number
+	case model.BodyPathLt:
+		number, ok := toFloat(value)
+		return ok && number < inst.number
+	case model.BodyPathIn:
+		for _, item := range inst.value.([]any) {
+			if inst.comparer.Compare(item, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// toFloat converts a number decoded from JSON or YAML to float64.
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,59 @@
+package matcher
+
+import (
+	"mockium/internal/service/schema"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// BodySchemaMatcher matches requests whose body validates against a JSON Schema.
+type BodySchemaMatcher struct {
+	log    *zap.Logger    // Logger for error and debug output.
+	schema *schema.Schema // Schema the body must satisfy, nil if it could not be loaded.
+	refs   schema.Refs    // Schemas referenced by the schema.
+}
+
+// NewBodySchemaMatcher creates and returns a new instance of BodySchemaMatcher.
+// Parameters:
+//   - log: logger used for internal logging.
+//   - source: the schema object, or the path of a JSON or YAML schema file.
+//
+// A schema that cannot be loaded is logged, and the matcher then matches no request.
+func NewBodySchemaMatcher(log *zap.Logger, source any) *BodySchemaMatcher {
+	s, refs, err := schema.Load(source)
+	if err != nil {
+		log.Error("load body schema", zap.Error(err))
+	}
+
+	return &BodySchemaMatcher{
+		log:    log,
+		schema: s,
+		refs:   refs,
+	}
+}
+
+// Match checks whether the request body satisfies the schema. The body is parsed by
+// its Content-Type and shared with the other body matchers of the handle.
+func (inst *BodySchemaMatcher) Match(req *http.Request) bool {
+	if inst.schema == nil {
+		return false
+	}
+
+	contentType := req.Header.Get("Content-Type")
+	if contentType == "" {
+		inst.log.Warn("can't parse body with empty Content-Type header")
+		return false
+	}
+
+	body, ok := parseBody(inst.log, contentType, req)
+	if !ok {
+		return false
+	}
+
+	if violations := schema.Validate(inst.schema, inst.refs, body); len(violations) > 0 {
+		inst.log.Debug("body does not match schema", zap.Stringers("violations", violations))
+		return false
+	}
+	return true
+}
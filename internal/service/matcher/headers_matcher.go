@@ -1,8 +1,12 @@
 package matcher
 
 import (
+	"mime"
 	"mockium/internal/service"
+	"mockium/internal/service/comparer"
+	"mockium/internal/service/constants"
 	"net/http"
+	"strings"
 )
 
 // HeadersMatcher is responsible for validating whether an HTTP request's headers
@@ -27,13 +31,77 @@ func NewHeadersMatcher(matchHeaders map[string]any, comparer service.Comparer) *
 // Match evaluates whether all expected headers are present in the provided HTTP request,
 // and whether their values match according to the configured comparer.
 //
+// A header expected to be ${absent} must be missing. The SOAPAction header is compared
+// without its quotes, and is taken from the "action" parameter of the SOAP 1.2 Content-Type.
+// The Content-Type header is compared by its media type, see contentTypeMatches.
+//
 // Returns true if all expected headers are found and match; otherwise, returns false.
 func (inst *HeadersMatcher) Match(req *http.Request) bool {
 	for key, tValue := range inst.matchHeaders {
 		actual := req.Header.Get(key)
-		if actual == "" || !inst.comparer.Compare(tValue, actual) {
+		if http.CanonicalHeaderKey(key) == http.CanonicalHeaderKey(constants.HeaderSOAPAction) {
+			actual = soapAction(req)
+		}
+
+		if actual == "" {
+			if !comparer.IsAbsent(tValue) {
+				return false
+			}
+			continue
+		}
+
+		if http.CanonicalHeaderKey(key) == "Content-Type" {
+			if !contentTypeMatches(inst.comparer, tValue, actual) {
+				return false
+			}
+			continue
+		}
+
+		if !inst.comparer.Compare(tValue, actual) {
+			return false
+		}
+	}
+	return true
+}
+
+// contentTypeMatches compares the Content-Type of a request with the expected one.
+// A literal Content-Type matches the same media type, whatever the case, with at least
+// the expected parameters, so "multipart/form-data" matches "multipart/form-data; boundary=x".
+// Placeholders are compared with the whole header value.
+func contentTypeMatches(compare service.Comparer, expected any, actual string) bool {
+	text, ok := expected.(string)
+	if !ok || text == constants.AnyValuePlaceholder {
+		return compare.Compare(expected, actual)
+	}
+
+	expectedType, expectedParams, err := mime.ParseMediaType(text)
+	if err != nil {
+		return text == actual
+	}
+
+	actualType, actualParams, err := mime.ParseMediaType(actual)
+	if err != nil || expectedType != actualType {
+		return false
+	}
+
+	for name, value := range expectedParams {
+		if !strings.EqualFold(actualParams[name], value) {
 			return false
 		}
 	}
 	return true
 }
+
+// soapAction returns the SOAP action of the request: the unquoted SOAPAction header of SOAP 1.1,
+// or the "action" parameter of the SOAP 1.2 Content-Type.
+func soapAction(req *http.Request) string {
+	if action := strings.Trim(req.Header.Get(constants.HeaderSOAPAction), `"`); action != "" {
+		return action
+	}
+
+	_, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return params["action"]
+}
@@ -0,0 +1,61 @@
+package matcher
+
+import (
+	"encoding/json"
+	"mockium/internal/service"
+	"mockium/internal/service/comparer"
+
+	"go.uber.org/zap"
+)
+
+// MessageMatcher compares incoming WebSocket messages with the MustMessage of a reply.
+type MessageMatcher struct {
+	comparer service.Comparer // Interface for deep comparison of values.
+	pattern  any              // Expected message with compiled placeholders, nil to match any message.
+	text     bool             // The pattern is a string compared with the text of the message.
+}
+
+// NewMessageMatcher creates a new MessageMatcher.
+// A string pattern is compared with the text of the message, other patterns with the
+// message decoded as JSON. Placeholders, array modes and strict objects are compiled
+// like in MustBody.
+//
+// Parameters:
+//   - log: a structured logger used for diagnostics.
+//   - pattern: the expected message, nil to match any message.
+//
+// Returns a pointer to a configured MessageMatcher instance.
+func NewMessageMatcher(log *zap.Logger, pattern any) *MessageMatcher {
+	_, text := pattern.(string)
+
+	var compiled any
+	if pattern != nil {
+		compiled = (&RequestMatcher{log: log}).precompileValue(pattern)
+	}
+
+	return &MessageMatcher{
+		comparer: comparer.New(),
+		pattern:  compiled,
+		text:     text,
+	}
+}
+
+// Match checks whether the message matches the pattern.
+//
+// Returns true if there is no pattern or the message matches it; false if it does not,
+// or if a JSON pattern is compared with a message that is not valid JSON.
+func (inst *MessageMatcher) Match(message []byte) bool {
+	if inst.pattern == nil {
+		return true
+	}
+
+	if inst.text {
+		return inst.comparer.Compare(inst.pattern, string(message))
+	}
+
+	var decoded any
+	if err := json.Unmarshal(message, &decoded); err != nil {
+		return false
+	}
+	return inst.comparer.Compare(inst.pattern, decoded)
+}
@@ -2,7 +2,10 @@ package matcher
 
 import (
 	"mockium/internal/service"
+	"mockium/internal/service/comparer"
 	"net/http"
+
+	"github.com/gorilla/mux"
 )
 
 // PathMatcher is responsible for checking whether path parameters from an HTTP request
@@ -27,13 +30,31 @@ func NewPathMatcher(matchPath map[string]any, comparer service.Comparer) *PathMa
 // Match checks whether all expected path parameters exist in the request and match the
 // expected values using the configured comparer.
 //
+// A parameter expected to be ${absent} must be missing.
+//
 // Returns true if all path values match; otherwise, returns false.
 func (inst *PathMatcher) Match(req *http.Request) bool {
 	for key, tValue := range inst.matchPath {
-		actual := req.PathValue(key)
-		if actual == "" || !inst.comparer.Compare(tValue, actual) {
+		actual := pathValue(req, key)
+		if actual == "" {
+			if !comparer.IsAbsent(tValue) {
+				return false
+			}
+			continue
+		}
+
+		if !inst.comparer.Compare(tValue, actual) {
 			return false
 		}
 	}
 	return true
 }
+
+// pathValue returns the path parameter set by the gorilla/mux router,
+// or by http.Request.SetPathValue, e.g. for a request restored from the journal.
+func pathValue(req *http.Request, key string) string {
+	if value, ok := mux.Vars(req)[key]; ok {
+		return value
+	}
+	return req.PathValue(key)
+}
@@ -2,6 +2,7 @@ package matcher
 
 import (
 	"mockium/internal/service"
+	"mockium/internal/service/comparer"
 	"net/http"
 )
 
@@ -27,11 +28,20 @@ func NewQueryMatcher(matchQuery map[string]any, comparer service.Comparer) *Quer
 // Match determines whether all expected query parameters are present in the HTTP request
 // and whether their values match the expected values using the configured comparer.
 //
+// A parameter expected to be ${absent} must be missing.
+//
 // Returns true if all query parameters match; otherwise, returns false.
 func (inst *QueryMatcher) Match(req *http.Request) bool {
 	for key, tValue := range inst.matchQuery {
 		actual := req.URL.Query().Get(key)
-		if actual == "" || !inst.comparer.Compare(tValue, actual) {
+		if actual == "" {
+			if !comparer.IsAbsent(tValue) {
+				return false
+			}
+			continue
+		}
+
+		if !inst.comparer.Compare(tValue, actual) {
 			return false
 		}
 	}
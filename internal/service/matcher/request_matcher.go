@@ -2,11 +2,10 @@ package matcher
 
 import (
 	"mockium/internal/model"
+	"mockium/internal/service"
 	"mockium/internal/service/comparer"
-	"mockium/internal/service/constants"
 	"mockium/internal/transport"
 	"net/http"
-	"regexp"
 
 	"go.uber.org/zap"
 )
@@ -48,8 +47,18 @@ func NewRequestMatcher(log *zap.Logger, templateRequest *model.MatchRequestTempl
 		parameterMatchers = append(parameterMatchers, NewHeadersMatcher(matchHeaders, comparer))
 	}
 
-	if len(templateRequest.MustBody) > 0 {
-		parameterMatchers = append(parameterMatchers, NewBodyMatcher(log, comparer, matchHeaders, requestMatcher.precompileRegexp(templateRequest.MustBody)))
+	if len(templateRequest.MustBody) > 0 || len(templateRequest.MustBodyPaths) > 0 {
+		var matchBody any
+		if len(templateRequest.MustBody) > 0 {
+			matchBody = requestMatcher.precompileValue(templateRequest.MustBody)
+		}
+
+		parameterMatchers = append(parameterMatchers, NewBodyMatcher(log, comparer, matchHeaders, matchBody,
+			requestMatcher.compileBodyPaths(comparer, templateRequest.MustBodyPaths)))
+	}
+
+	if templateRequest.MustBodySchema != nil {
+		parameterMatchers = append(parameterMatchers, NewBodySchemaMatcher(log, templateRequest.MustBodySchema))
 	}
 
 	if len(templateRequest.MustQueryParameters) > 0 {
@@ -79,10 +88,10 @@ func (inst *RequestMatcher) Match(req *http.Request) bool {
 }
 
 // precompileRegexp recursively processes a map of values that may include
-// regular expression placeholders. If a placeholder is detected, it attempts
-// to compile it into a *regexp.Regexp object.
+// placeholders such as ${regexp:...} or ${range:1..100}. If a placeholder is detected,
+// it is compiled into a *regexp.Regexp object or a comparer.Predicate.
 //
-// Returns a new map with compiled regexes where applicable.
+// Returns a new map with compiled placeholders where applicable.
 // If compilation fails, the original string is retained and a warning is logged.
 func (inst *RequestMatcher) precompileRegexp(source map[string]any) map[string]any {
 	if len(source) == 0 {
@@ -91,27 +100,57 @@ func (inst *RequestMatcher) precompileRegexp(source map[string]any) map[string]a
 
 	result := make(map[string]any, len(source))
 	for key, value := range source {
-		switch v := value.(type) {
-		case string:
-			if constants.RegexpRequestValuePlaceholder.MatchString(v) {
-				placeholders := constants.RegexpRequestValuePlaceholder.FindStringSubmatch(v)
-				if placeholders[1] == constants.RegexpValuePlaceholder {
-					if re, err := regexp.Compile(placeholders[2]); err == nil {
-						result[key] = re
-						continue
-					} else {
-						inst.log.Warn("failed to compile regexp", zap.String("regexp", v))
-						result[key] = v
-						continue
-					}
-				}
-			}
-			result[key] = v
-		case map[string]any:
-			result[key] = inst.precompileRegexp(v)
-		default:
-			result[key] = value
-		}
+		result[key] = inst.precompileValue(value)
 	}
 	return result
 }
+
+// precompileValue compiles a placeholder, and the placeholders nested in maps and arrays.
+// Maps that are array or strict object wrappers are compiled too. Other values are returned as is.
+func (inst *RequestMatcher) precompileValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		compiled, err := comparer.Compile(v)
+		if err != nil {
+			inst.log.Warn("failed to compile placeholder", zap.String("placeholder", v), zap.Error(err))
+			return v
+		}
+		return compiled
+	case map[string]any:
+		compiled := inst.precompileRegexp(v)
+		object, err := comparer.CompileObject(compiled)
+		if err != nil {
+			inst.log.Warn("failed to compile object", zap.Error(err))
+			return compiled
+		}
+		return object
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = inst.precompileValue(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// compileBodyPaths compiles the body path conditions, precompiling the placeholders of their values.
+// A condition that fails to compile is logged and never holds, so the request does not match.
+func (inst *RequestMatcher) compileBodyPaths(compare service.Comparer, templates []model.BodyPathTemplate) []*BodyPath {
+	paths := make([]*BodyPath, 0, len(templates))
+	for _, template := range templates {
+		if template.Op == model.BodyPathEquals {
+			template.Value = inst.precompileValue(template.Value)
+		}
+
+		path, err := NewBodyPath(compare, template)
+		if err != nil {
+			inst.log.Error("compile body path", zap.Error(err))
+			template = model.BodyPathTemplate{Path: "$", Op: model.BodyPathAbsent}
+			path, _ = NewBodyPath(compare, template)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
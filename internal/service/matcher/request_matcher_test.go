@@ -1,17 +1,26 @@
 package matcher
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"io"
+	"mime/multipart"
 	"mockium/internal/model"
+	"mockium/internal/service/comparer"
 	"mockium/internal/service/constants"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -206,6 +215,29 @@ func TestRequestMatcher_HeaderMatching(t *testing.T) {
 	assert.True(t, matcher.Match(req))
 }
 
+func TestContentTypeMatches(t *testing.T) {
+	compare := comparer.New()
+
+	tests := []struct {
+		expected any
+		actual   string
+		want     bool
+	}{
+		{"application/json", "application/json", true},
+		{"application/json", "Application/JSON; charset=utf-8", true},
+		{"application/json; charset=utf-8", "application/json; charset=UTF-8", true},
+		{"application/json; charset=utf-8", "application/json", false},
+		{"multipart/form-data", "multipart/form-data; boundary=abc", true},
+		{"text/xml", "application/xml", false},
+		{"${...}", "text/plain", true},
+		{regexp.MustCompile(`^text/`), "text/plain; charset=utf-8", true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, contentTypeMatches(compare, tt.expected, tt.actual), "%v %s", tt.expected, tt.actual)
+	}
+}
+
 func BenchmarkRequestMatcher_Match(b *testing.B) {
 	logger := zaptest.NewLogger(b)
 	template := &model.MatchRequestTemplate{
@@ -226,3 +258,382 @@ func BenchmarkRequestMatcher_Match(b *testing.B) {
 		matcher.Match(req)
 	}
 }
+
+func TestRequestMatcher_BodySchema(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	inline := map[string]any{
+		"type":     "object",
+		"required": []any{"sku", "quantity"},
+		"properties": map[string]any{
+			"sku":      map[string]any{"type": "string", "pattern": "^[A-Z]{3}$"},
+			"quantity": map[string]any{"type": "integer", "minimum": 1},
+		},
+		"additionalProperties": false,
+	}
+
+	file := filepath.Join(t.TempDir(), "order.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+type: object
+required: [sku]
+properties:
+  sku:
+    $ref: '#/$defs/sku'
+$defs:
+  sku:
+    type: string
+    enum: [ABC]
+`), 0o644))
+
+	tests := []struct {
+		name   string
+		schema any
+		body   string
+		want   bool
+	}{
+		{"inline valid", inline, `{"sku": "ABC", "quantity": 2}`, true},
+		{"inline invalid value", inline, `{"sku": "ABC", "quantity": 0}`, false},
+		{"inline extra property", inline, `{"sku": "ABC", "quantity": 1, "note": ""}`, false},
+		{"not JSON", inline, `sku=ABC`, false},
+		{"file valid", file, `{"sku": "ABC"}`, true},
+		{"file invalid", file, `{"sku": "XYZ"}`, false},
+		{"missing file", filepath.Join(t.TempDir(), "missing.json"), `{}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := NewRequestMatcher(logger, &model.MatchRequestTemplate{MustBodySchema: tt.schema})
+
+			req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", constants.ContentTypeApplicationJSON)
+			assert.Equal(t, tt.want, matcher.Match(req))
+
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.body, string(body))
+		})
+	}
+}
+
+func TestRequestMatcher_BodySchemaContentType(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	template := &model.MatchRequestTemplate{
+		MustBody: map[string]any{"sku": "ABC"},
+		MustBodySchema: map[string]any{
+			"type":     "object",
+			"required": []any{"sku"},
+			"properties": map[string]any{
+				"sku": map[string]any{"type": "string"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        bool
+	}{
+		{"json", constants.ContentTypeApplicationJSON, `{"sku": "ABC"}`, true},
+		{"json with charset", "application/json; charset=utf-8", `{"sku": "ABC"}`, true},
+		{"form", constants.ContentTypeFormURLEncoded, `sku=ABC`, true},
+		{"no content type", "", `{"sku": "ABC"}`, false},
+		{"unknown content type", "text/plain", `{"sku": "ABC"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := NewRequestMatcher(logger, template)
+
+			req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			assert.Equal(t, tt.want, matcher.Match(req))
+		})
+	}
+
+	t.Run("shares the parsed body", func(t *testing.T) {
+		matcher := NewBodySchemaMatcher(logger, template.MustBodySchema)
+
+		// The body parsed by another matcher of the handle is validated, not parsed again.
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"sku": 1}`))
+		req.Header.Set("Content-Type", constants.ContentTypeApplicationJSON)
+		req = req.WithContext(context.WithValue(req.Context(), ctxtBodyCacheKey{}, cachedBody{value: map[string]any{"sku": "ABC"}}))
+		assert.True(t, matcher.Match(req))
+	})
+}
+
+func TestRequestMatcher_BodyPaths(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	body := `{"user": {"name": "Ann", "age": 30, "role": "admin"}, "items": [{"sku": "ABC-1"}, {"sku": "XYZ-2"}], "tags": ["a", "b"]}`
+
+	tests := []struct {
+		name  string
+		paths []model.BodyPathTemplate
+		body  string
+		want  bool
+	}{
+		{"equals", []model.BodyPathTemplate{{Path: "$.user.name", Op: model.BodyPathEquals, Value: "Ann"}}, body, true},
+		{"equals number", []model.BodyPathTemplate{{Path: "$.user.age", Op: model.BodyPathEquals, Value: 30}}, body, true},
+		{"equals regexp placeholder", []model.BodyPathTemplate{{Path: "$.items[*].sku", Op: model.BodyPathEquals, Value: "${regexp:^XYZ-}"}}, body, true},
+		{"equals mismatch", []model.BodyPathTemplate{{Path: "$.user.name", Op: model.BodyPathEquals, Value: "Bob"}}, body, false},
+		{"contains string", []model.BodyPathTemplate{{Path: "$.items[*].sku", Op: model.BodyPathContains, Value: "ABC"}}, body, true},
+		{"contains array", []model.BodyPathTemplate{{Path: "$.tags", Op: model.BodyPathContains, Value: "b"}}, body, true},
+		{"contains missing", []model.BodyPathTemplate{{Path: "$.tags", Op: model.BodyPathContains, Value: "c"}}, body, false},
+		{"matches", []model.BodyPathTemplate{{Path: "$.user.age", Op: model.BodyPathMatches, Value: "^3\\d$"}}, body, true},
+		{"exists", []model.BodyPathTemplate{{Path: "$..sku", Op: model.BodyPathExists}}, body, true},
+		{"absent", []model.BodyPathTemplate{{Path: "$.user.email", Op: model.BodyPathAbsent}}, body, true},
+		{"absent present", []model.BodyPathTemplate{{Path: "$.user.name", Op: model.BodyPathAbsent}}, body, false},
+		{"gt", []model.BodyPathTemplate{{Path: "$.user.age", Op: model.BodyPathGt, Value: 18}}, body, true},
+		{"lt", []model.BodyPathTemplate{{Path: "$.user.age", Op: model.BodyPathLt, Value: 18}}, body, false},
+		{"in", []model.BodyPathTemplate{{Path: "$.user.role", Op: model.BodyPathIn, Value: []any{"admin", "owner"}}}, body, true},
+		{"all conditions", []model.BodyPathTemplate{
+			{Path: "$.user.age", Op: model.BodyPathGt, Value: 18},
+			{Path: "$.user.role", Op: model.BodyPathEquals, Value: "guest"},
+		}, body, false},
+		{"invalid condition", []model.BodyPathTemplate{{Path: "$.user.age", Op: "between"}}, body, false},
+		{"array body", []model.BodyPathTemplate{{Path: "$[0].id", Op: model.BodyPathEquals, Value: 1}}, `[{"id": 1}]`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := NewRequestMatcher(logger, &model.MatchRequestTemplate{MustBodyPaths: tt.paths})
+
+			req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", constants.ContentTypeApplicationJSON)
+			assert.Equal(t, tt.want, matcher.Match(req))
+		})
+	}
+}
+
+func TestRequestMatcher_Placeholders(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	matcher := NewRequestMatcher(logger, &model.MatchRequestTemplate{
+		MustHeaders:         map[string]any{"Authorization": "${startsWith:Bearer }", "X-Debug": "${absent}"},
+		MustQueryParameters: map[string]any{"limit": "${range:1..100}", "cursor": "${absent}"},
+		MustPathParameters:  map[string]any{"id": "${uuid}"},
+	})
+
+	newRequest := func(target, id string, headers map[string]string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		return mux.SetURLVars(req, map[string]string{"id": id})
+	}
+
+	const id = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	auth := map[string]string{"Authorization": "Bearer token"}
+
+	assert.True(t, matcher.Match(newRequest("/users/"+id+"?limit=10", id, auth)))
+	assert.False(t, matcher.Match(newRequest("/users/1?limit=10", "1", auth)))
+	assert.False(t, matcher.Match(newRequest("/users/"+id+"?limit=500", id, auth)))
+	assert.False(t, matcher.Match(newRequest("/users/"+id+"?limit=10&cursor=abc", id, auth)))
+	assert.False(t, matcher.Match(newRequest("/users/"+id+"?limit=10", id, map[string]string{"Authorization": "Basic abc"})))
+	assert.False(t, matcher.Match(newRequest("/users/"+id+"?limit=10", id, map[string]string{"Authorization": "Bearer token", "X-Debug": "1"})))
+}
+
+func TestRequestMatcher_BodyWrappers(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	matcher := NewRequestMatcher(logger, &model.MatchRequestTemplate{
+		MustHeaders: map[string]any{"Content-Type": constants.ContentTypeApplicationJSON},
+		MustBody: map[string]any{
+			"$strict": true,
+			"tags":    map[string]any{"$array": "containsAll", "values": []any{"b", "a"}},
+			"items":   map[string]any{"$array": "every", "values": []any{map[string]any{"sku": "${regexp:^[A-Z]+$}"}}},
+		},
+	})
+
+	tests := []struct {
+		body string
+		want bool
+	}{
+		{`{"tags": ["a", "b", "c"], "items": [{"sku": "ABC"}, {"sku": "XYZ", "qty": 2}]}`, true},
+		{`{"tags": ["a", "c"], "items": []}`, false},
+		{`{"tags": ["a", "b"], "items": [{"sku": "abc"}]}`, false},
+		{`{"tags": ["a", "b"], "items": [], "note": "extra"}`, false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", constants.ContentTypeApplicationJSON)
+		assert.Equal(t, tt.want, matcher.Match(req), tt.body)
+	}
+}
+
+func TestRequestMatcher_SOAP(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	const envelope = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body><m:GetOrder xmlns:m="urn:orders"><m:id>42</m:id><m:item sku="ABC"/></m:GetOrder></soap:Body>
+</soap:Envelope>`
+
+	tests := []struct {
+		name     string
+		template *model.MatchRequestTemplate
+		headers  map[string]string
+		want     bool
+	}{
+		{
+			name: "body parameters",
+			template: &model.MatchRequestTemplate{MustBody: map[string]any{
+				"Envelope": map[string]any{"Body": map[string]any{"GetOrder": map[string]any{"@xmlns": "urn:orders", "id": "${range:1..100}"}}},
+			}},
+			headers: map[string]string{"Content-Type": "text/xml; charset=utf-8"},
+			want:    true,
+		},
+		{
+			name: "xpath",
+			template: &model.MatchRequestTemplate{MustBodyPaths: []model.BodyPathTemplate{
+				{Path: "/soap:Envelope/soap:Body/m:GetOrder/m:id", Op: model.BodyPathEquals, Value: "42"},
+				{Path: "//item/@sku", Op: model.BodyPathIn, Value: []any{"ABC", "XYZ"}},
+			}},
+			headers: map[string]string{"Content-Type": "text/xml"},
+			want:    true,
+		},
+		{
+			name: "xpath mismatch",
+			template: &model.MatchRequestTemplate{MustBodyPaths: []model.BodyPathTemplate{
+				{Path: "//id", Op: model.BodyPathEquals, Value: "43"},
+			}},
+			headers: map[string]string{"Content-Type": "application/xml"},
+			want:    false,
+		},
+		{
+			name:     "SOAPAction 1.1",
+			template: &model.MatchRequestTemplate{MustHeaders: map[string]any{"SOAPAction": "urn:GetOrder"}},
+			headers:  map[string]string{"Content-Type": "text/xml", "SOAPAction": `"urn:GetOrder"`},
+			want:     true,
+		},
+		{
+			name:     "SOAPAction 1.2",
+			template: &model.MatchRequestTemplate{MustHeaders: map[string]any{"SOAPAction": "urn:GetOrder"}},
+			headers:  map[string]string{"Content-Type": `application/soap+xml; charset=utf-8; action="urn:GetOrder"`},
+			want:     true,
+		},
+		{
+			name:     "SOAPAction mismatch",
+			template: &model.MatchRequestTemplate{MustHeaders: map[string]any{"SOAPAction": "urn:GetOrder"}},
+			headers:  map[string]string{"SOAPAction": `"urn:DeleteOrder"`},
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := NewRequestMatcher(logger, tt.template)
+
+			req := httptest.NewRequest(http.MethodPost, "/ws", strings.NewReader(envelope))
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			assert.Equal(t, tt.want, matcher.Match(req))
+		})
+	}
+}
+
+func TestRequestMatcher_Multipart(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	require.NoError(t, writer.WriteField("title", "holiday"))
+	part, err := writer.CreateFormFile("photo", "beach.png")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("abc"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	tests := []struct {
+		name     string
+		template *model.MatchRequestTemplate
+		want     bool
+	}{
+		{
+			name: "fields and file",
+			template: &model.MatchRequestTemplate{
+				MustHeaders: map[string]any{"Content-Type": constants.ContentTypeFormData},
+				MustBody: map[string]any{
+					"title": "holiday",
+					"photo": map[string]any{
+						"filename":    "${endsWith:.png}",
+						"contentType": constants.ContentTypeOctetStream,
+						"size":        "${range:1..1024}",
+						"sha256":      "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name:     "any file",
+			template: &model.MatchRequestTemplate{MustBody: map[string]any{"photo": constants.FileParamName}},
+			want:     true,
+		},
+		{
+			name:     "field is not a file",
+			template: &model.MatchRequestTemplate{MustBody: map[string]any{"title": constants.FileParamName}},
+			want:     false,
+		},
+		{
+			name:     "file too large",
+			template: &model.MatchRequestTemplate{MustBody: map[string]any{"photo": map[string]any{"size": "${gt:1024}"}}},
+			want:     false,
+		},
+		{
+			name: "body path",
+			template: &model.MatchRequestTemplate{MustBodyPaths: []model.BodyPathTemplate{
+				{Path: "$.photo.filename", Op: model.BodyPathMatches, Value: `^beach\.`},
+			}},
+			want: true,
+		},
+		{
+			name:     "other media type",
+			template: &model.MatchRequestTemplate{MustHeaders: map[string]any{"Content-Type": constants.ContentTypeApplicationJSON}, MustBody: map[string]any{"title": "holiday"}},
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := NewRequestMatcher(logger, tt.template)
+
+			req := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(body.Bytes()))
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			assert.Equal(t, tt.want, matcher.Match(req))
+		})
+	}
+}
+
+func TestMessageMatcher(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	tests := []struct {
+		name    string
+		pattern any
+		message string
+		want    bool
+	}{
+		{"any message", nil, "hello", true},
+		{"text", "ping", "ping", true},
+		{"text mismatch", "ping", "pong", false},
+		{"text placeholder", "${regexp:^sub:}", "sub:orders", true},
+		{"text of json", `{"type":"ping"}`, `{"type":"ping"}`, true},
+		{"json", map[string]any{"type": "subscribe"}, `{"type": "subscribe", "channel": "orders"}`, true},
+		{"json number", map[string]any{"id": 7}, `{"id": 7}`, true},
+		{"json placeholder", map[string]any{"id": "${range:1..10}"}, `{"id": 42}`, false},
+		{"json strict", map[string]any{"$strict": true, "type": "subscribe"}, `{"type": "subscribe", "channel": "orders"}`, false},
+		{"json array", []any{"a", "b"}, `["a", "b"]`, true},
+		{"not json", map[string]any{"type": "subscribe"}, "subscribe", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewMessageMatcher(logger, tt.pattern).Match([]byte(tt.message)))
+		})
+	}
+}
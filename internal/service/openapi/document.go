@@ -0,0 +1,224 @@
+// Package openapi reads OpenAPI 3 documents and converts them to mock templates.
+package openapi
+
+import (
+	"fmt"
+	"mockium/internal/service/schema"
+	"net/url"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is the part of an OpenAPI 3 document used by mockium.
+type Document struct {
+	OpenAPI    string               `yaml:"openapi"`
+	Servers    []Server             `yaml:"servers"`
+	Paths      map[string]*PathItem `yaml:"paths"`
+	Components Components           `yaml:"components"`
+
+	refs schema.Refs // Component schemas by their reference.
+}
+
+// Server is a server of the API, the path of its URL is the base path of the document paths.
+type Server struct {
+	URL       string                    `yaml:"url"`
+	Variables map[string]ServerVariable `yaml:"variables"`
+}
+
+// ServerVariable is a variable of a server URL template, e.g. "{basePath}".
+type ServerVariable struct {
+	Default string `yaml:"default"`
+}
+
+// Components holds the reusable objects of the document.
+type Components struct {
+	Schemas       map[string]*schema.Schema `yaml:"schemas"`
+	Parameters    map[string]*Parameter     `yaml:"parameters"`
+	Responses     map[string]*Response      `yaml:"responses"`
+	RequestBodies map[string]*RequestBody   `yaml:"requestBodies"`
+	Examples      map[string]*Example       `yaml:"examples"`
+	Headers       map[string]*Header        `yaml:"headers"`
+}
+
+// PathItem describes the operations of a path.
+type PathItem struct {
+	Parameters []*Parameter `yaml:"parameters"`
+	Get        *Operation   `yaml:"get"`
+	Post       *Operation   `yaml:"post"`
+	Put        *Operation   `yaml:"put"`
+	Patch      *Operation   `yaml:"patch"`
+	Delete     *Operation   `yaml:"delete"`
+}
+
+// Operation describes a single API operation on a path.
+type Operation struct {
+	OperationID string               `yaml:"operationId"`
+	Parameters  []*Parameter         `yaml:"parameters"`
+	RequestBody *RequestBody         `yaml:"requestBody"`
+	Responses   map[string]*Response `yaml:"responses"`
+}
+
+// Parameter describes a path, query, header or cookie parameter.
+type Parameter struct {
+	Ref      string         `yaml:"$ref"`
+	Name     string         `yaml:"name"`
+	In       string         `yaml:"in"`
+	Required bool           `yaml:"required"`
+	Schema   *schema.Schema `yaml:"schema"`
+}
+
+// RequestBody describes the body of a request.
+type RequestBody struct {
+	Ref      string                `yaml:"$ref"`
+	Required bool                  `yaml:"required"`
+	Content  map[string]*MediaType `yaml:"content"`
+}
+
+// Response describes a response of an operation.
+type Response struct {
+	Ref     string                `yaml:"$ref"`
+	Headers map[string]*Header    `yaml:"headers"`
+	Content map[string]*MediaType `yaml:"content"`
+}
+
+// Header describes a response header.
+type Header struct {
+	Ref     string         `yaml:"$ref"`
+	Schema  *schema.Schema `yaml:"schema"`
+	Example any            `yaml:"example"`
+}
+
+// MediaType describes the content of a body in one media type.
+type MediaType struct {
+	Schema   *schema.Schema      `yaml:"schema"`
+	Example  any                 `yaml:"example"`
+	Examples map[string]*Example `yaml:"examples"`
+}
+
+// Example is a named example of a media type.
+type Example struct {
+	Ref   string `yaml:"$ref"`
+	Value any    `yaml:"value"`
+}
+
+// Load reads an OpenAPI 3 document in YAML or JSON format.
+//
+// Parameters:
+//   - path: the document file.
+//
+// Returns the document or an error if it cannot be read or is not OpenAPI 3.
+func Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse decodes an OpenAPI 3 document in YAML or JSON format.
+func Parse(data []byte) (*Document, error) {
+	doc := &Document{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version '%s', expected 3.x", doc.OpenAPI)
+	}
+
+	doc.refs = make(schema.Refs, len(doc.Components.Schemas))
+	doc.refs.AddDefs("#/components/schemas/", doc.Components.Schemas)
+
+	return doc, nil
+}
+
+// Refs returns the component schemas by their reference.
+func (inst *Document) Refs() schema.Refs {
+	return inst.refs
+}
+
+// BasePaths returns the base paths of the document paths, taken from the server URLs
+// with their variables set to the default values, e.g. "/v1" for "https://api.example.com/v1".
+// A document without servers is served from the root, its only base path is empty.
+func (inst *Document) BasePaths() []string {
+	if len(inst.Servers) == 0 {
+		return []string{""}
+	}
+
+	paths := make([]string, 0, len(inst.Servers))
+	for _, server := range inst.Servers {
+		raw := server.URL
+		for name, variable := range server.Variables {
+			raw = strings.ReplaceAll(raw, "{"+name+"}", variable.Default)
+		}
+
+		path := raw
+		if u, err := url.Parse(raw); err == nil {
+			path = u.Path
+		}
+		paths = append(paths, strings.TrimSuffix(path, "/"))
+	}
+	return paths
+}
+
+// Operations returns the operations of the path item by HTTP method, in a fixed method order.
+func (inst *PathItem) Operations() []MethodOperation {
+	var operations []MethodOperation
+	for _, op := range []MethodOperation{
+		{"GET", inst.Get}, {"POST", inst.Post}, {"PUT", inst.Put}, {"PATCH", inst.Patch}, {"DELETE", inst.Delete},
+	} {
+		if op.Operation != nil {
+			operations = append(operations, op)
+		}
+	}
+	return operations
+}
+
+// MethodOperation is an operation together with its HTTP method.
+type MethodOperation struct {
+	Method    string
+	Operation *Operation
+}
+
+// parameter resolves a parameter reference.
+func (inst *Document) parameter(p *Parameter) (*Parameter, error) {
+	return resolve(p, p.Ref, "#/components/parameters/", inst.Components.Parameters, func(p *Parameter) string { return p.Ref })
+}
+
+// response resolves a response reference.
+func (inst *Document) response(r *Response) (*Response, error) {
+	return resolve(r, r.Ref, "#/components/responses/", inst.Components.Responses, func(r *Response) string { return r.Ref })
+}
+
+// requestBody resolves a request body reference.
+func (inst *Document) requestBody(b *RequestBody) (*RequestBody, error) {
+	return resolve(b, b.Ref, "#/components/requestBodies/", inst.Components.RequestBodies, func(b *RequestBody) string { return b.Ref })
+}
+
+// header resolves a header reference.
+func (inst *Document) header(h *Header) (*Header, error) {
+	return resolve(h, h.Ref, "#/components/headers/", inst.Components.Headers, func(h *Header) string { return h.Ref })
+}
+
+// example resolves an example reference.
+func (inst *Document) example(e *Example) (*Example, error) {
+	return resolve(e, e.Ref, "#/components/examples/", inst.Components.Examples, func(e *Example) string { return e.Ref })
+}
+
+// resolve follows the references to a component until an object without a reference is reached.
+func resolve[T any](value *T, ref, prefix string, components map[string]*T, refOf func(*T) string) (*T, error) {
+	for seen := 0; ref != ""; seen++ {
+		if seen > len(components) {
+			return nil, fmt.Errorf("circular reference '%s'", ref)
+		}
+
+		target, ok := components[strings.TrimPrefix(ref, prefix)]
+		if !ok || !strings.HasPrefix(ref, prefix) {
+			return nil, fmt.Errorf("unknown reference '%s'", ref)
+		}
+		value, ref = target, refOf(target)
+	}
+	return value, nil
+}
@@ -0,0 +1,315 @@
+package openapi
+
+import (
+	"fmt"
+	"mime"
+	"mockium/internal/model"
+	"mockium/internal/service/constants"
+	"mockium/internal/service/schema"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PreferHeader selects a documented response other than the default one, e.g. "Prefer: code=404".
+const PreferHeader = "Prefer"
+
+// ignoredHeaders are header parameters that OpenAPI describes elsewhere and are not matched.
+var ignoredHeaders = map[string]struct{}{
+	"accept":        {},
+	"authorization": {},
+	"content-type":  {},
+}
+
+// Templates converts the document to templates, one template per path.
+//
+// Every operation becomes a handle with MustMethod that returns the default response:
+// the first documented 2xx response, otherwise the "default" one. Each other documented
+// response gets a handle with a higher priority that matches the "Prefer: code=<status>" header.
+// Required query and header parameters become "${...}" matchers, restricted to the
+// enum values or the pattern of the parameter schema if there is one.
+//
+// Response bodies use the examples of the document or examples synthesized from the schemas.
+//
+// Returns the templates sorted by path, or an error if a reference cannot be resolved.
+func (inst *Document) Templates() ([]model.Template, error) {
+	paths := make([]string, 0, len(inst.Paths))
+	for path := range inst.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	templates := make([]model.Template, 0, len(paths))
+	for _, path := range paths {
+		item := inst.Paths[path]
+		if item == nil {
+			continue
+		}
+
+		template := model.Template{Path: path}
+		for _, op := range item.Operations() {
+			handles, err := inst.handles(op.Method, item.Parameters, op.Operation)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", op.Method, path, err)
+			}
+			template.Handle = append(template.Handle, handles...)
+		}
+
+		if len(template.Handle) > 0 {
+			templates = append(templates, template)
+		}
+	}
+
+	return templates, nil
+}
+
+// handles converts an operation to its handles, the default response first.
+func (inst *Document) handles(method string, pathParameters []*Parameter, op *Operation) ([]model.HandleTemplate, error) {
+	match, err := inst.match(method, append(append([]*Parameter{}, pathParameters...), op.Parameters...))
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	defaultCode := ""
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			defaultCode = code
+			break
+		}
+	}
+	if defaultCode == "" && op.Responses["default"] != nil {
+		defaultCode = "default"
+	}
+
+	var handles []model.HandleTemplate
+	if defaultCode == "" {
+		handles = append(handles, model.HandleTemplate{MatchRequestTemplate: match})
+	}
+
+	for _, code := range codes {
+		status, ok := statusCode(code)
+		if code == "default" && code == defaultCode {
+			status, ok = 200, true
+		}
+		if !ok {
+			continue
+		}
+
+		response, err := inst.setResponse(status, op.Responses[code])
+		if err != nil {
+			return nil, fmt.Errorf("response %s: %w", code, err)
+		}
+
+		handle := model.HandleTemplate{MatchRequestTemplate: match, SetResponseTemplate: response}
+		if code != defaultCode {
+			handle.Priority = 1
+			handle.MatchRequestTemplate.MustHeaders = copyMap(match.MustHeaders)
+			handle.MatchRequestTemplate.MustHeaders[PreferHeader] = fmt.Sprintf("${%s:\\bcode=%d\\b}", constants.RegexpValuePlaceholder, status)
+		}
+
+		if code == defaultCode {
+			handles = append([]model.HandleTemplate{handle}, handles...)
+		} else {
+			handles = append(handles, handle)
+		}
+	}
+
+	return handles, nil
+}
+
+// match builds the request matcher of an operation from its required query and header parameters.
+// Operation parameters override the path item parameters with the same name and location.
+func (inst *Document) match(method string, parameters []*Parameter) (model.MatchRequestTemplate, error) {
+	match := model.MatchRequestTemplate{MustMethod: model.Method(method)}
+
+	resolved := make(map[string]*Parameter)
+	var order []string
+	for _, p := range parameters {
+		p, err := inst.parameter(p)
+		if err != nil {
+			return match, err
+		}
+
+		key := p.In + ":" + strings.ToLower(p.Name)
+		if _, ok := resolved[key]; !ok {
+			order = append(order, key)
+		}
+		resolved[key] = p
+	}
+
+	for _, key := range order {
+		p := resolved[key]
+		if !p.Required {
+			continue
+		}
+
+		value, err := inst.valueMatcher(p.Schema)
+		if err != nil {
+			return match, fmt.Errorf("parameter '%s': %w", p.Name, err)
+		}
+
+		switch p.In {
+		case "query":
+			if match.MustQueryParameters == nil {
+				match.MustQueryParameters = make(map[string]any)
+			}
+			match.MustQueryParameters[p.Name] = value
+		case "header":
+			if _, ignored := ignoredHeaders[strings.ToLower(p.Name)]; ignored {
+				continue
+			}
+			if match.MustHeaders == nil {
+				match.MustHeaders = make(map[string]any)
+			}
+			match.MustHeaders[p.Name] = value
+		}
+	}
+
+	return match, nil
+}
+
+// valueMatcher returns the placeholder matching the values allowed by the parameter schema.
+func (inst *Document) valueMatcher(s *schema.Schema) (string, error) {
+	s, err := inst.refs.Resolve(s)
+	if err != nil || s == nil {
+		return constants.AnyValuePlaceholder, err
+	}
+
+	switch {
+	case len(s.Enum) > 0:
+		values := make([]string, 0, len(s.Enum))
+		for _, value := range s.Enum {
+			values = append(values, regexp.QuoteMeta(fmt.Sprint(value)))
+		}
+		return fmt.Sprintf("${%s:^(%s)$}", constants.RegexpValuePlaceholder, strings.Join(values, "|")), nil
+	case s.Pattern != "":
+		return fmt.Sprintf("${%s:%s}", constants.RegexpValuePlaceholder, s.Pattern), nil
+	}
+	return constants.AnyValuePlaceholder, nil
+}
+
+// setResponse converts a documented response to a response template with the given status.
+func (inst *Document) setResponse(status int, r *Response) (model.SetResponseTemplate, error) {
+	response := model.SetResponseTemplate{SetStatus: status}
+
+	r, err := inst.response(r)
+	if err != nil {
+		return response, err
+	}
+
+	for name, h := range r.Headers {
+		h, err := inst.header(h)
+		if err != nil {
+			return response, err
+		}
+
+		value := h.Example
+		if value == nil {
+			value = schema.Example(h.Schema, inst.refs)
+		}
+		if value == nil || strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+
+		if response.SetHeaders == nil {
+			response.SetHeaders = make(map[string]string)
+		}
+		response.SetHeaders[name] = fmt.Sprint(value)
+	}
+
+	mediaType, content := inst.mediaType(r.Content)
+	if content == nil {
+		return response, nil
+	}
+
+	body, err := inst.mediaExample(content)
+	if err != nil || body == nil {
+		return response, err
+	}
+
+	if response.SetHeaders == nil {
+		response.SetHeaders = make(map[string]string)
+	}
+	response.SetHeaders["Content-Type"] = mediaType
+
+	if isJSON(mediaType) {
+		response.SetBody = body
+	} else if text, ok := body.(string); ok {
+		response.SetRawBody = text
+	}
+
+	return response, nil
+}
+
+// mediaType chooses the media type of a body: JSON if documented, otherwise the first one.
+func (inst *Document) mediaType(content map[string]*MediaType) (string, *MediaType) {
+	if len(content) == 0 {
+		return "", nil
+	}
+
+	types := make([]string, 0, len(content))
+	for mediaType := range content {
+		types = append(types, mediaType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if isJSON(types[i]) != isJSON(types[j]) {
+			return isJSON(types[i])
+		}
+		return types[i] < types[j]
+	})
+
+	return types[0], content[types[0]]
+}
+
+// mediaExample returns the example of a body: the media type example, the first of its named
+// examples, or an example synthesized from the schema.
+func (inst *Document) mediaExample(content *MediaType) (any, error) {
+	if content.Example != nil {
+		return content.Example, nil
+	}
+
+	if len(content.Examples) > 0 {
+		names := make([]string, 0, len(content.Examples))
+		for name := range content.Examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		example, err := inst.example(content.Examples[names[0]])
+		if err != nil {
+			return nil, err
+		}
+		return example.Value, nil
+	}
+
+	return schema.Example(content.Schema, inst.refs), nil
+}
+
+// statusCode converts a response code to a status, e.g. "404" or "5XX".
+func statusCode(code string) (int, bool) {
+	status, err := strconv.Atoi(strings.NewReplacer("X", "0", "x", "0").Replace(code))
+	return status, err == nil && status >= 100 && status < 600
+}
+
+func isJSON(mediaType string) bool {
+	base, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		base = mediaType
+	}
+	return base == constants.ContentTypeApplicationJSON || strings.HasSuffix(base, "+json")
+}
+
+func copyMap(source map[string]any) map[string]any {
+	result := make(map[string]any, len(source)+1)
+	for key, value := range source {
+		result[key] = value
+	}
+	return result
+}
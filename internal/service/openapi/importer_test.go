@@ -0,0 +1,85 @@
+package openapi
+
+import (
+	"mockium/internal/model"
+	"mockium/internal/service/builder"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestDocument_Templates(t *testing.T) {
+	doc, err := Load("testdata/petstore.yaml")
+	require.NoError(t, err)
+
+	templates, err := doc.Templates()
+	require.NoError(t, err)
+	require.NoError(t, builder.NewTemplateBuilder(zap.NewNop()).Validate(templates))
+	require.Len(t, templates, 2)
+
+	pets := templates[0]
+	assert.Equal(t, "/pets", pets.Path)
+	require.Len(t, pets.Handle, 3)
+
+	list := pets.Handle[0]
+	assert.Equal(t, model.GET, list.MatchRequestTemplate.MustMethod)
+	assert.Equal(t, 0, list.Priority)
+	assert.Equal(t, map[string]any{"limit": "${...}", "status": "${regexp:^(available|sold)$}"}, list.MatchRequestTemplate.MustQueryParameters)
+	assert.Equal(t, map[string]any{"X-Tenant": "${regexp:^[a-z]+$}"}, list.MatchRequestTemplate.MustHeaders)
+	assert.Equal(t, http.StatusOK, list.SetResponseTemplate.SetStatus)
+	assert.Equal(t, "2", list.SetResponseTemplate.SetHeaders["X-Total"])
+	assert.Equal(t, "application/json", list.SetResponseTemplate.SetHeaders["Content-Type"])
+
+	body, ok := list.SetResponseTemplate.SetBody.([]any)
+	require.True(t, ok)
+	require.Len(t, body, 1)
+	pet := body[0].(map[string]any)
+	assert.Equal(t, int64(1), pet["id"])
+	assert.Equal(t, "string", pet["name"])
+	assert.Equal(t, "3fa85f64-5717-4562-b3fc-2c963f66afa6", pet["tag"])
+	assert.Equal(t, map[string]any{"email": "user@example.com", "pets": []any{}}, pet["owner"])
+
+	tooMany := pets.Handle[1]
+	assert.Equal(t, 1, tooMany.Priority)
+	assert.Equal(t, http.StatusTooManyRequests, tooMany.SetResponseTemplate.SetStatus)
+	assert.Equal(t, `${regexp:\bcode=429\b}`, tooMany.MatchRequestTemplate.MustHeaders[PreferHeader])
+	assert.Equal(t, "${regexp:^[a-z]+$}", tooMany.MatchRequestTemplate.MustHeaders["X-Tenant"])
+	assert.NotContains(t, list.MatchRequestTemplate.MustHeaders, PreferHeader)
+	assert.Equal(t, map[string]any{"title": "Too Many Requests"}, tooMany.SetResponseTemplate.SetBody)
+
+	create := pets.Handle[2]
+	assert.Equal(t, model.POST, create.MatchRequestTemplate.MustMethod)
+	assert.Equal(t, http.StatusCreated, create.SetResponseTemplate.SetStatus)
+	assert.Equal(t, map[string]any{"id": 1, "name": "Rex"}, create.SetResponseTemplate.SetBody)
+
+	pet2 := templates[1]
+	assert.Equal(t, "/pets/{id}", pet2.Path)
+	require.Len(t, pet2.Handle, 1)
+	assert.Equal(t, http.StatusOK, pet2.Handle[0].SetResponseTemplate.SetStatus)
+	assert.Equal(t, "unexpected error", pet2.Handle[0].SetResponseTemplate.SetRawBody)
+	assert.Equal(t, "text/plain", pet2.Handle[0].SetResponseTemplate.SetHeaders["Content-Type"])
+}
+
+func TestParse_Errors(t *testing.T) {
+	_, err := Parse([]byte(`swagger: "2.0"`))
+	assert.Error(t, err)
+
+	doc, err := Parse([]byte(`
+openapi: 3.1.0
+paths:
+  /pets:
+    get:
+      parameters:
+        - $ref: '#/components/parameters/Missing'
+      responses:
+        '200':
+          description: ok
+`))
+	require.NoError(t, err)
+
+	_, err = doc.Templates()
+	assert.Error(t, err)
+}
@@ -0,0 +1,309 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mockium/internal/model"
+	"mockium/internal/service/schema"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// regexpPathParameter matches a path template parameter, e.g. "{id}".
+var regexpPathParameter = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// Validator checks incoming requests against the operations of an OpenAPI document.
+type Validator struct {
+	doc   *Document
+	bases []string        // Base paths of the document paths, from the servers of the document.
+	paths []validatorPath // Document paths, the ones with fewer parameters first.
+}
+
+// validatorPath is a document path compiled to a regular expression.
+type validatorPath struct {
+	pattern *regexp.Regexp
+	names   []string // Names of the path parameters in the order of the pattern groups.
+	item    *PathItem
+}
+
+// NewValidator creates a request validator for the document.
+//
+// Parameters:
+//   - doc: the OpenAPI document.
+//
+// Returns the validator, or an error if a parameter, request body or schema reference is broken.
+func NewValidator(doc *Document) (*Validator, error) {
+	inst := &Validator{doc: doc, bases: doc.BasePaths()}
+
+	for path, item := range doc.Paths {
+		if item == nil {
+			continue
+		}
+
+		var names []string
+		expr, last := "^", 0
+		for _, loc := range regexpPathParameter.FindAllStringSubmatchIndex(path, -1) {
+			expr += regexp.QuoteMeta(path[last:loc[0]]) + "([^/]+)"
+			names = append(names, path[loc[2]:loc[3]])
+			last = loc[1]
+		}
+
+		pattern, err := regexp.Compile(expr + regexp.QuoteMeta(path[last:]) + "$")
+		if err != nil {
+			return nil, fmt.Errorf("path '%s': %w", path, err)
+		}
+
+		for _, op := range item.Operations() {
+			if _, err := inst.parameters(item, op.Operation); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", op.Method, path, err)
+			}
+			if op.Operation.RequestBody != nil {
+				if _, err := doc.requestBody(op.Operation.RequestBody); err != nil {
+					return nil, fmt.Errorf("%s %s: %w", op.Method, path, err)
+				}
+			}
+		}
+
+		inst.paths = append(inst.paths, validatorPath{pattern: pattern, names: names, item: item})
+	}
+
+	// Literal paths take precedence over templated ones, e.g. "/pets/mine" over "/pets/{id}".
+	sort.SliceStable(inst.paths, func(i, j int) bool {
+		if len(inst.paths[i].names) != len(inst.paths[j].names) {
+			return len(inst.paths[i].names) < len(inst.paths[j].names)
+		}
+		return inst.paths[i].pattern.String() < inst.paths[j].pattern.String()
+	})
+
+	return inst, nil
+}
+
+// Validate checks the path parameters, query parameters, headers and JSON body of the request
+// against the operation the request is addressed to.
+//
+// Parameters:
+//   - req: the incoming HTTP request, its body is restored after reading.
+//
+// Returns the violations found, nil if the request is valid or no operation of the document matches it.
+func (inst *Validator) Validate(req *http.Request) []model.Violation {
+	path, values := inst.match(req.URL.Path)
+	if path == nil {
+		return nil
+	}
+
+	var operation *Operation
+	for _, op := range path.item.Operations() {
+		if op.Method == req.Method {
+			operation = op.Operation
+		}
+	}
+	if operation == nil {
+		return nil
+	}
+
+	// References were checked by NewValidator.
+	parameters, _ := inst.parameters(path.item, operation)
+
+	var violations []model.Violation
+	for _, p := range parameters {
+		violations = append(violations, inst.validateParameter(req, p, values)...)
+	}
+
+	if operation.RequestBody != nil {
+		body, _ := inst.doc.requestBody(operation.RequestBody)
+		violations = append(violations, inst.validateBody(req, body)...)
+	}
+
+	return violations
+}
+
+// match finds the document path matching the request path under one of the base paths.
+//
+// Returns the path and the values of its parameters, or nil if no path matches.
+func (inst *Validator) match(requestPath string) (*validatorPath, map[string]string) {
+	for _, base := range inst.bases {
+		relative, ok := strings.CutPrefix(requestPath, base)
+		if !ok || !strings.HasPrefix(relative, "/") {
+			continue
+		}
+
+		for i := range inst.paths {
+			path := &inst.paths[i]
+			groups := path.pattern.FindStringSubmatch(relative)
+			if groups == nil {
+				continue
+			}
+
+			values := make(map[string]string, len(path.names))
+			for j, name := range path.names {
+				values[name] = groups[j+1]
+			}
+			return path, values
+		}
+	}
+	return nil, nil
+}
+
+// parameters returns the resolved parameters of the operation, including the ones
+// of its path item not overridden by the operation.
+func (inst *Validator) parameters(item *PathItem, operation *Operation) ([]*Parameter, error) {
+	var parameters []*Parameter
+	seen := make(map[string]int)
+	for _, list := range [][]*Parameter{item.Parameters, operation.Parameters} {
+		for _, p := range list {
+			resolved, err := inst.doc.parameter(p)
+			if err != nil {
+				return nil, err
+			}
+
+			key := resolved.In + ":" + strings.ToLower(resolved.Name)
+			if i, ok := seen[key]; ok {
+				parameters[i] = resolved
+				continue
+			}
+			seen[key] = len(parameters)
+			parameters = append(parameters, resolved)
+		}
+	}
+	return parameters, nil
+}
+
+// validateParameter checks a path, query or header parameter, cookies are not checked.
+func (inst *Validator) validateParameter(req *http.Request, p *Parameter, pathValues map[string]string) []model.Violation {
+	var raw []string
+	switch p.In {
+	case "path":
+		if value, ok := pathValues[p.Name]; ok {
+			raw = []string{value}
+		}
+	case "query":
+		raw = req.URL.Query()[p.Name]
+	case "header":
+		raw = req.Header.Values(p.Name)
+	default:
+		return nil
+	}
+
+	if len(raw) == 0 {
+		if p.Required || p.In == "path" {
+			return []model.Violation{{In: p.In, Name: p.Name, Message: "missing required parameter"}}
+		}
+		return nil
+	}
+
+	if p.Schema == nil {
+		return nil
+	}
+
+	s, err := inst.doc.refs.Resolve(p.Schema)
+	if err != nil {
+		return []model.Violation{{In: p.In, Name: p.Name, Message: err.Error()}}
+	}
+
+	var value any
+	if s.Type.Has(schema.TypeArray) {
+		items := make([]any, 0, len(raw))
+		for _, r := range raw {
+			for _, item := range strings.Split(r, ",") {
+				items = append(items, inst.parameterValue(s.Items, item))
+			}
+		}
+		value = items
+	} else {
+		value = inst.parameterValue(s, raw[0])
+	}
+
+	var violations []model.Violation
+	for _, v := range schema.Validate(s, inst.doc.refs, value) {
+		violations = append(violations, model.Violation{In: p.In, Name: p.Name, Message: v.String()})
+	}
+	return violations
+}
+
+// parameterValue converts the text of a parameter to the type of its schema,
+// a value that cannot be converted is kept as text and fails the type check.
+func (inst *Validator) parameterValue(s *schema.Schema, raw string) any {
+	s, err := inst.doc.refs.Resolve(s)
+	if err != nil || s == nil {
+		return raw
+	}
+
+	switch {
+	case s.Type.Has(schema.TypeInteger), s.Type.Has(schema.TypeNumber):
+		if n, err := strconv.ParseFloat(raw, 64); err == nil {
+			return n
+		}
+	case s.Type.Has(schema.TypeBoolean):
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+// validateBody checks the request body against the schema of its media type.
+// Only JSON bodies are validated against the schema; other media types are only checked to be documented.
+func (inst *Validator) validateBody(req *http.Request, body *RequestBody) []model.Violation {
+	var data []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if data, err = io.ReadAll(req.Body); err != nil {
+			return []model.Violation{{In: "body", Message: "failed to read body: " + err.Error()}}
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	if len(data) == 0 {
+		if body.Required {
+			return []model.Violation{{In: "body", Message: "missing required request body"}}
+		}
+		return nil
+	}
+
+	if len(body.Content) == 0 {
+		return nil
+	}
+
+	contentType := req.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return []model.Violation{{In: "header", Name: "Content-Type", Message: fmt.Sprintf("unsupported media type '%s'", contentType)}}
+	}
+
+	media, ok := mediaTypeOf(body.Content, mediaType)
+	if !ok {
+		return []model.Violation{{In: "header", Name: "Content-Type", Message: fmt.Sprintf("unsupported media type '%s'", mediaType)}}
+	}
+
+	if media == nil || media.Schema == nil || !isJSON(mediaType) {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return []model.Violation{{In: "body", Message: "invalid JSON: " + err.Error()}}
+	}
+
+	var violations []model.Violation
+	for _, v := range schema.Validate(media.Schema, inst.doc.refs, value) {
+		violations = append(violations, model.Violation{In: "body", Name: v.Path, Message: v.Message})
+	}
+	return violations
+}
+
+// mediaTypeOf finds the content of the media type, also by the "type/*" and "*/*" ranges.
+func mediaTypeOf(content map[string]*MediaType, mediaType string) (*MediaType, bool) {
+	for _, key := range []string{mediaType, strings.SplitN(mediaType, "/", 2)[0] + "/*", "*/*"} {
+		if media, ok := content[key]; ok {
+			return media, true
+		}
+	}
+	return nil, false
+}
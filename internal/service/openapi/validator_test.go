@@ -0,0 +1,171 @@
+package openapi
+
+import (
+	"io"
+	"mockium/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidator_Validate(t *testing.T) {
+	doc, err := Load("testdata/petstore.yaml")
+	require.NoError(t, err)
+
+	validator, err := NewValidator(doc)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		headers map[string]string
+		body    string
+		want    []model.Violation
+	}{
+		{
+			name:    "valid query",
+			method:  http.MethodGet,
+			target:  "/pets?limit=10&status=sold",
+			headers: map[string]string{"X-Tenant": "acme"},
+		},
+		{
+			name:   "invalid query and header",
+			method: http.MethodGet,
+			target: "/pets?limit=ten&offset=1",
+			want: []model.Violation{
+				{In: "query", Name: "limit", Message: "must be integer"},
+				{In: "query", Name: "status", Message: "missing required parameter"},
+				{In: "header", Name: "X-Tenant", Message: "missing required parameter"},
+			},
+		},
+		{
+			name:    "header pattern",
+			method:  http.MethodGet,
+			target:  "/pets?limit=1&status=available",
+			headers: map[string]string{"X-Tenant": "ACME"},
+			want:    []model.Violation{{In: "header", Name: "X-Tenant", Message: "must match pattern '^[a-z]+$'"}},
+		},
+		{
+			name:    "valid body",
+			method:  http.MethodPost,
+			target:  "/pets",
+			headers: map[string]string{"Content-Type": "application/json; charset=utf-8"},
+			body:    `{"id": 1, "name": "Rex", "owner": {"email": "user@example.com"}}`,
+		},
+		{
+			name:    "invalid body",
+			method:  http.MethodPost,
+			target:  "/pets",
+			headers: map[string]string{"Content-Type": "application/json"},
+			body:    `{"id": 0, "owner": {"email": "user"}}`,
+			want: []model.Violation{
+				{In: "body", Message: "missing required property 'name'"},
+				{In: "body", Name: "/id", Message: "must be greater than or equal to 1"},
+				{In: "body", Name: "/owner/email", Message: "must be a valid email"},
+			},
+		},
+		{
+			name:    "invalid JSON",
+			method:  http.MethodPost,
+			target:  "/pets",
+			headers: map[string]string{"Content-Type": "application/json"},
+			body:    `{`,
+			want:    []model.Violation{{In: "body", Message: "invalid JSON: unexpected end of JSON input"}},
+		},
+		{
+			name:    "unsupported media type",
+			method:  http.MethodPost,
+			target:  "/pets",
+			headers: map[string]string{"Content-Type": "text/plain"},
+			body:    `Rex`,
+			want:    []model.Violation{{In: "header", Name: "Content-Type", Message: "unsupported media type 'text/plain'"}},
+		},
+		{
+			name:   "path parameter",
+			method: http.MethodGet,
+			target: "/pets/42",
+		},
+		{
+			name:   "undocumented method",
+			method: http.MethodDelete,
+			target: "/pets/42",
+		},
+		{
+			name:   "undocumented path",
+			method: http.MethodGet,
+			target: "/owners",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			assert.Equal(t, tt.want, validator.Validate(req))
+
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.body, string(body))
+		})
+	}
+}
+
+func TestValidator_BasePath(t *testing.T) {
+	doc, err := Parse([]byte(`
+openapi: 3.0.0
+servers:
+  - url: https://api.example.com/v1/
+  - url: "{scheme}://staging.example.com/{basePath}"
+    variables:
+      scheme:
+        default: https
+      basePath:
+        default: api/v2
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          required: true
+          schema:
+            type: integer
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/v1", "/api/v2"}, doc.BasePaths())
+
+	validator, err := NewValidator(doc)
+	require.NoError(t, err)
+
+	missing := []model.Violation{{In: "query", Name: "limit", Message: "missing required parameter"}}
+	assert.Equal(t, missing, validator.Validate(httptest.NewRequest(http.MethodGet, "/v1/pets", nil)))
+	assert.Equal(t, missing, validator.Validate(httptest.NewRequest(http.MethodGet, "/api/v2/pets", nil)))
+	assert.Empty(t, validator.Validate(httptest.NewRequest(http.MethodGet, "/v1/pets?limit=1", nil)))
+
+	// Outside of the base paths the document describes no operation.
+	assert.Empty(t, validator.Validate(httptest.NewRequest(http.MethodGet, "/pets", nil)))
+	assert.Empty(t, validator.Validate(httptest.NewRequest(http.MethodGet, "/v10/pets", nil)))
+}
+
+func TestNewValidator_BrokenReference(t *testing.T) {
+	doc, err := Parse([]byte(`
+openapi: 3.0.0
+paths:
+  /pets:
+    get:
+      parameters:
+        - $ref: '#/components/parameters/Missing'
+`))
+	require.NoError(t, err)
+
+	_, err = NewValidator(doc)
+	assert.ErrorContains(t, err, "unknown reference")
+}
@@ -0,0 +1,50 @@
+// Package random is the source of the random choices of the mock: response variants,
+// delays, faults and the random values of response templates.
+// Seeding it makes the choices reproducible for the same sequence of requests.
+package random
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	mu     sync.Mutex
+	source = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// Seed replaces the source with one seeded with the given value.
+func Seed(seed int64) {
+	mu.Lock()
+	defer mu.Unlock()
+	source = rand.New(rand.NewSource(seed))
+}
+
+// Float64 returns a number in [0.0, 1.0).
+func Float64() float64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return source.Float64()
+}
+
+// NormFloat64 returns a normally distributed number with mean 0 and standard deviation 1.
+func NormFloat64() float64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return source.NormFloat64()
+}
+
+// Int63n returns a number in [0, n). It panics if n <= 0.
+func Int63n(n int64) int64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return source.Int63n(n)
+}
+
+// Intn returns a number in [0, n). It panics if n <= 0.
+func Intn(n int) int {
+	mu.Lock()
+	defer mu.Unlock()
+	return source.Intn(n)
+}
@@ -0,0 +1,22 @@
+package random
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeed(t *testing.T) {
+	sample := func() []any {
+		return []any{Float64(), NormFloat64(), Int63n(1000), Intn(1000)}
+	}
+
+	Seed(42)
+	first := sample()
+
+	Seed(42)
+	assert.Equal(t, first, sample())
+
+	Seed(43)
+	assert.NotEqual(t, first, sample())
+}
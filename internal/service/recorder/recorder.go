@@ -0,0 +1,356 @@
+// Package recorder saves proxied exchanges as templates, so that a recorded
+// upstream can be replayed from the template directory.
+package recorder
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"mockium/internal/model"
+	"mockium/internal/service/constants"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// AllParameters selects every query parameter or header for matching.
+const AllParameters = "*"
+
+// skipResponseHeaders are the upstream response headers that are not recorded,
+// because they describe the upstream connection rather than the response.
+var skipResponseHeaders = map[string]struct{}{
+	"Connection":        {},
+	"Content-Encoding":  {},
+	"Content-Length":    {},
+	"Date":              {},
+	"Keep-Alive":        {},
+	"Proxy-Connection":  {},
+	"Trailer":           {},
+	"Transfer-Encoding": {},
+	"Upgrade":           {},
+}
+
+// unsafeFileChars are replaced in file names derived from request paths.
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Options selects the request parts recorded as match criteria.
+type Options struct {
+	Query   []string // Query parameters to match, AllParameters for every parameter.
+	Headers []string // Headers to match, AllParameters for every header.
+}
+
+// Recorder writes every exchange as a handle of the template for the request path.
+// Each path is stored in its own JSON file in the template directory.
+type Recorder struct {
+	log     *zap.Logger
+	dir     string
+	options Options
+	mu      sync.Mutex // Serializes the read-modify-write of template files.
+}
+
+// New creates a new Recorder.
+//
+// Parameters:
+//   - log: logger for recording diagnostics.
+//   - dir: template directory the templates are written to.
+//   - options: request parts recorded as match criteria.
+//
+// Returns a pointer to a Recorder.
+func New(log *zap.Logger, dir string, options Options) *Recorder {
+	headers := make([]string, 0, len(options.Headers))
+	for _, name := range options.Headers {
+		headers = append(headers, http.CanonicalHeaderKey(name))
+	}
+	options.Headers = headers
+
+	return &Recorder{
+		log:     log,
+		dir:     dir,
+		options: options,
+	}
+}
+
+// Record saves the exchange as a handle of the template for the request path.
+// A handle recorded earlier for the same request is replaced by the new one.
+//
+// Parameters:
+//   - exchange: the proxied request and the upstream response.
+//
+// Returns an error if the method is not supported or the template cannot be written.
+func (inst *Recorder) Record(exchange *model.Exchange) error {
+	switch model.Method(exchange.Method) {
+	case model.GET, model.POST, model.DELETE, model.PATCH, model.PUT:
+	default:
+		return fmt.Errorf("unsupported method '%s'", exchange.Method)
+	}
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	fileName, template, err := inst.load(exchange.Path)
+	if err != nil {
+		return err
+	}
+
+	handle, err := inst.handle(exchange, fileName)
+	if err != nil {
+		return err
+	}
+
+	template.Handle = replaceHandle(template.Handle, handle)
+
+	data, err := json.MarshalIndent(template, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFile(filepath.Join(inst.dir, fileName), data); err != nil {
+		return err
+	}
+
+	inst.log.Info("recorded exchange",
+		zap.String("method", exchange.Method),
+		zap.String("path", exchange.Path),
+		zap.Int("status", exchange.Status),
+		zap.String("file", fileName))
+
+	return nil
+}
+
+// load reads the template recorded for the path. The file name is derived from the path;
+// if another path already owns that name, a hash of the path is appended.
+//
+// Returns the template file name and the template, empty if nothing was recorded for the path yet.
+func (inst *Recorder) load(path string) (string, *model.Template, error) {
+	base := strings.Trim(unsafeFileChars.ReplaceAllString(path, "_"), "_")
+	if base == "" {
+		base = "root"
+	}
+
+	sum := sha1.Sum([]byte(path))
+	for _, fileName := range []string{base + ".json", base + "-" + hex.EncodeToString(sum[:4]) + ".json"} {
+		data, err := os.ReadFile(filepath.Join(inst.dir, fileName))
+		if errors.Is(err, os.ErrNotExist) {
+			return fileName, &model.Template{Path: path}, nil
+		}
+		if err != nil {
+			return "", nil, err
+		}
+
+		template := &model.Template{}
+		if err := json.Unmarshal(data, template); err != nil {
+			return "", nil, fmt.Errorf("%w, file: %s", err, fileName)
+		}
+
+		if template.Path == path {
+			return fileName, template, nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("no free template file name for path '%s'", path)
+}
+
+// handle converts the exchange to a handle: the request becomes MatchRequest
+// and the upstream response becomes SetResponse.
+func (inst *Recorder) handle(exchange *model.Exchange, fileName string) (model.HandleTemplate, error) {
+	match := model.MatchRequestTemplate{MustMethod: model.Method(exchange.Method)}
+
+	for name, values := range exchange.Query {
+		if values[0] != "" && selected(inst.options.Query, name) {
+			if match.MustQueryParameters == nil {
+				match.MustQueryParameters = make(map[string]any)
+			}
+			match.MustQueryParameters[name] = escapeLiteral(values[0])
+		}
+	}
+
+	for name, values := range exchange.Headers {
+		if selected(inst.options.Headers, name) {
+			if match.MustHeaders == nil {
+				match.MustHeaders = make(map[string]any)
+			}
+			match.MustHeaders[name] = escapeLiteral(values[0])
+		}
+	}
+
+	if body := requestBody(exchange); body != nil {
+		if match.MustHeaders == nil {
+			match.MustHeaders = make(map[string]any)
+		}
+		match.MustHeaders["Content-Type"] = escapeLiteral(exchange.Headers.Get("Content-Type"))
+		match.MustBody = escapeBody(body).(map[string]any)
+	}
+
+	response := model.SetResponseTemplate{SetStatus: exchange.Status}
+	for name, values := range exchange.ResponseHeaders {
+		if _, skip := skipResponseHeaders[name]; skip {
+			continue
+		}
+		if len(values) > 1 {
+			if response.SetHeaderValues == nil {
+				response.SetHeaderValues = make(map[string][]string)
+			}
+			response.SetHeaderValues[name] = values
+			continue
+		}
+		if response.SetHeaders == nil {
+			response.SetHeaders = make(map[string]string)
+		}
+		response.SetHeaders[name] = values[0]
+	}
+
+	if len(exchange.ResponseBody) == 0 {
+		return model.HandleTemplate{MatchRequestTemplate: match, SetResponseTemplate: response}, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(exchange.ResponseHeaders.Get("Content-Type"))
+	var body any
+	switch {
+	case isJSON(mediaType) && json.Unmarshal(exchange.ResponseBody, &body) == nil && !hasPlaceholder(body):
+		response.SetBody = body
+	case utf8.Valid(exchange.ResponseBody):
+		response.SetRawBody = string(exchange.ResponseBody)
+	default:
+		// Binary bodies are stored next to the template and sent as a file.
+		sum := sha1.Sum(exchange.ResponseBody)
+		bodyFile := filepath.Join(inst.dir, strings.TrimSuffix(fileName, ".json")+"-"+hex.EncodeToString(sum[:4])+".body")
+		if err := writeFile(bodyFile, exchange.ResponseBody); err != nil {
+			return model.HandleTemplate{}, err
+		}
+		response.SetFile = bodyFile
+	}
+
+	return model.HandleTemplate{MatchRequestTemplate: match, SetResponseTemplate: response}, nil
+}
+
+// requestBody parses the request body that BodyMatcher is able to compare:
+// a JSON object or a form-urlencoded body. Returns nil for any other body.
+// A repeated form field is recorded with its last value, the one BodyMatcher compares.
+func requestBody(exchange *model.Exchange) map[string]any {
+	if len(exchange.Body) == 0 {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(exchange.Headers.Get("Content-Type"))
+	if err != nil {
+		return nil
+	}
+
+	switch mediaType {
+	case constants.ContentTypeApplicationJSON:
+		body := make(map[string]any)
+		if err := json.Unmarshal(exchange.Body, &body); err != nil {
+			return nil
+		}
+		return body
+	case constants.ContentTypeFormURLEncoded:
+		values, err := url.ParseQuery(string(exchange.Body))
+		if err != nil {
+			return nil
+		}
+
+		body := make(map[string]any, len(values))
+		for name, fieldValues := range values {
+			body[name] = fieldValues[len(fieldValues)-1]
+		}
+		return body
+	}
+	return nil
+}
+
+// escapeLiteral keeps a recorded value from being read as a placeholder:
+// a value containing "${" is matched by a regexp of the quoted value instead.
+func escapeLiteral(value string) string {
+	if !strings.Contains(value, "${") {
+		return value
+	}
+
+	pattern := strings.NewReplacer("\n", `\n`, "\r", `\r`).Replace(regexp.QuoteMeta(value))
+	return "${" + constants.RegexpValuePlaceholder + ":^" + pattern + "$}"
+}
+
+// escapeBody escapes the string values of a recorded body at any depth.
+func escapeBody(value any) any {
+	switch v := value.(type) {
+	case string:
+		return escapeLiteral(v)
+	case map[string]any:
+		for key, item := range v {
+			v[key] = escapeBody(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = escapeBody(item)
+		}
+	}
+	return value
+}
+
+// hasPlaceholder reports whether a string of the body contains "${" at any depth.
+// Such a body is recorded as SetRawBody, which is sent as is.
+func hasPlaceholder(value any) bool {
+	switch v := value.(type) {
+	case string:
+		return strings.Contains(v, "${")
+	case map[string]any:
+		for _, item := range v {
+			if hasPlaceholder(item) {
+				return true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if hasPlaceholder(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// replaceHandle replaces the handle matching the same request, or appends the handle.
+func replaceHandle(handles []model.HandleTemplate, handle model.HandleTemplate) []model.HandleTemplate {
+	key, _ := json.Marshal(handle.MatchRequestTemplate)
+	for i := range handles {
+		if existing, _ := json.Marshal(handles[i].MatchRequestTemplate); bytes.Equal(existing, key) {
+			handles[i] = handle
+			return handles
+		}
+	}
+	return append(handles, handle)
+}
+
+// selected reports whether the name is in the list, or the list selects every name.
+func selected(names []string, name string) bool {
+	for _, selectedName := range names {
+		if selectedName == AllParameters || selectedName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == constants.ContentTypeApplicationJSON || strings.HasSuffix(mediaType, "+json")
+}
+
+// writeFile writes the file through a temporary file, so that the template
+// directory watcher never loads a partially written template.
+func writeFile(name string, data []byte) error {
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
@@ -0,0 +1,235 @@
+package recorder
+
+import (
+	"mockium/internal/model"
+	"mockium/internal/service/builder"
+	"mockium/internal/service/scenario"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRecorder_Record(t *testing.T) {
+	dir := t.TempDir()
+	rec := New(zaptest.NewLogger(t), dir, Options{Query: []string{"page"}, Headers: []string{"x-tenant"}})
+
+	require.NoError(t, rec.Record(&model.Exchange{
+		Method:          "GET",
+		Path:            "/users",
+		Query:           url.Values{"page": {"2"}, "trace": {"abc"}},
+		Headers:         http.Header{"X-Tenant": {"acme"}, "User-Agent": {"test"}},
+		Status:          http.StatusOK,
+		ResponseHeaders: http.Header{"Content-Type": {"application/json"}, "Content-Length": {"9"}, "X-Total": {"1"}},
+		ResponseBody:    []byte(`[{"id":1}]`),
+	}))
+
+	require.NoError(t, rec.Record(&model.Exchange{
+		Method:          "POST",
+		Path:            "/users",
+		Headers:         http.Header{"Content-Type": {"application/json"}, "X-Tenant": {"acme"}},
+		Body:            []byte(`{"name":"test"}`),
+		Status:          http.StatusCreated,
+		ResponseHeaders: http.Header{"Content-Type": {"text/plain"}},
+		ResponseBody:    []byte("created"),
+	}))
+
+	templates, err := builder.NewTemplateBuilder(zaptest.NewLogger(t)).Build(dir)
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+
+	template := templates[0]
+	assert.Equal(t, "/users", template.Path)
+	assert.Equal(t, "users.json", template.ID)
+	require.Len(t, template.Handle, 2)
+
+	get := template.Handle[0]
+	assert.Equal(t, model.GET, get.MatchRequestTemplate.MustMethod)
+	assert.Equal(t, map[string]any{"page": "2"}, get.MatchRequestTemplate.MustQueryParameters)
+	assert.Equal(t, map[string]any{"X-Tenant": "acme"}, get.MatchRequestTemplate.MustHeaders)
+	assert.Equal(t, http.StatusOK, get.SetResponseTemplate.SetStatus)
+	assert.Equal(t, map[string]string{"Content-Type": "application/json", "X-Total": "1"}, get.SetResponseTemplate.SetHeaders)
+	assert.Equal(t, []any{map[string]any{"id": float64(1)}}, get.SetResponseTemplate.SetBody)
+
+	post := template.Handle[1]
+	assert.Equal(t, model.POST, post.MatchRequestTemplate.MustMethod)
+	assert.Equal(t, map[string]any{"name": "test"}, post.MatchRequestTemplate.MustBody)
+	assert.Equal(t, "application/json", post.MatchRequestTemplate.MustHeaders["Content-Type"])
+	assert.Equal(t, "created", post.SetResponseTemplate.SetRawBody)
+}
+
+func TestRecorder_ReplaceSameRequest(t *testing.T) {
+	dir := t.TempDir()
+	rec := New(zaptest.NewLogger(t), dir, Options{})
+
+	for _, status := range []int{http.StatusInternalServerError, http.StatusOK} {
+		require.NoError(t, rec.Record(&model.Exchange{Method: "GET", Path: "/health", Status: status}))
+	}
+
+	templates, err := builder.NewTemplateBuilder(zaptest.NewLogger(t)).Build(dir)
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	require.Len(t, templates[0].Handle, 1)
+	assert.Equal(t, http.StatusOK, templates[0].Handle[0].SetResponseTemplate.SetStatus)
+}
+
+func TestRecorder_ReplayLiterals(t *testing.T) {
+	dir := t.TempDir()
+	rec := New(zaptest.NewLogger(t), dir, Options{Query: []string{AllParameters}, Headers: []string{"X-Filter"}})
+
+	require.NoError(t, rec.Record(&model.Exchange{
+		Method:  "POST",
+		Path:    "/search",
+		Query:   url.Values{"q": {"${req.query:q}"}},
+		Headers: http.Header{"Content-Type": {"application/json"}, "X-Filter": {"${regexp:.*}"}},
+		Body:    []byte(`{"term": "${any}", "lines": ["a\nb (c)"]}`),
+		Status:  http.StatusOK,
+		ResponseHeaders: http.Header{
+			"Content-Type": {"application/json"},
+			"Set-Cookie":   {"session=1; Path=/", "theme=dark; Path=/"},
+		},
+		ResponseBody: []byte(`{"echo":"${req.query:q}"}`),
+	}))
+
+	templates, err := builder.NewTemplateBuilder(zaptest.NewLogger(t)).Build(dir)
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+
+	response := templates[0].Handle[0].SetResponseTemplate
+	assert.Equal(t, map[string][]string{"Set-Cookie": {"session=1; Path=/", "theme=dark; Path=/"}}, response.SetHeaderValues)
+	assert.Equal(t, `{"echo":"${req.query:q}"}`, response.SetRawBody)
+
+	route := builder.BuildRoutes(zap.NewNop(), nopProcessLogger{}, scenario.New(), builder.Options{}, &templates[0])
+	serve := func(query, filter, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/search?"+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Filter", filter)
+		w := httptest.NewRecorder()
+		route.Handler(model.POST).ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("q=%24%7Breq.query%3Aq%7D", "${regexp:.*}", `{"term": "${any}", "lines": ["a\nb (c)"]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"session=1; Path=/", "theme=dark; Path=/"}, w.Header().Values("Set-Cookie"))
+	assert.Equal(t, `{"echo":"${req.query:q}"}`, w.Body.String())
+
+	// The recorded values match only themselves.
+	assert.Equal(t, http.StatusNotFound, serve("q=other", "${regexp:.*}", `{"term": "${any}", "lines": ["a\nb (c)"]}`).Code)
+	assert.Equal(t, http.StatusNotFound, serve("q=%24%7Breq.query%3Aq%7D", "other", `{"term": "${any}", "lines": ["a\nb (c)"]}`).Code)
+	assert.Equal(t, http.StatusNotFound, serve("q=%24%7Breq.query%3Aq%7D", "${regexp:.*}", `{"term": "other", "lines": ["a\nb (c)"]}`).Code)
+}
+
+type nopProcessLogger struct{}
+
+func (nopProcessLogger) Log(*model.ProcessLoggingFileds) {}
+
+func TestRecorder_FileNames(t *testing.T) {
+	dir := t.TempDir()
+	rec := New(zaptest.NewLogger(t), dir, Options{})
+
+	for _, path := range []string{"/", "/a/b", "/a_b"} {
+		require.NoError(t, rec.Record(&model.Exchange{Method: "GET", Path: path, Status: http.StatusOK}))
+	}
+
+	templates, err := builder.NewTemplateBuilder(zaptest.NewLogger(t)).Build(dir)
+	require.NoError(t, err)
+	require.Len(t, templates, 3)
+
+	paths := make(map[string]string)
+	for _, template := range templates {
+		paths[template.Path] = template.ID
+	}
+	assert.Equal(t, "root.json", paths["/"])
+	assert.Equal(t, "a_b.json", paths["/a/b"])
+	assert.Contains(t, paths, "/a_b")
+	assert.NotEqual(t, "a_b.json", paths["/a_b"])
+}
+
+func TestRecorder_BinaryBody(t *testing.T) {
+	dir := t.TempDir()
+	rec := New(zaptest.NewLogger(t), dir, Options{})
+
+	body := []byte{0xff, 0xd8, 0xff, 0x00}
+	require.NoError(t, rec.Record(&model.Exchange{
+		Method:          "GET",
+		Path:            "/logo",
+		Status:          http.StatusOK,
+		ResponseHeaders: http.Header{"Content-Type": {"image/jpeg"}},
+		ResponseBody:    body,
+	}))
+
+	templates, err := builder.NewTemplateBuilder(zaptest.NewLogger(t)).Build(dir)
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+
+	file := templates[0].Handle[0].SetResponseTemplate.SetFile
+	assert.Equal(t, dir, filepath.Dir(file))
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, body, data)
+}
+
+func TestRecorder_ReplayBodies(t *testing.T) {
+	dir := t.TempDir()
+	rec := New(zaptest.NewLogger(t), dir, Options{})
+
+	exchanges := []struct {
+		path, contentType, body string
+	}{
+		{"/orders", "application/json; charset=utf-8", `{"item": "book"}`},
+		{"/search", "application/x-www-form-urlencoded", "tag=a&tag=b&page=1"},
+	}
+	for _, exchange := range exchanges {
+		require.NoError(t, rec.Record(&model.Exchange{
+			Method:  "POST",
+			Path:    exchange.path,
+			Headers: http.Header{"Content-Type": {exchange.contentType}},
+			Body:    []byte(exchange.body),
+			Status:  http.StatusCreated,
+		}))
+	}
+
+	templates, err := builder.NewTemplateBuilder(zaptest.NewLogger(t)).Build(dir)
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+
+	for _, template := range templates {
+		require.NotNil(t, template.Handle[0].MatchRequestTemplate.MustBody, template.Path)
+	}
+
+	serve := func(path, contentType, body string) int {
+		for i := range templates {
+			if templates[i].Path != path {
+				continue
+			}
+			route := builder.BuildRoutes(zap.NewNop(), nopProcessLogger{}, scenario.New(), builder.Options{}, &templates[i])
+			req := httptest.NewRequest("POST", path, strings.NewReader(body))
+			req.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+			route.Handler(model.POST).ServeHTTP(w, req)
+			return w.Code
+		}
+		return 0
+	}
+
+	for _, exchange := range exchanges {
+		assert.Equal(t, http.StatusCreated, serve(exchange.path, exchange.contentType, exchange.body), exchange.path)
+	}
+	assert.Equal(t, http.StatusNotFound, serve("/orders", "application/json; charset=utf-8", `{"item": "pen"}`))
+	assert.Equal(t, http.StatusNotFound, serve("/search", "application/x-www-form-urlencoded", "tag=a&tag=c&page=1"))
+}
+
+func TestRecorder_UnsupportedMethod(t *testing.T) {
+	rec := New(zaptest.NewLogger(t), t.TempDir(), Options{})
+	assert.Error(t, rec.Record(&model.Exchange{Method: "OPTIONS", Path: "/"}))
+}
@@ -0,0 +1,152 @@
+package render
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mockium/internal/service/random"
+	"strconv"
+	"text/template"
+	"time"
+)
+
+// funcs are the helper functions available in response templates.
+var funcs = template.FuncMap{
+	"uuid":         newUUID,
+	"now":          time.Now,
+	"date":         date,
+	"randInt":      randInt,
+	"base64Encode": base64Encode,
+	"base64Decode": base64Decode,
+	"json":         toJSON,
+	"default":      defaultValue,
+	"add":          arithmetic(func(a, b float64) float64 { return a + b }),
+	"sub":          arithmetic(func(a, b float64) float64 { return a - b }),
+	"mul":          arithmetic(func(a, b float64) float64 { return a * b }),
+	"div":          div,
+	"mod":          mod,
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// date formats the time with the Go layout, e.g. {{now | date "2006-01-02"}}.
+// Besides the Go layouts, "unix" and "unixMilli" format the time as a timestamp.
+func date(layout string, t time.Time) string {
+	switch layout {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unixMilli":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	default:
+		return t.Format(layout)
+	}
+}
+
+// randInt returns a random integer in [min, max).
+func randInt(min, max int) (int, error) {
+	if max <= min {
+		return 0, fmt.Errorf("randInt: max %d must be greater than min %d", max, min)
+	}
+	return min + random.Intn(max-min), nil
+}
+
+func base64Encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func base64Decode(s string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// toJSON encodes the value as JSON, e.g. {{json .Body.items}}.
+func toJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// defaultValue returns def if the value is missing or empty, e.g. {{.Query.Get "page" | default "1"}}.
+func defaultValue(def, v any) any {
+	if v == nil || v == "" {
+		return def
+	}
+	return v
+}
+
+// arithmetic wraps a binary operation on numbers. The operands may be any numeric
+// type or a numeric string, as query parameters and headers are strings.
+// The result is an integer when it has no fractional part.
+func arithmetic(op func(a, b float64) float64) func(a, b any) (any, error) {
+	return func(a, b any) (any, error) {
+		x, err := toFloat(a)
+		if err != nil {
+			return nil, err
+		}
+		y, err := toFloat(b)
+		if err != nil {
+			return nil, err
+		}
+		return number(op(x, y)), nil
+	}
+}
+
+func div(a, b any) (any, error) {
+	y, err := toFloat(b)
+	if err != nil {
+		return nil, err
+	}
+	if y == 0 {
+		return nil, fmt.Errorf("div: division by zero")
+	}
+	return arithmetic(func(a, b float64) float64 { return a / b })(a, y)
+}
+
+func mod(a, b any) (any, error) {
+	y, err := toFloat(b)
+	if err != nil {
+		return nil, err
+	}
+	if y == 0 {
+		return nil, fmt.Errorf("mod: division by zero")
+	}
+	return arithmetic(math.Mod)(a, y)
+}
+
+func toFloat(v any) (float64, error) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	case json.Number:
+		return n.Float64()
+	case string:
+		return strconv.ParseFloat(n, 64)
+	default:
+		return 0, fmt.Errorf("unexpected number %v of type %T", v, v)
+	}
+}
+
+func number(f float64) any {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int64(f)
+	}
+	return f
+}
@@ -0,0 +1,116 @@
+// Package render renders response templates written in Go text/template syntax
+// against the data of the incoming request.
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"mockium/internal/service/constants"
+	"mockium/internal/service/formdata"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/gorilla/mux"
+)
+
+// Data is the request data available in response templates.
+type Data struct {
+	Method  string                   // HTTP method, e.g. {{.Method}}.
+	URL     string                   // Request URL, e.g. {{.URL}}.
+	Path    map[string]string        // Path variables, e.g. {{.Path.id}}.
+	Query   url.Values               // Query parameters, e.g. {{.Query.Get "page"}}.
+	Headers http.Header              // Request headers, e.g. {{.Headers.Get "X-Request-Id"}}.
+	Form    url.Values               // Form parameters of a form-urlencoded or multipart body, e.g. {{.Form.Get "username"}}.
+	Files   map[string]formdata.File // First file uploaded with each field of a multipart body, e.g. {{.Files.avatar.Name}}.
+	Body    any                      // Parsed JSON body, e.g. {{.Body.user.name}}, nil if the body is not JSON.
+}
+
+// NewData collects the template data from the request.
+// The request body is read and restored, so it can still be read afterwards.
+//
+// Parameters:
+//   - req: the incoming HTTP request.
+//
+// Returns the template data or an error if the body cannot be read.
+func NewData(req *http.Request) (*Data, error) {
+	data := &Data{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Path:    mux.Vars(req),
+		Query:   req.URL.Query(),
+		Headers: req.Header,
+		Form:    req.PostForm,
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return data, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch {
+	case mediaType == constants.ContentTypeFormURLEncoded && data.Form == nil:
+		if data.Form, err = url.ParseQuery(string(body)); err != nil {
+			return nil, err
+		}
+	case mediaType == constants.ContentTypeFormData:
+		form, err := formdata.ParseRequest(req)
+		if err != nil {
+			return nil, err
+		}
+
+		data.Form = form.Values
+		data.Files = make(map[string]formdata.File, len(form.Files))
+		for name := range form.Files {
+			data.Files[name], _ = form.File(name)
+		}
+	case len(body) > 0:
+		// Any body that is valid JSON is exposed, whatever the Content-Type says.
+		var parsed any
+		if json.Unmarshal(body, &parsed) == nil {
+			data.Body = parsed
+		}
+	}
+
+	return data, nil
+}
+
+// Parse parses a template with the helper functions available.
+//
+// Parameters:
+//   - text: the template text.
+//
+// Returns the parsed template or a syntax error.
+func Parse(text string) (*template.Template, error) {
+	return template.New("").Funcs(funcs).Parse(text)
+}
+
+// IsTemplate reports whether the text contains template actions and must be rendered.
+func IsTemplate(text string) bool {
+	return strings.Contains(text, "{{")
+}
+
+// Execute renders a parsed template with the request data.
+//
+// Parameters:
+//   - tmpl: the template returned by Parse.
+//   - data: the request data.
+//
+// Returns the rendered text or an execution error.
+func Execute(tmpl *template.Template, data *Data) (string, error) {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,78 @@
+package render
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func render(t *testing.T, text string, data *Data) string {
+	t.Helper()
+
+	tmpl, err := Parse(text)
+	require.NoError(t, err)
+
+	out, err := Execute(tmpl, data)
+	require.NoError(t, err)
+	return out
+}
+
+func TestNewData_JSON(t *testing.T) {
+	req := httptest.NewRequest("POST", "/users/42?page=2", strings.NewReader(`{"user":{"name":"test"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-Id", "abc")
+	req = mux.SetURLVars(req, map[string]string{"id": "42"})
+
+	data, err := NewData(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "POST 42 2 abc test",
+		render(t, `{{.Method}} {{.Path.id}} {{.Query.Get "page"}} {{.Headers.Get "x-request-id"}} {{.Body.user.name}}`, data))
+
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"user":{"name":"test"}}`, string(body))
+}
+
+func TestNewData_Form(t *testing.T) {
+	req := httptest.NewRequest("POST", "/login", strings.NewReader("username=test"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	data, err := NewData(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "test", render(t, `{{.Form.Get "username"}}`, data))
+	assert.Nil(t, data.Body)
+}
+
+func TestFuncs(t *testing.T) {
+	data := &Data{Body: map[string]any{"price": 2.5, "count": float64(4), "items": []any{"a", "b"}}}
+
+	assert.Equal(t, "10", render(t, `{{mul .Body.price .Body.count}}`, data))
+	assert.Equal(t, "6", render(t, `{{add .Body.count "2"}}`, data))
+	assert.Equal(t, "1.5", render(t, `{{sub .Body.price 1}}`, data))
+	assert.Equal(t, "2", render(t, `{{div .Body.count 2}}`, data))
+	assert.Equal(t, "1", render(t, `{{mod 7 3}}`, data))
+	assert.Equal(t, `["a","b"]`, render(t, `{{json .Body.items}}`, data))
+	assert.Equal(t, "dGVzdA== test", render(t, `{{base64Encode "test"}} {{base64Decode "dGVzdA=="}}`, data))
+	assert.Equal(t, "fallback", render(t, `{{.Body.missing | default "fallback"}}`, data))
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, render(t, `{{uuid}}`, data))
+	assert.Regexp(t, `^\d{4}-\d{2}-\d{2}$`, render(t, `{{now | date "2006-01-02"}}`, data))
+	assert.Regexp(t, `^\d+$`, render(t, `{{now | date "unix"}}`, data))
+	assert.Contains(t, []string{"5", "6", "7"}, render(t, `{{randInt 5 8}}`, data))
+
+	tmpl, err := Parse(`{{div 1 0}}`)
+	require.NoError(t, err)
+	_, err = Execute(tmpl, data)
+	assert.Error(t, err)
+}
+
+func TestParse_Error(t *testing.T) {
+	_, err := Parse(`{{.Body.name`)
+	assert.Error(t, err)
+}
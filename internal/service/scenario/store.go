@@ -0,0 +1,99 @@
+package scenario
+
+import "sync"
+
+// StartedState is the state every scenario is in before its first transition
+// and after a reset.
+const StartedState = "Started"
+
+// Store keeps the current state of the named scenarios shared by all handles.
+//
+// Handlers evaluating and changing states atomically hold the store lock
+// (Lock/Unlock) and use State and SetState. All other methods lock the store
+// themselves.
+type Store struct {
+	mu     sync.Mutex
+	states map[string]string // Current state by scenario name.
+}
+
+// New creates a new, empty scenario Store.
+func New() *Store {
+	return &Store{
+		states: make(map[string]string),
+	}
+}
+
+// Lock acquires the store lock for a State/SetState transaction.
+func (inst *Store) Lock() { inst.mu.Lock() }
+
+// Unlock releases the store lock.
+func (inst *Store) Unlock() { inst.mu.Unlock() }
+
+// State returns the current state of the scenario, StartedState if it has not
+// been changed yet. The caller must hold the store lock.
+func (inst *Store) State(name string) string {
+	if state, ok := inst.states[name]; ok {
+		return state
+	}
+	return StartedState
+}
+
+// SetState moves the scenario to the given state. The caller must hold the store lock.
+func (inst *Store) SetState(name, state string) {
+	inst.states[name] = state
+}
+
+// Register makes the scenario known to the store in StartedState,
+// keeping the current state of an already known scenario.
+func (inst *Store) Register(name string) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if _, ok := inst.states[name]; !ok {
+		inst.states[name] = StartedState
+	}
+}
+
+// States returns a copy of the current states of all known scenarios.
+func (inst *Store) States() map[string]string {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	states := make(map[string]string, len(inst.states))
+	for name, state := range inst.states {
+		states[name] = state
+	}
+	return states
+}
+
+// Update moves the scenario to the given state.
+func (inst *Store) Update(name, state string) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	inst.states[name] = state
+}
+
+// Reset moves the scenario back to StartedState.
+//
+// Returns false if the scenario is unknown.
+func (inst *Store) Reset(name string) bool {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if _, ok := inst.states[name]; !ok {
+		return false
+	}
+	inst.states[name] = StartedState
+	return true
+}
+
+// ResetAll moves all scenarios back to StartedState.
+func (inst *Store) ResetAll() {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	for name := range inst.states {
+		inst.states[name] = StartedState
+	}
+}
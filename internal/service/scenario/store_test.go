@@ -0,0 +1,37 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore_States(t *testing.T) {
+	store := New()
+	store.Register("order")
+
+	store.Lock()
+	assert.Equal(t, StartedState, store.State("order"))
+	assert.Equal(t, StartedState, store.State("unknown"))
+	store.SetState("order", "pending")
+	store.Unlock()
+
+	store.Register("order")
+	assert.Equal(t, map[string]string{"order": "pending"}, store.States())
+
+	store.Update("payment", "done")
+	assert.Equal(t, map[string]string{"order": "pending", "payment": "done"}, store.States())
+}
+
+func TestStore_Reset(t *testing.T) {
+	store := New()
+	store.Update("order", "paid")
+	store.Update("payment", "done")
+
+	assert.True(t, store.Reset("order"))
+	assert.False(t, store.Reset("unknown"))
+	assert.Equal(t, map[string]string{"order": StartedState, "payment": "done"}, store.States())
+
+	store.ResetAll()
+	assert.Equal(t, map[string]string{"order": StartedState, "payment": StartedState}, store.States())
+}
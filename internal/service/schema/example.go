@@ -0,0 +1,157 @@
+package schema
+
+import (
+	"math"
+	"strings"
+)
+
+// maxExampleDepth limits the nesting of synthesized examples.
+const maxExampleDepth = 16
+
+// formatExamples are the example strings of the well-known string formats.
+var formatExamples = map[string]string{
+	"date-time": "2024-01-01T00:00:00Z",
+	"date":      "2024-01-01",
+	"time":      "00:00:00Z",
+	"uuid":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"email":     "user@example.com",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"byte":      "ZXhhbXBsZQ==",
+	"password":  "password",
+}
+
+// Example returns an example value of the schema. The example, default, const or first enum
+// value of the schema is used if there is one, otherwise the value is synthesized
+// from the type, properties and constraints. A recursive reference is expanded once,
+// the properties that would repeat it are left out.
+//
+// Parameters:
+//   - s: the schema.
+//   - refs: the schemas referenced by "$ref".
+//
+// Returns the example value, nil if no value can be derived.
+func Example(s *Schema, refs Refs) any {
+	return example(s, refs, 0, map[string]bool{})
+}
+
+// example synthesizes the example, expanding keeps the references being expanded.
+func example(s *Schema, refs Refs, depth int, expanding map[string]bool) any {
+	if s != nil && s.Ref != "" {
+		if expanding[s.Ref] {
+			return nil
+		}
+		expanding[s.Ref] = true
+		defer delete(expanding, s.Ref)
+	}
+
+	s, err := refs.Resolve(s)
+	if err != nil || s == nil || depth > maxExampleDepth {
+		return nil
+	}
+
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case len(s.Examples) > 0:
+		return s.Examples[0]
+	case s.Default != nil:
+		return s.Default
+	case s.Const != nil:
+		return s.Const
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.AllOf) > 0:
+		return allOfExample(s, refs, depth, expanding)
+	case len(s.OneOf) > 0:
+		return example(s.OneOf[0], refs, depth, expanding)
+	case len(s.AnyOf) > 0:
+		return example(s.AnyOf[0], refs, depth, expanding)
+	}
+
+	switch typeOf(s) {
+	case TypeObject:
+		object := make(map[string]any, len(s.Properties))
+		for name, property := range s.Properties {
+			if value := example(property, refs, depth+1, expanding); value != nil {
+				object[name] = value
+			}
+		}
+		return object
+	case TypeArray:
+		size := 1
+		if s.MinItems != nil && *s.MinItems > size {
+			size = *s.MinItems
+		}
+
+		item := example(s.Items, refs, depth+1, expanding)
+		if item == nil {
+			return []any{}
+		}
+
+		array := make([]any, size)
+		for i := range array {
+			array[i] = item
+		}
+		return array
+	case TypeString:
+		return stringExample(s)
+	case TypeInteger:
+		return int64(numberExample(s, math.Ceil, math.Floor))
+	case TypeNumber:
+		return numberExample(s, func(f float64) float64 { return f }, func(f float64) float64 { return f })
+	case TypeBoolean:
+		return true
+	}
+	return nil
+}
+
+// allOfExample merges the examples of the allOf schemas and of the schema's own properties.
+func allOfExample(s *Schema, refs Refs, depth int, expanding map[string]bool) any {
+	merged := make(map[string]any)
+	own := *s
+	own.AllOf = nil
+
+	for _, part := range append(s.AllOf, &own) {
+		switch value := example(part, refs, depth, expanding).(type) {
+		case map[string]any:
+			for name, item := range value {
+				merged[name] = item
+			}
+		case nil:
+		default:
+			return value
+		}
+	}
+	return merged
+}
+
+func stringExample(s *Schema) string {
+	value, ok := formatExamples[s.Format]
+	if !ok {
+		value = "string"
+	}
+
+	if s.MinLength != nil && len(value) < *s.MinLength {
+		value += strings.Repeat("x", *s.MinLength-len(value))
+	}
+	if s.MaxLength != nil && len(value) > *s.MaxLength {
+		value = value[:*s.MaxLength]
+	}
+	return value
+}
+
+// numberExample returns zero moved into the [minimum, maximum] range, rounded with the given functions.
+func numberExample(s *Schema, roundUp, roundDown func(float64) float64) float64 {
+	value := 0.0
+	if s.Minimum != nil && value < *s.Minimum {
+		value = roundUp(*s.Minimum)
+	}
+	if s.Maximum != nil && value > *s.Maximum {
+		value = roundDown(*s.Maximum)
+	}
+	return value
+}
@@ -0,0 +1,70 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExample(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   any
+	}{
+		{"example", `{"type": "string", "example": "abc"}`, "abc"},
+		{"enum", `{"type": "string", "enum": ["a", "b"]}`, "a"},
+		{"default", `{"type": "integer", "default": 5}`, float64(5)},
+		{"format", `{"type": "string", "format": "date"}`, "2024-01-01"},
+		{"min length", `{"type": "string", "minLength": 8}`, "stringxx"},
+		{"max length", `{"type": "string", "maxLength": 3}`, "str"},
+		{"integer minimum", `{"type": "integer", "minimum": 1.5}`, int64(2)},
+		{"number maximum", `{"type": "number", "maximum": -0.5}`, -0.5},
+		{"boolean", `{"type": "boolean"}`, true},
+		{"nullable list", `{"type": ["null", "boolean"]}`, true},
+		{"array", `{"type": "array", "minItems": 2, "items": {"type": "integer"}}`, []any{int64(0), int64(0)}},
+		{"object", `{"properties": {"id": {"type": "integer"}, "tags": {"items": {"type": "string"}}}}`,
+			map[string]any{"id": int64(0), "tags": []any{"string"}}},
+		{"oneOf", `{"oneOf": [{"type": "boolean"}, {"type": "string"}]}`, true},
+		{"allOf", `{"allOf": [{"properties": {"a": {"type": "boolean"}}}, {"properties": {"b": {"type": "boolean"}}}]}`,
+			map[string]any{"a": true, "b": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Schema{}
+			require.NoError(t, json.Unmarshal([]byte(tt.schema), s))
+			assert.Equal(t, tt.want, Example(s, nil))
+		})
+	}
+}
+
+func TestExample_Refs(t *testing.T) {
+	root := &Schema{}
+	require.NoError(t, yaml.Unmarshal([]byte(`
+$ref: '#/$defs/Node'
+$defs:
+  Node:
+    type: object
+    additionalProperties: false
+    properties:
+      name:
+        type: string
+      child:
+        $ref: '#/$defs/Node'
+`), root))
+
+	refs := Refs{}
+	refs.AddDefs("#/$defs/", root.Defs)
+
+	example, ok := Example(root, refs).(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{"name": "string"}, example)
+	assert.True(t, refs["#/$defs/Node"].AdditionalProperties.Forbidden)
+
+	_, err := refs.Resolve(&Schema{Ref: "#/$defs/Missing"})
+	assert.Error(t, err)
+}
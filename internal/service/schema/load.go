@@ -0,0 +1,88 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads a schema given inline or as a file.
+//
+// Parameters:
+//   - source: the schema object decoded from JSON or YAML, or the path of a JSON or YAML schema file.
+//
+// Returns the schema together with the refs of its "$defs", or an error if the schema cannot be read,
+// refers to an unknown schema or has an invalid pattern.
+func Load(source any) (*Schema, Refs, error) {
+	s := &Schema{}
+	switch v := source.(type) {
+	case string:
+		data, err := os.ReadFile(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := yaml.Unmarshal(data, s); err != nil {
+			return nil, nil, fmt.Errorf("schema file '%s': %w", v, err)
+		}
+	case map[string]any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := json.Unmarshal(data, s); err != nil {
+			return nil, nil, fmt.Errorf("schema: %w", err)
+		}
+	default:
+		return nil, nil, fmt.Errorf("schema must be an object or a file path, got %T", source)
+	}
+
+	refs := make(Refs, len(s.Defs))
+	refs.AddDefs("#/$defs/", s.Defs)
+
+	if err := check(s, refs, make(map[*Schema]bool)); err != nil {
+		return nil, nil, err
+	}
+	return s, refs, nil
+}
+
+// check verifies that the references of the schema and its subschemas are known
+// and that their patterns compile.
+func check(s *Schema, refs Refs, seen map[*Schema]bool) error {
+	if s == nil || seen[s] {
+		return nil
+	}
+	seen[s] = true
+
+	if _, err := refs.Resolve(s); err != nil {
+		return err
+	}
+
+	if s.Pattern != "" {
+		if _, err := compilePattern(s.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern '%s': %w", s.Pattern, err)
+		}
+	}
+
+	subschemas := []*Schema{s.Items}
+	for _, list := range [][]*Schema{s.AllOf, s.OneOf, s.AnyOf} {
+		subschemas = append(subschemas, list...)
+	}
+	for _, property := range s.Properties {
+		subschemas = append(subschemas, property)
+	}
+	for _, def := range s.Defs {
+		subschemas = append(subschemas, def)
+	}
+	if s.AdditionalProperties != nil {
+		subschemas = append(subschemas, s.AdditionalProperties.Schema)
+	}
+
+	for _, sub := range subschemas {
+		if err := check(sub, refs, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
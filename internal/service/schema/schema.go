@@ -0,0 +1,158 @@
+// Package schema implements the subset of JSON Schema used by OpenAPI documents
+// and by body matching: types, objects, arrays, enums and value constraints.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JSON Schema type names.
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeBoolean = "boolean"
+	TypeArray   = "array"
+	TypeObject  = "object"
+	TypeNull    = "null"
+)
+
+// Schema is a JSON Schema. Both the JSON Schema and the OpenAPI 3.0 dialects are accepted,
+// e.g. the list of types as well as "nullable".
+type Schema struct {
+	Ref                  string             `yaml:"$ref" json:"$ref"`
+	Defs                 map[string]*Schema `yaml:"$defs" json:"$defs"`
+	Type                 Types              `yaml:"type" json:"type"`
+	Format               string             `yaml:"format" json:"format"`
+	Nullable             bool               `yaml:"nullable" json:"nullable"`
+	Enum                 []any              `yaml:"enum" json:"enum"`
+	Const                any                `yaml:"const" json:"const"`
+	Properties           map[string]*Schema `yaml:"properties" json:"properties"`
+	Required             []string           `yaml:"required" json:"required"`
+	AdditionalProperties *Additional        `yaml:"additionalProperties" json:"additionalProperties"`
+	Items                *Schema            `yaml:"items" json:"items"`
+	Pattern              string             `yaml:"pattern" json:"pattern"`
+	Minimum              *float64           `yaml:"minimum" json:"minimum"`
+	Maximum              *float64           `yaml:"maximum" json:"maximum"`
+	MinLength            *int               `yaml:"minLength" json:"minLength"`
+	MaxLength            *int               `yaml:"maxLength" json:"maxLength"`
+	MinItems             *int               `yaml:"minItems" json:"minItems"`
+	MaxItems             *int               `yaml:"maxItems" json:"maxItems"`
+	AllOf                []*Schema          `yaml:"allOf" json:"allOf"`
+	OneOf                []*Schema          `yaml:"oneOf" json:"oneOf"`
+	AnyOf                []*Schema          `yaml:"anyOf" json:"anyOf"`
+	Default              any                `yaml:"default" json:"default"`
+	Example              any                `yaml:"example" json:"example"`
+	Examples             []any              `yaml:"examples" json:"examples"`
+}
+
+// Types is the "type" keyword, a single type name or a list of them.
+type Types []string
+
+func (inst *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*inst = Types{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(inst))
+}
+
+func (inst *Types) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*inst = Types{value.Value}
+		return nil
+	}
+	return value.Decode((*[]string)(inst))
+}
+
+// Has reports whether the type is listed.
+func (inst Types) Has(name string) bool {
+	for _, t := range inst {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Additional is the "additionalProperties" keyword, a boolean or a schema
+// the additional properties must satisfy.
+type Additional struct {
+	Forbidden bool    // additionalProperties: false
+	Schema    *Schema // Schema of the additional properties, nil allows any value.
+}
+
+func (inst *Additional) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(data, &allowed); err == nil {
+		inst.Forbidden = !allowed
+		return nil
+	}
+	return json.Unmarshal(data, &inst.Schema)
+}
+
+func (inst *Additional) UnmarshalYAML(value *yaml.Node) error {
+	var allowed bool
+	if value.Kind == yaml.ScalarNode && value.Decode(&allowed) == nil {
+		inst.Forbidden = !allowed
+		return nil
+	}
+	return value.Decode(&inst.Schema)
+}
+
+// Refs are the schemas a "$ref" may point to, by their reference,
+// e.g. "#/components/schemas/User" or "#/$defs/User".
+type Refs map[string]*Schema
+
+// AddDefs adds the schemas defined under the prefix, e.g. the "#/$defs/" of a schema.
+func (inst Refs) AddDefs(prefix string, defs map[string]*Schema) {
+	for name, def := range defs {
+		inst[prefix+name] = def
+	}
+}
+
+// Resolve follows the "$ref" of the schema.
+//
+// Parameters:
+//   - s: the schema, possibly a reference.
+//
+// Returns the referenced schema, or an error if the reference is unknown or circular.
+func (inst Refs) Resolve(s *Schema) (*Schema, error) {
+	for seen := 0; s != nil && s.Ref != ""; seen++ {
+		if seen > len(inst) {
+			return nil, fmt.Errorf("circular reference '%s'", s.Ref)
+		}
+
+		target, ok := inst[s.Ref]
+		if !ok {
+			return nil, fmt.Errorf("unknown reference '%s'", s.Ref)
+		}
+		s = target
+	}
+	return s, nil
+}
+
+// typeOf returns the schema type, inferred from the keywords if "type" is missing.
+func typeOf(s *Schema) string {
+	for _, t := range s.Type {
+		if t != TypeNull {
+			return t
+		}
+	}
+
+	switch {
+	case s.Properties != nil || s.AdditionalProperties != nil || len(s.Required) > 0:
+		return TypeObject
+	case s.Items != nil:
+		return TypeArray
+	case s.Pattern != "" || s.MinLength != nil || s.MaxLength != nil || s.Format != "":
+		return TypeString
+	case s.Minimum != nil || s.Maximum != nil:
+		return TypeNumber
+	}
+	return ""
+}
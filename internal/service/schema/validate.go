@@ -0,0 +1,290 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Violation is a value that does not satisfy its schema.
+type Violation struct {
+	Path    string // JSON pointer of the value, e.g. "/items/0/sku", empty for the root value.
+	Message string // Description of the violated constraint.
+}
+
+func (inst Violation) String() string {
+	if inst.Path == "" {
+		return inst.Message
+	}
+	return inst.Path + ": " + inst.Message
+}
+
+// patterns caches the compiled "pattern" regular expressions.
+var patterns sync.Map
+
+// uuidPattern matches the textual form of a UUID.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Validate checks a decoded JSON value against the schema.
+//
+// The value must be decoded the way encoding/json decodes into any: numbers as float64,
+// objects as map[string]any and arrays as []any.
+//
+// Parameters:
+//   - s: the schema.
+//   - refs: the schemas referenced by "$ref".
+//   - value: the value to validate.
+//
+// Returns every violation found, nil if the value is valid.
+func Validate(s *Schema, refs Refs, value any) []Violation {
+	return validate(s, refs, value, "")
+}
+
+func validate(s *Schema, refs Refs, value any, path string) []Violation {
+	s, err := refs.Resolve(s)
+	if err != nil {
+		return []Violation{{Path: path, Message: err.Error()}}
+	}
+	if s == nil {
+		return nil
+	}
+
+	if value == nil && (s.Nullable || s.Type.Has(TypeNull)) {
+		return nil
+	}
+
+	var violations []Violation
+	add := func(format string, args ...any) {
+		violations = append(violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.Type) > 0 && !matchesType(s.Type, value) {
+		add("must be %s", strings.Join(s.Type, " or "))
+		return violations
+	}
+
+	if len(s.Enum) > 0 && !containsValue(s.Enum, value) {
+		add("must be one of %v", s.Enum)
+	}
+	if s.Const != nil && !equalValues(s.Const, value) {
+		add("must be %v", s.Const)
+	}
+
+	switch v := value.(type) {
+	case string:
+		violations = append(violations, validateString(s, v, path)...)
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			add("must be greater than or equal to %v", *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			add("must be less than or equal to %v", *s.Maximum)
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			add("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			add("must have at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				violations = append(violations, validate(s.Items, refs, item, fmt.Sprintf("%s/%d", path, i))...)
+			}
+		}
+	case map[string]any:
+		violations = append(violations, validateObject(s, refs, v, path)...)
+	}
+
+	for _, part := range s.AllOf {
+		violations = append(violations, validate(part, refs, value, path)...)
+	}
+
+	if len(s.AnyOf) > 0 && countValid(s.AnyOf, refs, value) == 0 {
+		add("must match at least one schema of anyOf")
+	}
+	if len(s.OneOf) > 0 && countValid(s.OneOf, refs, value) != 1 {
+		add("must match exactly one schema of oneOf")
+	}
+
+	return violations
+}
+
+func validateString(s *Schema, value, path string) []Violation {
+	var violations []Violation
+	add := func(format string, args ...any) {
+		violations = append(violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	length := utf8.RuneCountInString(value)
+	if s.MinLength != nil && length < *s.MinLength {
+		add("must be at least %d characters long", *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		add("must be at most %d characters long", *s.MaxLength)
+	}
+
+	if s.Pattern != "" {
+		re, err := compilePattern(s.Pattern)
+		if err != nil {
+			add("invalid pattern '%s': %s", s.Pattern, err)
+		} else if !re.MatchString(value) {
+			add("must match pattern '%s'", s.Pattern)
+		}
+	}
+
+	if !validFormat(s.Format, value) {
+		add("must be a valid %s", s.Format)
+	}
+
+	return violations
+}
+
+func validateObject(s *Schema, refs Refs, value map[string]any, path string) []Violation {
+	var violations []Violation
+
+	for _, name := range s.Required {
+		if _, ok := value[name]; !ok {
+			violations = append(violations, Violation{Path: path, Message: fmt.Sprintf("missing required property '%s'", name)})
+		}
+	}
+
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propertyPath := path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+		if property, ok := s.Properties[name]; ok {
+			violations = append(violations, validate(property, refs, value[name], propertyPath)...)
+			continue
+		}
+
+		if s.AdditionalProperties == nil {
+			continue
+		}
+		if s.AdditionalProperties.Forbidden {
+			violations = append(violations, Violation{Path: path, Message: fmt.Sprintf("unexpected property '%s'", name)})
+			continue
+		}
+		violations = append(violations, validate(s.AdditionalProperties.Schema, refs, value[name], propertyPath)...)
+	}
+
+	return violations
+}
+
+// matchesType reports whether the value is of one of the types.
+func matchesType(types Types, value any) bool {
+	for _, t := range types {
+		switch v := value.(type) {
+		case nil:
+			if t == TypeNull {
+				return true
+			}
+		case string:
+			if t == TypeString {
+				return true
+			}
+		case bool:
+			if t == TypeBoolean {
+				return true
+			}
+		case float64:
+			if t == TypeNumber || (t == TypeInteger && v == math.Trunc(v)) {
+				return true
+			}
+		case []any:
+			if t == TypeArray {
+				return true
+			}
+		case map[string]any:
+			if t == TypeObject {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func countValid(schemas []*Schema, refs Refs, value any) int {
+	valid := 0
+	for _, s := range schemas {
+		if len(validate(s, refs, value, "")) == 0 {
+			valid++
+		}
+	}
+	return valid
+}
+
+func containsValue(values []any, value any) bool {
+	for _, candidate := range values {
+		if equalValues(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues compares the values, numbers of any type by their value,
+// as schemas decoded from YAML hold integers.
+func equalValues(a, b any) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+// validFormat checks the well-known string formats, other formats are not checked.
+func validFormat(format, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	case "uuid":
+		return uuidPattern.MatchString(value)
+	case "email":
+		address, err := mail.ParseAddress(value)
+		return err == nil && address.Address == value
+	}
+	return true
+}
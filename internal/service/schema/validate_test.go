@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		want   []Violation
+	}{
+		{"valid object", `{"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}}}`, `{"id": 1}`, nil},
+		{"type", `{"type": "string"}`, `1`, []Violation{{"", "must be string"}}},
+		{"integer", `{"type": "integer"}`, `1.5`, []Violation{{"", "must be integer"}}},
+		{"nullable", `{"type": "string", "nullable": true}`, `null`, nil},
+		{"null type", `{"type": ["string", "null"]}`, `null`, nil},
+		{"enum", `{"enum": ["a", "b"]}`, `"c"`, []Violation{{"", "must be one of [a b]"}}},
+		{"minimum", `{"type": "number", "minimum": 2}`, `1`, []Violation{{"", "must be greater than or equal to 2"}}},
+		{"maximum", `{"type": "number", "maximum": 2}`, `3`, []Violation{{"", "must be less than or equal to 2"}}},
+		{"min length", `{"type": "string", "minLength": 3}`, `"ab"`, []Violation{{"", "must be at least 3 characters long"}}},
+		{"max length", `{"type": "string", "maxLength": 1}`, `"ab"`, []Violation{{"", "must be at most 1 characters long"}}},
+		{"pattern", `{"type": "string", "pattern": "^[a-z]+$"}`, `"A1"`, []Violation{{"", "must match pattern '^[a-z]+$'"}}},
+		{"format", `{"type": "string", "format": "uuid"}`, `"abc"`, []Violation{{"", "must be a valid uuid"}}},
+		{"required", `{"type": "object", "required": ["id"]}`, `{}`, []Violation{{"", "missing required property 'id'"}}},
+		{"nested", `{"properties": {"items": {"items": {"properties": {"sku": {"type": "string"}}}}}}`,
+			`{"items": [{"sku": "a"}, {"sku": 1}]}`, []Violation{{"/items/1/sku", "must be string"}}},
+		{"additional forbidden", `{"properties": {"id": {}}, "additionalProperties": false}`, `{"id": 1, "x": 2}`,
+			[]Violation{{"", "unexpected property 'x'"}}},
+		{"additional schema", `{"additionalProperties": {"type": "integer"}}`, `{"x": "a"}`, []Violation{{"/x", "must be integer"}}},
+		{"min items", `{"type": "array", "minItems": 1}`, `[]`, []Violation{{"", "must have at least 1 items"}}},
+		{"allOf", `{"allOf": [{"required": ["a"]}, {"required": ["b"]}]}`, `{"a": 1}`, []Violation{{"", "missing required property 'b'"}}},
+		{"anyOf", `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, `true`, []Violation{{"", "must match at least one schema of anyOf"}}},
+		{"oneOf", `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`, `1`, []Violation{{"", "must match exactly one schema of oneOf"}}},
+		{"ref", `{"$ref": "#/$defs/id", "$defs": {"id": {"type": "integer"}}}`, `"a"`, []Violation{{"", "must be integer"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Schema{}
+			require.NoError(t, json.Unmarshal([]byte(tt.schema), s))
+			refs := Refs{}
+			refs.AddDefs("#/$defs/", s.Defs)
+
+			var value any
+			require.NoError(t, json.Unmarshal([]byte(tt.value), &value))
+			assert.Equal(t, tt.want, Validate(s, refs, value))
+		})
+	}
+}
+
+func TestValidate_YAMLEnum(t *testing.T) {
+	s := &Schema{}
+	require.NoError(t, yaml.Unmarshal([]byte("enum: [1, 2]"), s))
+
+	assert.Empty(t, Validate(s, nil, float64(2)))
+	assert.Len(t, Validate(s, nil, float64(3)), 1)
+}
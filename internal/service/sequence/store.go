@@ -0,0 +1,88 @@
+package sequence
+
+import (
+	"mockium/internal/service"
+	"sync"
+)
+
+// Store keeps the response lists (SetResponses) of the handles of all templates,
+// so that their position can be reset at runtime.
+type Store struct {
+	mu        sync.Mutex
+	sequences map[string]map[int]service.ResponseSequence // Response lists by template ID and handle index.
+}
+
+// New creates a new, empty sequence Store.
+func New() *Store {
+	return &Store{
+		sequences: make(map[string]map[int]service.ResponseSequence),
+	}
+}
+
+// Register sets the response lists of the handles of a template by handle index,
+// replacing the ones built before the template was rebuilt; the lists of removed
+// handles are dropped. A list unchanged since the last build continues from its position.
+func (inst *Store) Register(template string, sequences map[int]service.ResponseSequence) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	previous := inst.sequences[template]
+	for handle, sequence := range sequences {
+		if old, ok := previous[handle]; ok {
+			sequence.Continue(old)
+		}
+	}
+
+	if len(sequences) == 0 {
+		delete(inst.sequences, template)
+		return
+	}
+	inst.sequences[template] = sequences
+}
+
+// Retain drops the response lists of the templates missing from the list, e.g. deleted ones.
+func (inst *Store) Retain(templates []string) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	keep := make(map[string]struct{}, len(templates))
+	for _, template := range templates {
+		keep[template] = struct{}{}
+	}
+
+	for template := range inst.sequences {
+		if _, ok := keep[template]; !ok {
+			delete(inst.sequences, template)
+		}
+	}
+}
+
+// Reset moves the response lists of the template back to their first response.
+//
+// Returns false if the template has no response lists.
+func (inst *Store) Reset(template string) bool {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	sequences, ok := inst.sequences[template]
+	if !ok {
+		return false
+	}
+
+	for _, sequence := range sequences {
+		sequence.Reset()
+	}
+	return true
+}
+
+// ResetAll moves all response lists back to their first response.
+func (inst *Store) ResetAll() {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	for _, sequences := range inst.sequences {
+		for _, sequence := range sequences {
+			sequence.Reset()
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package sequence
+
+import (
+	"mockium/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type counter struct {
+	resets    int
+	continued service.ResponseSequence
+}
+
+func (inst *counter) Reset() {
+	inst.resets++
+}
+
+func (inst *counter) Continue(previous service.ResponseSequence) {
+	inst.continued = previous
+}
+
+func TestStore_Reset(t *testing.T) {
+	store := New()
+
+	orders, payments, replaced := &counter{}, &counter{}, &counter{}
+	store.Register("orders", map[int]service.ResponseSequence{0: replaced})
+	store.Register("orders", map[int]service.ResponseSequence{0: orders})
+	store.Register("payments", map[int]service.ResponseSequence{1: payments})
+
+	assert.Same(t, replaced, orders.continued)
+	assert.Nil(t, payments.continued)
+
+	assert.True(t, store.Reset("orders"))
+	assert.False(t, store.Reset("unknown"))
+	assert.Equal(t, 1, orders.resets)
+	assert.Equal(t, 0, payments.resets)
+	assert.Equal(t, 0, replaced.resets)
+
+	store.ResetAll()
+	assert.Equal(t, 2, orders.resets)
+	assert.Equal(t, 1, payments.resets)
+}
+
+func TestStore_DropsRemovedLists(t *testing.T) {
+	store := New()
+
+	first, second := &counter{}, &counter{}
+	store.Register("orders", map[int]service.ResponseSequence{0: first, 1: second})
+	store.Register("payments", map[int]service.ResponseSequence{0: &counter{}})
+
+	// The handle 1 was removed from the rebuilt template.
+	rebuilt := &counter{}
+	store.Register("orders", map[int]service.ResponseSequence{0: rebuilt})
+	store.ResetAll()
+	assert.Equal(t, 1, rebuilt.resets)
+	assert.Equal(t, 0, second.resets)
+
+	// A template without response lists anymore.
+	store.Register("orders", nil)
+	assert.False(t, store.Reset("orders"))
+
+	store.Retain([]string{"orders"})
+	assert.False(t, store.Reset("payments"))
+}
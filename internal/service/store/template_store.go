@@ -0,0 +1,275 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"mockium/internal/model"
+	"mockium/internal/service"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Loader reads the on-disk set of templates.
+type Loader func() ([]model.Template, error)
+
+// Publisher applies the current set of templates, e.g. by rebuilding the server routes.
+type Publisher func(templates []model.Template)
+
+// TemplateStore holds the live set of templates. It overlays the templates
+// changed at runtime (added, replaced or deleted) on top of the on-disk set,
+// and publishes the merged set after every change.
+//
+// All methods are safe for concurrent use.
+type TemplateStore struct {
+	log       *zap.Logger                // Logger for store operations.
+	validator service.TemplateValidator  // Validator applied to every change.
+	load      Loader                     // Source of the on-disk templates.
+	publish   Publisher                  // Callback receiving the merged templates.
+	mu        sync.Mutex                 // Guards the fields below.
+	disk      []model.Template           // On-disk templates in load order.
+	overrides map[string]*model.Template // Runtime changes by ID, nil marks a deleted template.
+	added     []string                   // IDs of templates put at runtime, in insertion order.
+	current   []model.Template           // Last published merged set.
+}
+
+// New creates a new TemplateStore.
+//
+// Parameters:
+//   - log: logger used for diagnostics.
+//   - validator: validator applied to the merged set on every change.
+//   - load: function reading the on-disk templates.
+//   - publish: callback applying the merged templates.
+//
+// Returns a pointer to an empty TemplateStore, call Reset to load the on-disk set.
+func New(log *zap.Logger, validator service.TemplateValidator, load Loader, publish Publisher) *TemplateStore {
+	return &TemplateStore{
+		log:       log,
+		validator: validator,
+		load:      load,
+		publish:   publish,
+		overrides: make(map[string]*model.Template),
+	}
+}
+
+// List returns the current templates in routing order.
+func (inst *TemplateStore) List() []model.Template {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	templates := make([]model.Template, len(inst.current))
+	copy(templates, inst.current)
+	return templates
+}
+
+// Get returns the current template with the given ID.
+func (inst *TemplateStore) Get(id string) (model.Template, bool) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	for _, template := range inst.current {
+		if template.ID == id {
+			return template, true
+		}
+	}
+	return model.Template{}, false
+}
+
+// Put adds a template or replaces the template with the same ID.
+// A random ID is assigned if the template has none.
+//
+// Returns the stored template and true if it replaced an existing one,
+// or an error if the resulting set fails validation.
+func (inst *TemplateStore) Put(template model.Template) (model.Template, bool, error) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if template.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return model.Template{}, false, err
+		}
+		template.ID = id
+	}
+
+	replaced := false
+	for _, current := range inst.current {
+		if current.ID == template.ID {
+			replaced = true
+			break
+		}
+	}
+
+	previous, existed := inst.overrides[template.ID]
+	added := inst.added
+
+	inst.overrides[template.ID] = &template
+	if !inst.isAdded(template.ID) {
+		inst.added = append(inst.added, template.ID)
+	}
+
+	if err := inst.apply(); err != nil {
+		if existed {
+			inst.overrides[template.ID] = previous
+		} else {
+			delete(inst.overrides, template.ID)
+		}
+		inst.added = added
+		return model.Template{}, false, err
+	}
+
+	return template, replaced, nil
+}
+
+// Delete removes the template with the given ID.
+//
+// Returns false if no such template exists.
+func (inst *TemplateStore) Delete(id string) (bool, error) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	exists := false
+	for _, template := range inst.current {
+		if template.ID == id {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		return false, nil
+	}
+
+	previous, existed := inst.overrides[id]
+	added := inst.added
+
+	if inst.onDisk(id) {
+		inst.overrides[id] = nil
+	} else {
+		delete(inst.overrides, id)
+	}
+	inst.added = inst.removeAdded(id)
+
+	if err := inst.apply(); err != nil {
+		if existed {
+			inst.overrides[id] = previous
+		} else {
+			delete(inst.overrides, id)
+		}
+		inst.added = added
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Reset drops all runtime changes and reloads the on-disk templates.
+// The current set is kept if loading or validation fails.
+func (inst *TemplateStore) Reset() error {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	disk, err := inst.load()
+	if err != nil {
+		return err
+	}
+
+	overrides, added := inst.overrides, inst.added
+	inst.disk, inst.overrides, inst.added = disk, make(map[string]*model.Template), nil
+	if err := inst.apply(); err != nil {
+		inst.overrides, inst.added = overrides, added
+		return err
+	}
+	return nil
+}
+
+// Reload reloads the on-disk templates and keeps the runtime changes on top of them.
+// The current set is kept if loading or validation fails.
+func (inst *TemplateStore) Reload() error {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	disk, err := inst.load()
+	if err != nil {
+		return err
+	}
+
+	previous := inst.disk
+	inst.disk = disk
+	if err := inst.apply(); err != nil {
+		inst.disk = previous
+		return err
+	}
+	return nil
+}
+
+// apply merges the on-disk and runtime templates, validates the result
+// and publishes it. It must be called with the mutex held.
+func (inst *TemplateStore) apply() error {
+	merged := make([]model.Template, 0, len(inst.disk)+len(inst.added))
+	for _, template := range inst.disk {
+		override, ok := inst.overrides[template.ID]
+		switch {
+		case !ok:
+			merged = append(merged, template)
+		case override != nil:
+			merged = append(merged, *override)
+		}
+	}
+
+	for _, id := range inst.added {
+		// Templates replacing an on-disk one have already been merged in its place.
+		if override := inst.overrides[id]; override != nil && !inst.onDisk(id) {
+			merged = append(merged, *override)
+		}
+	}
+
+	if err := inst.validator.Validate(merged); err != nil {
+		return err
+	}
+
+	inst.current = merged
+	inst.publish(merged)
+	inst.log.Info("templates applied", zap.Int("templates", len(merged)))
+
+	return nil
+}
+
+// onDisk reports whether the on-disk set contains a template with the given ID.
+func (inst *TemplateStore) onDisk(id string) bool {
+	for _, template := range inst.disk {
+		if template.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// isAdded reports whether the template with the given ID was put at runtime.
+func (inst *TemplateStore) isAdded(id string) bool {
+	for _, addedID := range inst.added {
+		if addedID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// removeAdded returns the runtime IDs without the given one.
+func (inst *TemplateStore) removeAdded(id string) []string {
+	added := make([]string, 0, len(inst.added))
+	for _, addedID := range inst.added {
+		if addedID != id {
+			added = append(added, addedID)
+		}
+	}
+	return added
+}
+
+// newID generates a random template ID.
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"fmt"
+	"mockium/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type MockValidator struct {
+	validateFunc func([]model.Template) error
+}
+
+func (m *MockValidator) Validate(templates []model.Template) error {
+	if m.validateFunc == nil {
+		return nil
+	}
+	return m.validateFunc(templates)
+}
+
+func ids(templates []model.Template) []string {
+	result := make([]string, 0, len(templates))
+	for _, template := range templates {
+		result = append(result, template.ID)
+	}
+	return result
+}
+
+func newTestStore(t *testing.T, disk *[]model.Template, validator *MockValidator) (*TemplateStore, *[]model.Template) {
+	published := &[]model.Template{}
+	store := New(zaptest.NewLogger(t), validator,
+		func() ([]model.Template, error) { return *disk, nil },
+		func(templates []model.Template) { *published = templates },
+	)
+	require.NoError(t, store.Reset())
+	return store, published
+}
+
+func TestTemplateStore_Reset(t *testing.T) {
+	disk := []model.Template{{ID: "a.json", Path: "/a"}, {ID: "b.json", Path: "/b"}}
+	store, published := newTestStore(t, &disk, &MockValidator{})
+
+	assert.Equal(t, []string{"a.json", "b.json"}, ids(store.List()))
+	assert.Equal(t, []string{"a.json", "b.json"}, ids(*published))
+
+	_, _, err := store.Put(model.Template{ID: "c", Path: "/c"})
+	require.NoError(t, err)
+	_, err = store.Delete("a.json")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Reset())
+	assert.Equal(t, []string{"a.json", "b.json"}, ids(store.List()))
+}
+
+func TestTemplateStore_Put(t *testing.T) {
+	disk := []model.Template{{ID: "a.json", Path: "/a"}}
+	store, published := newTestStore(t, &disk, &MockValidator{})
+
+	created, replaced, err := store.Put(model.Template{Path: "/new"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.False(t, replaced)
+
+	_, replaced, err = store.Put(model.Template{ID: "a.json", Path: "/replaced"})
+	require.NoError(t, err)
+	assert.True(t, replaced)
+
+	assert.Equal(t, []string{"a.json", created.ID}, ids(*published))
+
+	stored, ok := store.Get("a.json")
+	require.True(t, ok)
+	assert.Equal(t, "/replaced", stored.Path)
+}
+
+func TestTemplateStore_PutInvalid(t *testing.T) {
+	disk := []model.Template{{ID: "a.json", Path: "/a"}}
+	validator := &MockValidator{}
+	store, _ := newTestStore(t, &disk, validator)
+
+	validator.validateFunc = func(templates []model.Template) error {
+		for _, template := range templates {
+			if template.Path == "" {
+				return fmt.Errorf("empty path")
+			}
+		}
+		return nil
+	}
+
+	_, _, err := store.Put(model.Template{ID: "a.json"})
+	assert.Error(t, err)
+	_, _, err = store.Put(model.Template{ID: "b"})
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"a.json"}, ids(store.List()))
+	template, _ := store.Get("a.json")
+	assert.Equal(t, "/a", template.Path)
+}
+
+func TestTemplateStore_Delete(t *testing.T) {
+	disk := []model.Template{{ID: "a.json", Path: "/a"}, {ID: "b.json", Path: "/b"}}
+	store, _ := newTestStore(t, &disk, &MockValidator{})
+
+	_, _, err := store.Put(model.Template{ID: "c", Path: "/c"})
+	require.NoError(t, err)
+
+	deleted, err := store.Delete("a.json")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = store.Delete("c")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = store.Delete("unknown")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	assert.Equal(t, []string{"b.json"}, ids(store.List()))
+}
+
+func TestTemplateStore_ReloadKeepsRuntimeChanges(t *testing.T) {
+	disk := []model.Template{{ID: "a.json", Path: "/a"}, {ID: "b.json", Path: "/b"}}
+	store, _ := newTestStore(t, &disk, &MockValidator{})
+
+	_, _, err := store.Put(model.Template{ID: "c", Path: "/c"})
+	require.NoError(t, err)
+	_, err = store.Delete("b.json")
+	require.NoError(t, err)
+
+	disk = []model.Template{{ID: "a.json", Path: "/a2"}, {ID: "b.json", Path: "/b"}, {ID: "d.json", Path: "/d"}}
+	require.NoError(t, store.Reload())
+
+	assert.Equal(t, []string{"a.json", "d.json", "c"}, ids(store.List()))
+	template, _ := store.Get("a.json")
+	assert.Equal(t, "/a2", template.Path)
+}
+
+func TestTemplateStore_ReloadError(t *testing.T) {
+	disk := []model.Template{{ID: "a.json", Path: "/a"}}
+	loadErr := error(nil)
+	store := New(zaptest.NewLogger(t), &MockValidator{},
+		func() ([]model.Template, error) { return disk, loadErr },
+		func([]model.Template) {},
+	)
+	require.NoError(t, store.Reset())
+
+	loadErr = fmt.Errorf("invalid template")
+	assert.Error(t, store.Reload())
+	assert.Equal(t, []string{"a.json"}, ids(store.List()))
+}
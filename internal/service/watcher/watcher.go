@@ -0,0 +1,122 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// fileState describes the observed state of a single file in the watched directory.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// Watcher polls a directory and calls a callback whenever a file in it
+// is created, modified or removed.
+type Watcher struct {
+	log      *zap.Logger          // Logger for polling errors and change notifications.
+	dir      string               // Directory to watch.
+	interval time.Duration        // Delay between two directory scans.
+	onChange func()               // Callback invoked after a change is detected.
+	state    map[string]fileState // Last observed state of the directory files.
+}
+
+// New creates a new Watcher for the given directory.
+//
+// Parameters:
+//   - log: logger used for diagnostics.
+//   - dir: directory to watch (subdirectories are ignored).
+//   - interval: delay between two directory scans.
+//   - onChange: callback invoked after a change is detected.
+//
+// Returns a pointer to a Watcher.
+func New(log *zap.Logger, dir string, interval time.Duration, onChange func()) *Watcher {
+	return &Watcher{
+		log:      log,
+		dir:      dir,
+		interval: interval,
+		onChange: onChange,
+	}
+}
+
+// Run takes an initial snapshot of the directory and then polls it until
+// the context is cancelled, calling the change callback on every difference.
+// Run blocks, so it is usually started in its own goroutine.
+func (inst *Watcher) Run(ctx context.Context) {
+	state, err := inst.scan()
+	if err != nil {
+		inst.log.Error("scan watched directory", zap.String("dir", inst.dir), zap.Error(err))
+	}
+	inst.state = state
+
+	ticker := time.NewTicker(inst.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if inst.poll() {
+				inst.log.Info("watched directory changed", zap.String("dir", inst.dir))
+				inst.onChange()
+			}
+		}
+	}
+}
+
+// poll scans the directory and reports whether it differs from the last observed state.
+func (inst *Watcher) poll() bool {
+	state, err := inst.scan()
+	if err != nil {
+		inst.log.Error("scan watched directory", zap.String("dir", inst.dir), zap.Error(err))
+		return false
+	}
+
+	changed := len(state) != len(inst.state)
+	if !changed {
+		for name, current := range state {
+			previous, ok := inst.state[name]
+			if !ok || previous.size != current.size || !previous.modTime.Equal(current.modTime) {
+				changed = true
+				break
+			}
+		}
+	}
+
+	inst.state = state
+	return changed
+}
+
+// scan reads the size and modification time of every file in the directory.
+func (inst *Watcher) scan() (map[string]fileState, error) {
+	entries, err := os.ReadDir(inst.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	state := make(map[string]fileState, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		info, err := os.Stat(filepath.Join(inst.dir, entry.Name()))
+		if err != nil {
+			// The file may have been removed between ReadDir and Stat,
+			// it will be reported as missing on the next scan.
+			continue
+		}
+
+		state[entry.Name()] = fileState{
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		}
+	}
+
+	return state, nil
+}
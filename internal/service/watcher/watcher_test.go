@@ -0,0 +1,64 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestWatcher_Poll(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "test.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{}`), 0644))
+
+	w := New(zaptest.NewLogger(t), dir, time.Second, func() {})
+
+	state, err := w.scan()
+	require.NoError(t, err)
+	w.state = state
+
+	assert.False(t, w.poll(), "no change")
+
+	require.NoError(t, os.WriteFile(file, []byte(`{"Path": "/"}`), 0644))
+	assert.True(t, w.poll(), "modified file")
+	assert.False(t, w.poll(), "change reported once")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.yaml"), []byte(`Path: /`), 0644))
+	assert.True(t, w.poll(), "created file")
+
+	require.NoError(t, os.Remove(file))
+	assert.True(t, w.poll(), "removed file")
+}
+
+func TestWatcher_Run(t *testing.T) {
+	dir := t.TempDir()
+
+	changed := make(chan struct{}, 1)
+	w := New(zaptest.NewLogger(t), dir, 10*time.Millisecond, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go w.Run(ctx)
+
+	// Let the watcher take its initial snapshot.
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.json"), []byte(`{}`), 0644))
+
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("change was not detected")
+	}
+}
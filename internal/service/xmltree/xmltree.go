@@ -0,0 +1,254 @@
+// Package xmltree converts XML documents to the generic tree used for JSON bodies and back,
+// so that XML bodies are matched with the same placeholders and built from the same SetBody.
+//
+// An element is a map keyed by the local names of its child elements; repeated children become a list.
+// Attributes are keyed "@name" and the text of an element with attributes or children is keyed "#text".
+// An element whose namespace differs from its parent's has its namespace URI under "@xmlns".
+// Elements with text only are plain strings. The document is a map with the root element as the only key.
+package xmltree
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"mockium/internal/service/constants"
+	"sort"
+	"strings"
+)
+
+// Special keys of the element maps.
+const (
+	TextKey      = "#text"  // Text content of an element with attributes or children.
+	AttrPrefix   = "@"      // Prefix of the attribute keys.
+	NamespaceKey = "@xmlns" // Namespace URI of an element, when it differs from its parent's.
+)
+
+// element is an element being decoded.
+type element struct {
+	name     xml.Name
+	parentNS string
+	attrs    map[string]any
+	children map[string]any
+	text     strings.Builder
+}
+
+// value converts the element to its tree value.
+func (inst *element) value() any {
+	text := strings.TrimSpace(inst.text.String())
+	if len(inst.attrs) == 0 && len(inst.children) == 0 {
+		return text
+	}
+
+	result := make(map[string]any, len(inst.attrs)+len(inst.children)+2)
+	if inst.name.Space != "" && inst.name.Space != inst.parentNS {
+		result[NamespaceKey] = inst.name.Space
+	}
+	for key, value := range inst.attrs {
+		result[key] = value
+	}
+	for key, value := range inst.children {
+		result[key] = value
+	}
+	if text != "" {
+		result[TextKey] = text
+	}
+	return result
+}
+
+// add adds a child element value, turning repeated children into a list.
+func (inst *element) add(name string, value any) {
+	if inst.children == nil {
+		inst.children = make(map[string]any)
+	}
+
+	switch existing := inst.children[name].(type) {
+	case nil:
+		inst.children[name] = value
+	case []any:
+		inst.children[name] = append(existing, value)
+	default:
+		inst.children[name] = []any{existing, value}
+	}
+}
+
+// Parse decodes an XML document into a tree.
+//
+// Parameters:
+//   - r: the XML document.
+//
+// Returns a map with the root element under its local name, or an error if the document is not well-formed.
+func Parse(r io.Reader) (map[string]any, error) {
+	decoder := xml.NewDecoder(r)
+	document := &element{}
+	stack := []*element{document}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		current := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			child := &element{name: t.Name, parentNS: current.name.Space}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+					continue
+				}
+				if child.attrs == nil {
+					child.attrs = make(map[string]any)
+				}
+				child.attrs[AttrPrefix+attr.Name.Local] = attr.Value
+			}
+			stack = append(stack, child)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+			stack[len(stack)-1].add(t.Name.Local, current.value())
+		case xml.CharData:
+			current.text.Write(t)
+		}
+	}
+
+	if len(document.children) != 1 {
+		return nil, fmt.Errorf("xml document must have a single root element")
+	}
+	return document.children, nil
+}
+
+// Marshal encodes a tree into an XML document with an XML declaration.
+//
+// The tree must be a map with the root element as the only key. Keys are written as they are,
+// so that "soap:Envelope" together with an "@xmlns:soap" attribute produces a prefixed element.
+//
+// Parameters:
+//   - tree: the document tree, e.g. a SetBody.
+//
+// Returns the document, or an error if the tree has no single root element.
+func Marshal(tree any) ([]byte, error) {
+	root, ok := tree.(map[string]any)
+	if !ok || len(root) != 1 {
+		return nil, fmt.Errorf("xml body must be an object with a single root element")
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(xml.Header)
+	for name, value := range root {
+		if err := writeElement(buf, name, value); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalContent encodes the content of an element: the elements of a map ordered by name,
+// or the escaped text of a scalar value.
+//
+// Parameters:
+//   - value: the content, e.g. the detail of a SOAP Fault.
+//
+// Returns the XML fragment, or an error if a map key is not a valid element name.
+func MarshalContent(value any) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		if value != nil {
+			escape(buf, value)
+		}
+		return buf.Bytes(), nil
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := writeElement(buf, key, fields[key]); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeElement writes an element, or one element per item of a list.
+func writeElement(buf *bytes.Buffer, name string, value any) error {
+	if strings.HasPrefix(name, AttrPrefix) || name == TextKey || name == "" {
+		return fmt.Errorf("invalid element name '%s'", name)
+	}
+
+	if items, ok := value.([]any); ok {
+		for _, item := range items {
+			if err := writeElement(buf, name, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	buf.WriteString("<" + name)
+
+	fields, isMap := value.(map[string]any)
+	if !isMap {
+		if value == nil {
+			buf.WriteString("/>")
+			return nil
+		}
+		buf.WriteString(">")
+		escape(buf, value)
+		buf.WriteString("</" + name + ">")
+		return nil
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if strings.HasPrefix(key, AttrPrefix) {
+			buf.WriteString(" " + strings.TrimPrefix(key, AttrPrefix) + `="`)
+			escape(buf, fields[key])
+			buf.WriteString(`"`)
+		}
+	}
+	buf.WriteString(">")
+
+	if text, ok := fields[TextKey]; ok {
+		escape(buf, text)
+	}
+
+	for _, key := range keys {
+		if strings.HasPrefix(key, AttrPrefix) || key == TextKey {
+			continue
+		}
+		if err := writeElement(buf, key, fields[key]); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteString("</" + name + ">")
+	return nil
+}
+
+// escape writes the escaped text of a scalar value.
+func escape(buf *bytes.Buffer, value any) {
+	xml.EscapeText(buf, []byte(fmt.Sprint(value)))
+}
+
+// IsXML reports whether the Content-Type is XML, e.g. "text/xml; charset=utf-8" or "application/soap+xml".
+func IsXML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == constants.ContentTypeTextXML || mediaType == constants.ContentTypeApplicationXML ||
+		strings.HasSuffix(mediaType, "+xml")
+}
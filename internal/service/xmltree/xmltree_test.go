@@ -0,0 +1,111 @@
+package xmltree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envelope = `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <m:GetOrder xmlns:m="urn:orders">
+      <m:id>42</m:id>
+      <m:item sku="ABC" qty="1">First</m:item>
+      <m:item sku="XYZ">Second</m:item>
+    </m:GetOrder>
+  </soap:Body>
+</soap:Envelope>`
+
+func TestParse(t *testing.T) {
+	tree, err := Parse(strings.NewReader(envelope))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"Envelope": map[string]any{
+			"@xmlns": "http://schemas.xmlsoap.org/soap/envelope/",
+			"Body": map[string]any{
+				"GetOrder": map[string]any{
+					"@xmlns": "urn:orders",
+					"id":     "42",
+					"item": []any{
+						map[string]any{"@sku": "ABC", "@qty": "1", "#text": "First"},
+						map[string]any{"@sku": "XYZ", "#text": "Second"},
+					},
+				},
+			},
+		},
+	}, tree)
+}
+
+func TestParse_Error(t *testing.T) {
+	for _, doc := range []string{"<a><b></a>", "", "text"} {
+		_, err := Parse(strings.NewReader(doc))
+		assert.Error(t, err, doc)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	data, err := Marshal(map[string]any{
+		"soap:Envelope": map[string]any{
+			"@xmlns:soap": "http://schemas.xmlsoap.org/soap/envelope/",
+			"soap:Body": map[string]any{
+				"GetOrderResponse": map[string]any{
+					"@xmlns": "urn:orders",
+					"id":     float64(42),
+					"note":   "a < b",
+					"item":   []any{map[string]any{"@sku": "ABC", "#text": "First"}, "Second"},
+					"empty":  nil,
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>`+
+		`<GetOrderResponse xmlns="urn:orders"><empty/><id>42</id><item sku="ABC">First</item><item>Second</item><note>a &lt; b</note></GetOrderResponse>`+
+		`</soap:Body></soap:Envelope>`, string(data))
+
+	_, err = Marshal(map[string]any{"a": 1, "b": 2})
+	assert.Error(t, err)
+
+	_, err = Marshal([]any{"a"})
+	assert.Error(t, err)
+}
+
+func TestXPath_Find(t *testing.T) {
+	tree, err := Parse(strings.NewReader(envelope))
+	require.NoError(t, err)
+
+	tests := []struct {
+		expr string
+		want []any
+	}{
+		{"/soap:Envelope/soap:Body/m:GetOrder/m:id", []any{"42"}},
+		{"/Envelope/Body/GetOrder/id/text()", []any{"42"}},
+		{"//item", []any{"First", "Second"}},
+		{"//item[2]/@sku", []any{"XYZ"}},
+		{"//item/@qty", []any{"1"}},
+		{"/Envelope/*/GetOrder/id", []any{"42"}},
+		{"//missing", []any{}},
+		{"/Envelope/@xmlns", []any{"http://schemas.xmlsoap.org/soap/envelope/"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			path, err := CompileXPath(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, path.Find(tree))
+		})
+	}
+}
+
+func TestCompileXPath_Error(t *testing.T) {
+	for _, expr := range []string{"", "id", "/", "/a[0]", "/a[@id='1']", "/a[1"} {
+		_, err := CompileXPath(expr)
+		assert.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,216 @@
+package xmltree
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// step is a single location step of an XPath expression.
+type step struct {
+	descendant bool   // The step follows "//".
+	name       string // Local element name, "*", "@attribute" or "text()".
+	position   int    // 1-based position predicate, 0 if none.
+}
+
+// XPath is a compiled XPath expression over a tree produced by Parse.
+//
+// The supported subset covers absolute paths of element names with or without a prefix,
+// "*", "//", "@attribute", "text()" and positions such as "item[2]".
+type XPath struct {
+	expr  string
+	steps []step
+}
+
+// CompileXPath parses an XPath expression.
+//
+// Parameters:
+//   - expr: the expression, e.g. "/soap:Envelope/soap:Body/GetUser/id" or "//item[1]/@sku".
+//
+// Returns the compiled expression, or an error if it is invalid or not supported.
+func CompileXPath(expr string) (*XPath, error) {
+	if !strings.HasPrefix(expr, "/") {
+		return nil, fmt.Errorf("xpath '%s' must be absolute", expr)
+	}
+
+	path := &XPath{expr: expr}
+	rest := expr
+	for rest != "" {
+		descendant := strings.HasPrefix(rest, "//")
+		rest = strings.TrimPrefix(strings.TrimPrefix(rest, "/"), "/")
+
+		end := strings.Index(rest, "/")
+		if end < 0 {
+			end = len(rest)
+		}
+		text := rest[:end]
+		rest = rest[end:]
+
+		s, err := parseStep(text)
+		if err != nil {
+			return nil, fmt.Errorf("xpath '%s': %w", expr, err)
+		}
+		s.descendant = descendant
+		path.steps = append(path.steps, s)
+	}
+
+	if len(path.steps) == 0 {
+		return nil, fmt.Errorf("xpath '%s': missing step", expr)
+	}
+	return path, nil
+}
+
+// parseStep parses a location step: a name with an optional position predicate.
+func parseStep(text string) (step, error) {
+	s := step{name: text}
+	if open := strings.Index(text, "["); open >= 0 {
+		if !strings.HasSuffix(text, "]") {
+			return step{}, fmt.Errorf("missing ']' in '%s'", text)
+		}
+
+		position, err := strconv.Atoi(text[open+1 : len(text)-1])
+		if err != nil || position < 1 {
+			return step{}, fmt.Errorf("unsupported predicate in '%s'", text)
+		}
+		s.name, s.position = text[:open], position
+	}
+
+	if s.name == "" {
+		return step{}, fmt.Errorf("missing step name")
+	}
+
+	// Prefixes are not bound to namespaces, elements are selected by their local name.
+	if !strings.HasPrefix(s.name, AttrPrefix) {
+		if colon := strings.LastIndex(s.name, ":"); colon >= 0 {
+			s.name = s.name[colon+1:]
+		}
+	} else if colon := strings.LastIndex(s.name, ":"); colon >= 0 {
+		s.name = AttrPrefix + s.name[colon+1:]
+	}
+
+	return s, nil
+}
+
+// String returns the source expression.
+func (inst *XPath) String() string {
+	return inst.expr
+}
+
+// Find evaluates the expression on a tree produced by Parse.
+//
+// Parameters:
+//   - doc: the document tree.
+//
+// Returns the selected values: the text of elements and attributes, or the element maps
+// of elements with children; empty if nothing is selected.
+func (inst *XPath) Find(doc any) []any {
+	nodes := []any{doc}
+	for _, s := range inst.steps {
+		if s.descendant {
+			nodes = descendants(nodes)
+		}
+
+		var next []any
+		for _, node := range nodes {
+			selected := s.apply(node)
+			if s.position > 0 {
+				if s.position > len(selected) {
+					continue
+				}
+				selected = selected[s.position-1 : s.position]
+			}
+			next = append(next, selected...)
+		}
+		nodes = next
+	}
+
+	values := make([]any, 0, len(nodes))
+	for _, node := range nodes {
+		if element, ok := node.(map[string]any); ok {
+			if text, ok := element[TextKey]; ok && !hasChildren(element) {
+				node = text
+			}
+		}
+		values = append(values, node)
+	}
+	return values
+}
+
+// apply selects the values of the step from a node.
+func (inst step) apply(node any) []any {
+	if inst.name == "text()" {
+		switch v := node.(type) {
+		case string:
+			return []any{v}
+		case map[string]any:
+			if text, ok := v[TextKey]; ok {
+				return []any{text}
+			}
+		}
+		return nil
+	}
+
+	element, ok := node.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	if strings.HasPrefix(inst.name, AttrPrefix) {
+		if value, ok := element[inst.name]; ok {
+			return []any{value}
+		}
+		return nil
+	}
+
+	if inst.name == "*" {
+		return childElements(element)
+	}
+	return flatten(element[inst.name])
+}
+
+// childElements returns the child elements of an element, ordered by name.
+func childElements(element map[string]any) []any {
+	names := make([]string, 0, len(element))
+	for name := range element {
+		if !strings.HasPrefix(name, AttrPrefix) && name != TextKey {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var children []any
+	for _, name := range names {
+		children = append(children, flatten(element[name])...)
+	}
+	return children
+}
+
+// descendants returns the nodes together with all their descendant elements.
+func descendants(nodes []any) []any {
+	var all []any
+	for _, node := range nodes {
+		all = append(all, node)
+		if element, ok := node.(map[string]any); ok {
+			all = append(all, descendants(childElements(element))...)
+		}
+	}
+	return all
+}
+
+// flatten returns the elements of a repeated child, or the single child.
+func flatten(value any) []any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		return v
+	default:
+		return []any{v}
+	}
+}
+
+// hasChildren reports whether the element has child elements.
+func hasChildren(element map[string]any) bool {
+	return len(childElements(element)) > 0
+}
@@ -0,0 +1,112 @@
+package admin
+
+import (
+	"encoding/json"
+	"mockium/internal/service"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Admin is an HTTP handler exposing the administration REST API,
+// used to inspect and change the mock configuration at runtime.
+type Admin struct {
+	log       *zap.Logger            // Logger for admin operations.
+	templates service.TemplateStore  // Live set of templates.
+	journal   service.RequestJournal // Journal of the served requests, nil if disabled.
+	scenarios service.ScenarioStore  // Scenario state store.
+	sequences service.SequenceStore  // Response lists of the handles, nil if disabled.
+	router    *mux.Router            // Router of the admin endpoints.
+}
+
+// New creates a new Admin API handler serving under the given path prefix.
+//
+// Endpoints (relative to the prefix):
+//   - GET    /templates       - list the current templates
+//   - POST   /templates       - add a template, or replace the one with the same ID
+//   - POST   /templates/reset - drop runtime changes and reload the on-disk templates
+//   - GET    /templates/{id}  - get a template
+//   - PUT    /templates/{id}  - add or replace a template
+//   - DELETE /templates/{id}  - delete a template
+//   - GET    /requests        - list journal requests filtered by query parameters
+//   - GET    /requests/count  - count journal requests filtered by query parameters
+//   - POST   /requests/find   - list journal requests matching a model.JournalFilter
+//   - POST   /requests/count  - count journal requests matching a model.JournalFilter
+//   - DELETE /requests        - clear the journal
+//   - GET    /scenarios              - list the current scenario states
+//   - POST   /scenarios/reset        - reset all scenarios to the initial state
+//   - GET    /scenarios/{name}       - get a scenario state
+//   - PUT    /scenarios/{name}       - set a scenario state
+//   - POST   /scenarios/{name}/reset - reset a scenario to the initial state
+//   - POST   /sequences/reset        - move all response lists back to their first response
+//   - POST   /sequences/{id}/reset   - move the response lists of a template back to their first response
+//
+// Parameters:
+//   - log: logger for admin operations.
+//   - prefix: path prefix of the admin endpoints (e.g., "/__admin").
+//   - templates: store holding the live templates.
+//   - journal: journal of the served requests, nil disables the request endpoints.
+//   - scenarios: store holding the scenario states.
+//   - sequences: store holding the response lists of the handles, nil disables the sequence endpoints.
+//
+// Returns a pointer to an Admin handler.
+func New(log *zap.Logger, prefix string, templates service.TemplateStore, journal service.RequestJournal, scenarios service.ScenarioStore, sequences service.SequenceStore) *Admin {
+	inst := &Admin{
+		log:       log,
+		templates: templates,
+		journal:   journal,
+		scenarios: scenarios,
+		sequences: sequences,
+	}
+
+	r := mux.NewRouter().PathPrefix(prefix).Subrouter()
+	r.HandleFunc("/templates", inst.listTemplates).Methods(http.MethodGet)
+	r.HandleFunc("/templates", inst.createTemplate).Methods(http.MethodPost)
+	r.HandleFunc("/templates/reset", inst.resetTemplates).Methods(http.MethodPost)
+	r.HandleFunc("/templates/{id}", inst.getTemplate).Methods(http.MethodGet)
+	r.HandleFunc("/templates/{id}", inst.putTemplate).Methods(http.MethodPut)
+	r.HandleFunc("/templates/{id}", inst.deleteTemplate).Methods(http.MethodDelete)
+
+	if journal != nil {
+		r.HandleFunc("/requests", inst.listRequests).Methods(http.MethodGet)
+		r.HandleFunc("/requests", inst.clearRequests).Methods(http.MethodDelete)
+		r.HandleFunc("/requests/count", inst.countRequests).Methods(http.MethodGet)
+		r.HandleFunc("/requests/count", inst.verifyRequests).Methods(http.MethodPost)
+		r.HandleFunc("/requests/find", inst.findRequests).Methods(http.MethodPost)
+	}
+
+	r.HandleFunc("/scenarios", inst.listScenarios).Methods(http.MethodGet)
+	r.HandleFunc("/scenarios/reset", inst.resetScenarios).Methods(http.MethodPost)
+	r.HandleFunc("/scenarios/{name}", inst.getScenario).Methods(http.MethodGet)
+	r.HandleFunc("/scenarios/{name}", inst.setScenario).Methods(http.MethodPut)
+	r.HandleFunc("/scenarios/{name}/reset", inst.resetScenario).Methods(http.MethodPost)
+
+	if sequences != nil {
+		r.HandleFunc("/sequences/reset", inst.resetSequences).Methods(http.MethodPost)
+		r.HandleFunc("/sequences/{id}/reset", inst.resetSequence).Methods(http.MethodPost)
+	}
+	inst.router = r
+
+	return inst
+}
+
+// ServeHTTP dispatches the request to the matching admin endpoint.
+func (inst *Admin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	inst.router.ServeHTTP(w, r)
+}
+
+// writeJSON writes the value as a JSON response with the given status.
+func (inst *Admin) writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		inst.log.Error("write admin response", zap.Error(err))
+	}
+}
+
+// writeError writes the error as a JSON response with the given status.
+func (inst *Admin) writeError(w http.ResponseWriter, status int, err error) {
+	inst.log.Warn("admin request failed", zap.Int("status", status), zap.Error(err))
+	inst.writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,171 @@
+package admin
+
+import (
+	"encoding/json"
+	"mockium/internal/model"
+	"mockium/internal/service/builder"
+	"mockium/internal/service/scenario"
+	"mockium/internal/service/store"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+const prefix = "/__admin"
+
+func newTestAdmin(t *testing.T, disk []model.Template) (*Admin, *[]model.Template) {
+	published := &[]model.Template{}
+	templates := store.New(zaptest.NewLogger(t), builder.NewTemplateBuilder(zap.NewNop()),
+		func() ([]model.Template, error) { return disk, nil },
+		func(templates []model.Template) { *published = templates },
+	)
+	require.NoError(t, templates.Reset())
+
+	return New(zaptest.NewLogger(t), prefix, templates, nil, scenario.New(), nil), published
+}
+
+func serve(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestAdmin_ListTemplates(t *testing.T) {
+	a, _ := newTestAdmin(t, []model.Template{{ID: "users.json", Path: "/users"}})
+
+	rec := serve(a, http.MethodGet, prefix+"/templates", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var templates []model.Template
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &templates))
+	require.Len(t, templates, 1)
+	assert.Equal(t, "users.json", templates[0].ID)
+	assert.Equal(t, "/users", templates[0].Path)
+}
+
+func TestAdmin_CreateTemplate(t *testing.T) {
+	a, published := newTestAdmin(t, nil)
+
+	rec := serve(a, http.MethodPost, prefix+"/templates", `{
+		"Path": "/login",
+		"Handle": [{"MatchRequest": {"MustMethod": "POST"}, "SetResponse": {"SetStatus": 201}}]
+	}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var created model.Template
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.ID)
+
+	require.Len(t, *published, 1)
+	assert.Equal(t, "/login", (*published)[0].Path)
+	assert.Equal(t, 201, (*published)[0].Handle[0].SetResponseTemplate.SetStatus)
+
+	rec = serve(a, http.MethodGet, prefix+"/templates/"+created.ID, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestAdmin_CreateTemplateLegacyStatus(t *testing.T) {
+	a, published := newTestAdmin(t, nil)
+
+	rec := serve(a, http.MethodPost, prefix+"/templates", `{
+		"Path": "/login",
+		"Handle": [{"SetResponse": {"SeStatus": 201}}]
+	}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Len(t, *published, 1)
+	assert.Equal(t, 201, (*published)[0].Handle[0].SetResponseTemplate.SetStatus)
+	assert.Contains(t, rec.Body.String(), `"SetStatus":201`)
+
+	rec = serve(a, http.MethodPost, prefix+"/templates", `{
+		"Path": "/login",
+		"Handle": [{"SetResponse": {"SeStatus": 201, "SetStatus": 200}}]
+	}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAdmin_PutTemplate(t *testing.T) {
+	a, published := newTestAdmin(t, []model.Template{{ID: "users.json", Path: "/users"}})
+
+	rec := serve(a, http.MethodPut, prefix+"/templates/users.json", `{"Path": "/accounts"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(a, http.MethodPut, prefix+"/templates/orders", `{"Path": "/orders"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	require.Len(t, *published, 2)
+	assert.Equal(t, "/accounts", (*published)[0].Path)
+	assert.Equal(t, "orders", (*published)[1].ID)
+}
+
+func TestAdmin_PutTemplateConcurrent(t *testing.T) {
+	a, _ := newTestAdmin(t, nil)
+
+	var wg sync.WaitGroup
+	var created atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := serve(a, http.MethodPut, prefix+"/templates/users", `{"Path": "/users"}`)
+			if rec.Code == http.StatusCreated {
+				created.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), created.Load())
+}
+
+func TestAdmin_InvalidTemplate(t *testing.T) {
+	a, _ := newTestAdmin(t, nil)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "Invalid JSON", body: `{`},
+		{name: "Missing path", body: `{"Handle": []}`},
+		{name: "Invalid method", body: `{"Path": "/", "Handle": [{"MatchRequest": {"MustMethod": "ERROR"}}]}`},
+		{name: "Body with file", body: `{"Path": "/", "Handle": [{"SetResponse": {"SetBody": {}, "SetFile": "file"}}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(a, http.MethodPost, prefix+"/templates", tt.body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
+
+func TestAdmin_DeleteTemplate(t *testing.T) {
+	a, published := newTestAdmin(t, []model.Template{{ID: "users.json", Path: "/users"}})
+
+	rec := serve(a, http.MethodDelete, prefix+"/templates/users.json", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Len(t, *published, 0)
+
+	rec = serve(a, http.MethodDelete, prefix+"/templates/users.json", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAdmin_ResetTemplates(t *testing.T) {
+	a, published := newTestAdmin(t, []model.Template{{ID: "users.json", Path: "/users"}})
+
+	serve(a, http.MethodDelete, prefix+"/templates/users.json", "")
+	serve(a, http.MethodPost, prefix+"/templates", `{"Path": "/orders"}`)
+
+	rec := serve(a, http.MethodPost, prefix+"/templates/reset", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	require.Len(t, *published, 1)
+	assert.Equal(t, "users.json", (*published)[0].ID)
+}
@@ -0,0 +1,186 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mockium/internal/model"
+	"mockium/internal/service/matcher"
+	"mockium/internal/transport"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// listRequests responds with the journal entries accepted by the filter
+// from the query parameters (path, method, template, handle).
+func (inst *Admin) listRequests(w http.ResponseWriter, r *http.Request) {
+	filter, err := inst.queryFilter(r)
+	if err != nil {
+		inst.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	inst.writeJSON(w, http.StatusOK, inst.journal.Find(inst.journalFilter(filter)))
+}
+
+// countRequests responds with the number of journal entries accepted by the
+// filter from the query parameters (path, method, template, handle).
+func (inst *Admin) countRequests(w http.ResponseWriter, r *http.Request) {
+	filter, err := inst.queryFilter(r)
+	if err != nil {
+		inst.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	inst.writeJSON(w, http.StatusOK, map[string]int{"count": len(inst.journal.Find(inst.journalFilter(filter)))})
+}
+
+// findRequests responds with the journal entries accepted by the filter from the request body.
+func (inst *Admin) findRequests(w http.ResponseWriter, r *http.Request) {
+	filter, err := inst.bodyFilter(r)
+	if err != nil {
+		inst.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	inst.writeJSON(w, http.StatusOK, inst.journal.Find(inst.journalFilter(filter)))
+}
+
+// verifyRequests responds with the number of journal entries accepted by the filter from the request body.
+func (inst *Admin) verifyRequests(w http.ResponseWriter, r *http.Request) {
+	filter, err := inst.bodyFilter(r)
+	if err != nil {
+		inst.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	inst.writeJSON(w, http.StatusOK, map[string]int{"count": len(inst.journal.Find(inst.journalFilter(filter)))})
+}
+
+// clearRequests removes all entries from the journal.
+func (inst *Admin) clearRequests(w http.ResponseWriter, r *http.Request) {
+	inst.journal.Clear()
+	inst.log.Info("request journal cleared")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// queryFilter reads a journal filter from the query parameters.
+func (inst *Admin) queryFilter(r *http.Request) (model.JournalFilter, error) {
+	query := r.URL.Query()
+	filter := model.JournalFilter{
+		Path:     query.Get("path"),
+		Method:   query.Get("method"),
+		Template: query.Get("template"),
+	}
+
+	if handle := query.Get("handle"); handle != "" {
+		idx, err := strconv.Atoi(handle)
+		if err != nil {
+			return model.JournalFilter{}, fmt.Errorf("invalid handle index '%s'", handle)
+		}
+		filter.HandleIndex = &idx
+	}
+
+	return filter, nil
+}
+
+// bodyFilter reads a journal filter from the JSON request body.
+// An empty body is an empty filter accepting all entries.
+func (inst *Admin) bodyFilter(r *http.Request) (model.JournalFilter, error) {
+	filter := model.JournalFilter{}
+	if err := json.NewDecoder(r.Body).Decode(&filter); err != nil && err != io.EOF {
+		return model.JournalFilter{}, fmt.Errorf("decode filter: %w", err)
+	}
+	return filter, nil
+}
+
+// journalFilter builds the journal predicate for the filter. The MatchRequest
+// criteria are evaluated with the same request matcher used to serve the mocks.
+func (inst *Admin) journalFilter(filter model.JournalFilter) func(*model.ProcessLoggingFileds) bool {
+	var reqMatcher transport.RequestMatcher
+	if filter.MatchRequestTemplate != nil {
+		reqMatcher = matcher.NewRequestMatcher(inst.log, filter.MatchRequestTemplate)
+	}
+
+	return func(entry *model.ProcessLoggingFileds) bool {
+		if entry.Request == nil {
+			return false
+		}
+
+		if filter.Path != "" && inst.entryPath(entry) != filter.Path {
+			return false
+		}
+
+		if filter.Method != "" && !strings.EqualFold(entry.Request.Method, filter.Method) {
+			return false
+		}
+
+		if filter.Template != "" && entry.Template != filter.Template {
+			return false
+		}
+
+		if filter.HandleIndex != nil && (entry.HandleIndex == nil || *entry.HandleIndex != *filter.HandleIndex) {
+			return false
+		}
+
+		if reqMatcher == nil {
+			return true
+		}
+
+		if mustMethod := filter.MatchRequestTemplate.MustMethod; mustMethod != "" && !strings.EqualFold(entry.Request.Method, string(mustMethod)) {
+			return false
+		}
+
+		req, err := inst.replayRequest(entry.Request)
+		if err != nil {
+			inst.log.Warn("replay journal request", zap.String("url", entry.Request.Url), zap.Error(err))
+			return false
+		}
+
+		return reqMatcher.Match(req)
+	}
+}
+
+// entryPath returns the URL path of the journal entry.
+func (inst *Admin) entryPath(entry *model.ProcessLoggingFileds) string {
+	u, err := url.Parse(entry.Request.Url)
+	if err != nil {
+		return entry.Request.Url
+	}
+	return u.Path
+}
+
+// replayRequest rebuilds an HTTP request from the journal entry, so it can be
+// evaluated by a request matcher.
+func (inst *Admin) replayRequest(logReq *model.LogginRequest) (*http.Request, error) {
+	body := ""
+	if str, ok := logReq.Body.(string); ok {
+		body = str
+	}
+
+	req, err := http.NewRequest(logReq.Method, logReq.Url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range logReq.Headers {
+		switch v := values.(type) {
+		case []string:
+			for _, value := range v {
+				req.Header.Add(name, value)
+			}
+		case string:
+			req.Header.Add(name, v)
+		}
+	}
+
+	for name, value := range logReq.PathParams {
+		req.SetPathValue(name, value)
+	}
+
+	return req, nil
+}
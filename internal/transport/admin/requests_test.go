@@ -0,0 +1,169 @@
+package admin
+
+import (
+	"encoding/json"
+	"mockium/internal/logging"
+	"mockium/internal/model"
+	"mockium/internal/service/builder"
+	"mockium/internal/service/scenario"
+	"mockium/internal/service/store"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+func newJournalAdmin(t *testing.T) (*Admin, *logging.Journal) {
+	templates := store.New(zaptest.NewLogger(t), builder.NewTemplateBuilder(zap.NewNop()),
+		func() ([]model.Template, error) { return nil, nil },
+		func([]model.Template) {},
+	)
+	require.NoError(t, templates.Reset())
+
+	journal := logging.NewJournal(100)
+
+	idx := func(i int) *int { return &i }
+	journal.Log(&model.ProcessLoggingFileds{
+		Template:    "login.json",
+		HandleIndex: idx(0),
+		Request: &model.LogginRequest{
+			Url:     "/login",
+			Method:  http.MethodPost,
+			Headers: map[string]any{"Content-Type": []string{"application/json"}},
+			Body:    `{"username": "test", "password": "secret"}`,
+		},
+	})
+	journal.Log(&model.ProcessLoggingFileds{
+		Template:    "login.json",
+		HandleIndex: idx(0),
+		Request: &model.LogginRequest{
+			Url:     "/login",
+			Method:  http.MethodPost,
+			Headers: map[string]any{"Content-Type": []string{"application/json"}},
+			Body:    `{"username": "test", "password": "other"}`,
+		},
+	})
+	journal.Log(&model.ProcessLoggingFileds{
+		Template:    "login.json",
+		HandleIndex: idx(1),
+		Request: &model.LogginRequest{
+			Url:     "/login",
+			Method:  http.MethodPost,
+			Headers: map[string]any{"Content-Type": []string{"application/json"}},
+			Body:    `{"username": "admin"}`,
+		},
+	})
+	journal.Log(&model.ProcessLoggingFileds{
+		Template: "users.json",
+		Request: &model.LogginRequest{
+			Url:        "/users/42?sort=name",
+			Method:     http.MethodGet,
+			PathParams: map[string]string{"id": "42"},
+		},
+	})
+
+	return New(zaptest.NewLogger(t), prefix, templates, journal, scenario.New(), nil), journal
+}
+
+func count(t *testing.T, rec interface{ Result() *http.Response }) int {
+	var result map[string]int
+	require.NoError(t, json.NewDecoder(rec.Result().Body).Decode(&result))
+	return result["count"]
+}
+
+func TestAdmin_ListRequests(t *testing.T) {
+	a, _ := newJournalAdmin(t)
+
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{name: "All", query: "", want: 4},
+		{name: "By path", query: "?path=/login", want: 3},
+		{name: "By path without query", query: "?path=/users/42", want: 1},
+		{name: "By method", query: "?method=get", want: 1},
+		{name: "By template", query: "?template=login.json", want: 3},
+		{name: "By handle", query: "?template=login.json&handle=1", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(a, http.MethodGet, prefix+"/requests"+tt.query, "")
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var entries []model.ProcessLoggingFileds
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &entries))
+			assert.Len(t, entries, tt.want)
+
+			rec = serve(a, http.MethodGet, prefix+"/requests/count"+tt.query, "")
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.want, count(t, rec))
+		})
+	}
+
+	rec := serve(a, http.MethodGet, prefix+"/requests?handle=first", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAdmin_VerifyRequests(t *testing.T) {
+	a, _ := newJournalAdmin(t)
+
+	tests := []struct {
+		name   string
+		filter string
+		want   int
+	}{
+		{
+			name:   "Body parameter",
+			filter: `{"Path": "/login", "MatchRequest": {"MustMethod": "POST", "MustBodyParameters": {"username": "test"}}}`,
+			want:   2,
+		},
+		{
+			name:   "Body regexp",
+			filter: `{"MatchRequest": {"MustBodyParameters": {"password": "${regexp:^sec}"}}}`,
+			want:   1,
+		},
+		{
+			name:   "Method mismatch",
+			filter: `{"MatchRequest": {"MustMethod": "PUT"}}`,
+			want:   0,
+		},
+		{
+			name:   "Query and path parameters",
+			filter: `{"MatchRequest": {"MustQueryParameters": {"sort": "name"}, "MustPathParameters": {"id": "42"}}}`,
+			want:   1,
+		},
+		{
+			name:   "Empty filter",
+			filter: ``,
+			want:   4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(a, http.MethodPost, prefix+"/requests/count", tt.filter)
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.want, count(t, rec))
+
+			rec = serve(a, http.MethodPost, prefix+"/requests/find", tt.filter)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var entries []model.ProcessLoggingFileds
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &entries))
+			assert.Len(t, entries, tt.want)
+		})
+	}
+}
+
+func TestAdmin_ClearRequests(t *testing.T) {
+	a, journal := newJournalAdmin(t)
+
+	rec := serve(a, http.MethodDelete, prefix+"/requests", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, journal.Find(nil))
+}
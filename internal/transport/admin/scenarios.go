@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// scenarioState is the request and response body of a single scenario state.
+type scenarioState struct {
+	State string `json:"State"`
+}
+
+// listScenarios responds with the current state of every known scenario.
+func (inst *Admin) listScenarios(w http.ResponseWriter, r *http.Request) {
+	inst.writeJSON(w, http.StatusOK, inst.scenarios.States())
+}
+
+// getScenario responds with the current state of the scenario identified by the path.
+func (inst *Admin) getScenario(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	state, ok := inst.scenarios.States()[name]
+	if !ok {
+		inst.writeError(w, http.StatusNotFound, fmt.Errorf("scenario '%s' not found", name))
+		return
+	}
+
+	inst.writeJSON(w, http.StatusOK, scenarioState{State: state})
+}
+
+// setScenario moves the scenario identified by the path to the state from the request body.
+func (inst *Admin) setScenario(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	body := scenarioState{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		inst.writeError(w, http.StatusBadRequest, fmt.Errorf("decode scenario state: %w", err))
+		return
+	}
+
+	if body.State == "" {
+		inst.writeError(w, http.StatusBadRequest, fmt.Errorf("scenario 'State' is required"))
+		return
+	}
+
+	inst.scenarios.Update(name, body.State)
+	inst.log.Info("scenario state set", zap.String("scenario", name), zap.String("state", body.State))
+
+	inst.writeJSON(w, http.StatusOK, body)
+}
+
+// resetScenario moves the scenario identified by the path back to its initial state.
+func (inst *Admin) resetScenario(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	if !inst.scenarios.Reset(name) {
+		inst.writeError(w, http.StatusNotFound, fmt.Errorf("scenario '%s' not found", name))
+		return
+	}
+
+	inst.log.Info("scenario reset", zap.String("scenario", name))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// resetScenarios moves all scenarios back to their initial state.
+func (inst *Admin) resetScenarios(w http.ResponseWriter, r *http.Request) {
+	inst.scenarios.ResetAll()
+	inst.log.Info("scenarios reset")
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"encoding/json"
+	"mockium/internal/model"
+	"mockium/internal/service/builder"
+	"mockium/internal/service/scenario"
+	"mockium/internal/service/store"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+func newScenarioAdmin(t *testing.T) (*Admin, *scenario.Store) {
+	templates := store.New(zaptest.NewLogger(t), builder.NewTemplateBuilder(zap.NewNop()),
+		func() ([]model.Template, error) { return nil, nil },
+		func([]model.Template) {},
+	)
+	require.NoError(t, templates.Reset())
+
+	scenarios := scenario.New()
+	scenarios.Register("order")
+	scenarios.Update("payment", "done")
+
+	return New(zaptest.NewLogger(t), prefix, templates, nil, scenarios, nil), scenarios
+}
+
+func TestAdmin_ListScenarios(t *testing.T) {
+	a, _ := newScenarioAdmin(t)
+
+	rec := serve(a, http.MethodGet, prefix+"/scenarios", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var states map[string]string
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &states))
+	assert.Equal(t, map[string]string{"order": scenario.StartedState, "payment": "done"}, states)
+
+	rec = serve(a, http.MethodGet, prefix+"/scenarios/payment", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"State": "done"}`, rec.Body.String())
+
+	rec = serve(a, http.MethodGet, prefix+"/scenarios/unknown", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAdmin_SetScenario(t *testing.T) {
+	a, scenarios := newScenarioAdmin(t)
+
+	rec := serve(a, http.MethodPut, prefix+"/scenarios/order", `{"State": "paid"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "paid", scenarios.States()["order"])
+
+	rec = serve(a, http.MethodPut, prefix+"/scenarios/order", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAdmin_ResetScenarios(t *testing.T) {
+	a, scenarios := newScenarioAdmin(t)
+	scenarios.Update("order", "paid")
+
+	rec := serve(a, http.MethodPost, prefix+"/scenarios/order/reset", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, scenario.StartedState, scenarios.States()["order"])
+
+	rec = serve(a, http.MethodPost, prefix+"/scenarios/unknown/reset", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(a, http.MethodPost, prefix+"/scenarios/reset", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, map[string]string{"order": scenario.StartedState, "payment": scenario.StartedState}, scenarios.States())
+}
@@ -0,0 +1,29 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// resetSequence moves the response lists of the template identified by the path back to their first response.
+func (inst *Admin) resetSequence(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if !inst.sequences.Reset(id) {
+		inst.writeError(w, http.StatusNotFound, fmt.Errorf("template '%s' has no response lists", id))
+		return
+	}
+
+	inst.log.Info("sequences reset", zap.String("template", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// resetSequences moves all response lists back to their first response.
+func (inst *Admin) resetSequences(w http.ResponseWriter, r *http.Request) {
+	inst.sequences.ResetAll()
+	inst.log.Info("all sequences reset")
+	w.WriteHeader(http.StatusNoContent)
+}
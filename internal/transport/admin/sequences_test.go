@@ -0,0 +1,115 @@
+package admin
+
+import (
+	"mockium/internal/model"
+	"mockium/internal/service"
+	"mockium/internal/service/builder"
+	"mockium/internal/service/scenario"
+	"mockium/internal/service/sequence"
+	"mockium/internal/service/store"
+	"mockium/internal/transport"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAdmin_ResetSequences(t *testing.T) {
+	templates := store.New(zaptest.NewLogger(t), builder.NewTemplateBuilder(zap.NewNop()),
+		func() ([]model.Template, error) { return nil, nil },
+		func([]model.Template) {},
+	)
+	require.NoError(t, templates.Reset())
+
+	sequences := sequence.New()
+	responses := builder.NewSequenceBuilder([]model.SetResponseTemplate{{SetStatus: http.StatusServiceUnavailable}, {SetStatus: http.StatusOK}}, "")
+	sequences.Register("orders", map[int]service.ResponseSequence{0: responses})
+
+	a := New(zaptest.NewLogger(t), prefix, templates, nil, scenario.New(), sequences)
+
+	status := func() int {
+		resp, err := responses.Build(httptest.NewRequest(http.MethodGet, "/orders", nil))
+		require.NoError(t, err)
+		return resp.SetStatus
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, status())
+	assert.Equal(t, http.StatusOK, status())
+
+	rec := serve(a, http.MethodPost, prefix+"/sequences/orders/reset", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, http.StatusServiceUnavailable, status())
+
+	rec = serve(a, http.MethodPost, prefix+"/sequences/unknown/reset", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(a, http.MethodPost, prefix+"/sequences/reset", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, http.StatusServiceUnavailable, status())
+}
+
+func TestAdmin_SequencesAcrossTemplateChanges(t *testing.T) {
+	log := zap.NewNop()
+	sequences := sequence.New()
+	options := builder.Options{Sequences: sequences}
+
+	orders := model.Template{
+		ID:   "orders.json",
+		Path: "/orders",
+		Handle: []model.HandleTemplate{{
+			MatchRequestTemplate: model.MatchRequestTemplate{MustMethod: model.GET},
+			SetResponses:         []model.SetResponseTemplate{{SetStatus: 201}, {SetStatus: 202}, {SetStatus: 203}, {SetStatus: 204}},
+		}},
+	}
+
+	var routes []transport.Router
+	templates := store.New(log, builder.NewTemplateBuilder(log),
+		func() ([]model.Template, error) { return []model.Template{orders}, nil },
+		func(templates []model.Template) {
+			routes = builder.BuildAll(log, nopProcessLogger{}, scenario.New(), options, templates)
+		},
+	)
+	require.NoError(t, templates.Reset())
+
+	a := New(zaptest.NewLogger(t), prefix, templates, nil, scenario.New(), sequences)
+
+	status := func() int {
+		for _, route := range routes {
+			if route.Path() == "/orders" {
+				rec := httptest.NewRecorder()
+				route.Handler(model.GET).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders", nil))
+				return rec.Code
+			}
+		}
+		return 0
+	}
+
+	assert.Equal(t, 201, status())
+	assert.Equal(t, 202, status())
+
+	// Adding an unrelated template rebuilds every route, the list continues.
+	rec := serve(a, http.MethodPut, prefix+"/templates/users", `{"Path": "/users", "Handle": [{"SetResponse": {"SetStatus": 200}}]}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, 203, status())
+
+	// A changed list starts over.
+	rec = serve(a, http.MethodPut, prefix+"/templates/orders.json",
+		`{"Path": "/orders", "Handle": [{"MatchRequest": {"MustMethod": "GET"}, "SetResponses": [{"SetStatus": 301}, {"SetStatus": 302}]}]}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 301, status())
+	assert.Equal(t, 302, status())
+
+	// The lists of a deleted template are dropped.
+	rec = serve(a, http.MethodDelete, prefix+"/templates/orders.json", "")
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	rec = serve(a, http.MethodPost, prefix+"/sequences/orders.json/reset", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+type nopProcessLogger struct{}
+
+func (nopProcessLogger) Log(*model.ProcessLoggingFileds) {}
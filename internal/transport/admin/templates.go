@@ -0,0 +1,114 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"mockium/internal/model"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// listTemplates responds with the current templates in routing order.
+func (inst *Admin) listTemplates(w http.ResponseWriter, r *http.Request) {
+	inst.writeJSON(w, http.StatusOK, inst.templates.List())
+}
+
+// getTemplate responds with the template identified by the path.
+func (inst *Admin) getTemplate(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	template, ok := inst.templates.Get(id)
+	if !ok {
+		inst.writeError(w, http.StatusNotFound, fmt.Errorf("template '%s' not found", id))
+		return
+	}
+
+	inst.writeJSON(w, http.StatusOK, template)
+}
+
+// createTemplate adds the template from the request body, or replaces the
+// template with the same ID. A template without ID gets a generated one.
+func (inst *Admin) createTemplate(w http.ResponseWriter, r *http.Request) {
+	template, err := inst.decodeTemplate(r)
+	if err != nil {
+		inst.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	inst.storeTemplate(w, template)
+}
+
+// putTemplate adds or replaces the template identified by the path.
+func (inst *Admin) putTemplate(w http.ResponseWriter, r *http.Request) {
+	template, err := inst.decodeTemplate(r)
+	if err != nil {
+		inst.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	template.ID = mux.Vars(r)["id"]
+
+	inst.storeTemplate(w, template)
+}
+
+// deleteTemplate deletes the template identified by the path.
+func (inst *Admin) deleteTemplate(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	deleted, err := inst.templates.Delete(id)
+	if err != nil {
+		inst.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !deleted {
+		inst.writeError(w, http.StatusNotFound, fmt.Errorf("template '%s' not found", id))
+		return
+	}
+
+	inst.log.Info("template deleted", zap.String("id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// resetTemplates drops all runtime changes and reloads the on-disk templates.
+func (inst *Admin) resetTemplates(w http.ResponseWriter, r *http.Request) {
+	if err := inst.templates.Reset(); err != nil {
+		inst.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	inst.log.Info("templates reset")
+	inst.writeJSON(w, http.StatusOK, inst.templates.List())
+}
+
+// storeTemplate puts the template into the store and responds with the stored template.
+func (inst *Admin) storeTemplate(w http.ResponseWriter, template model.Template) {
+	stored, replaced, err := inst.templates.Put(template)
+	if err != nil {
+		inst.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	inst.log.Info("template stored", zap.String("id", stored.ID), zap.String("path", stored.Path))
+
+	status := http.StatusCreated
+	if replaced {
+		status = http.StatusOK
+	}
+	inst.writeJSON(w, status, stored)
+}
+
+// decodeTemplate reads a model.Template from the JSON request body.
+func (inst *Admin) decodeTemplate(r *http.Request) (model.Template, error) {
+	template := model.Template{}
+	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+		return model.Template{}, fmt.Errorf("decode template: %w", err)
+	}
+
+	if template.Path == "" {
+		return model.Template{}, fmt.Errorf("template 'Path' is required")
+	}
+
+	return template, nil
+}
@@ -0,0 +1,20 @@
+package transport
+
+import "net/http"
+
+// Handle binds a request matcher to the response builder that serves it.
+// It keeps the position of the handle in its template and an optional
+// priority, so that handlers can evaluate handles in a deterministic order.
+// A handle bound to a scenario only matches in the required scenario state
+// and can move the scenario to a new state. A handle with a proxy forwards
+// the matched request to an upstream instead of writing the built response.
+type Handle struct {
+	Index                 int             // Position of the handle in the template's Handle list.
+	Priority              int             // Handles with a higher priority are checked first.
+	Scenario              string          // Name of the scenario the handle belongs to, empty if stateless.
+	RequiredScenarioState string          // Scenario state required to match, empty matches any state.
+	NewScenarioState      string          // Scenario state set once the handle is chosen, empty keeps the state.
+	Matcher               RequestMatcher  // Matcher deciding whether the handle applies to a request.
+	Builder               ResponseBuilder // Builder producing the response for a matched request.
+	Proxy                 http.Handler    // Upstream the matched request is forwarded to, nil if the handle responds itself.
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"crypto/rand"
+	"io"
+	"mockium/internal/model"
+	"net"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+// defaultGarbageSize is the number of random bytes sent by the garbage fault
+// when the template does not set GarbageSize.
+const defaultGarbageSize = 1024
+
+// abort injects a connection level fault: the connection is hijacked and closed
+// without a response, closed with a TCP reset, or sent random bytes before closing.
+//
+// If the connection cannot be hijacked (e.g. HTTP/2), the handler is aborted with
+// http.ErrAbortHandler, which makes the server drop the connection or reset the stream.
+func (inst *Handler) abort(w http.ResponseWriter, fault *model.FaultTemplate) {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+
+	conn, bufrw, err := hj.Hijack()
+	if err != nil {
+		inst.log.Error("hijack connection", zap.Error(err))
+		panic(http.ErrAbortHandler)
+	}
+	defer conn.Close()
+
+	switch fault.Type {
+	case model.FaultConnectionReset:
+		// A zero linger makes Close send RST instead of FIN.
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			if err := tcpConn.SetLinger(0); err != nil {
+				inst.log.Error("set connection linger", zap.Error(err))
+			}
+		}
+	case model.FaultGarbage:
+		size := fault.GarbageSize
+		if size == 0 {
+			size = defaultGarbageSize
+		}
+
+		garbage := make([]byte, size)
+		rand.Read(garbage)
+		if _, err := bufrw.Write(garbage); err == nil {
+			err = bufrw.Flush()
+		}
+		if err != nil {
+			inst.log.Error("write garbage", zap.Error(err))
+		}
+	}
+}
+
+// truncate advertises the full body length in Content-Length, sends only the first
+// fault.TruncateAfter bytes of the body and then closes the connection.
+//
+// Parameters:
+//   - w: the HTTP response writer.
+//   - status: the response status code.
+//   - body: the full response body.
+//   - size: the full body length.
+//   - fault: the truncate fault.
+func (inst *Handler) truncate(w http.ResponseWriter, status int, body io.Reader, size int64, fault *model.FaultTemplate) {
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.WriteHeader(status)
+
+	if _, err := io.CopyN(w, body, min(int64(fault.TruncateAfter), size)); err != nil {
+		inst.log.Error("write truncated body", zap.Error(err))
+	}
+
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		// The server closes the connection itself after a short body.
+		return
+	}
+
+	conn, _, err := hj.Hijack()
+	if err != nil {
+		inst.log.Error("hijack connection", zap.Error(err))
+		return
+	}
+	conn.Close()
+}
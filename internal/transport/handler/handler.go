@@ -6,10 +6,13 @@ import (
 	"io"
 	"mockium/internal/model"
 	"mockium/internal/service"
+	"mockium/internal/service/xmltree"
 	"mockium/internal/transport"
 	"net/http"
+	"sort"
 	"time"
 
+	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 )
 
@@ -18,24 +21,53 @@ import (
 // using associated response builders.
 type Handler struct {
 	log           *zap.Logger
-	matchers      map[transport.RequestMatcher]transport.ResponseBuilder
+	template      string
+	handles       []transport.Handle
+	stateful      bool
 	processLogger service.ProcessLogger
+	scenarios     service.ScenarioStore
+	fallback      http.Handler
 }
 
 // New creates a new instance of Handler.
 //
+// The handles are evaluated by descending priority; handles with equal
+// priority keep the order in which they were passed (the template order).
+//
 // Parameters:
 //   - log: a zap.Logger instance for logging request/response activity.
-//   - matchers: a map of RequestMatcher to corresponding ResponseBuilder.
+//   - proceLogger: a process logger receiving every served request.
+//   - scenarios: the scenario state store shared by all handlers.
+//   - template: ID of the template the handles belong to, written to the process log.
+//   - fallback: upstream proxy for the requests no handle matches, nil to respond 404.
+//   - handles: the request matchers paired with their response builders.
 //
 // Returns:
 //
 //	A pointer to an initialized Handler.
-func New(log *zap.Logger, proceLogger service.ProcessLogger, mathcers map[transport.RequestMatcher]transport.ResponseBuilder) *Handler {
+func New(log *zap.Logger, proceLogger service.ProcessLogger, scenarios service.ScenarioStore, template string, fallback http.Handler, handles []transport.Handle) *Handler {
+	ordered := make([]transport.Handle, len(handles))
+	copy(ordered, handles)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Priority > ordered[j].Priority
+	})
+
+	stateful := false
+	for _, handle := range ordered {
+		if handle.Scenario != "" {
+			stateful = true
+			break
+		}
+	}
+
 	return &Handler{
 		log:           log,
-		matchers:      mathcers,
+		template:      template,
+		handles:       ordered,
+		stateful:      stateful,
 		processLogger: proceLogger,
+		scenarios:     scenarios,
+		fallback:      fallback,
 	}
 }
 
@@ -43,17 +75,25 @@ func New(log *zap.Logger, proceLogger service.ProcessLogger, mathcers map[transp
 // against configured request matchers. If a match is found,
 // the corresponding response is built and sent.
 //
-// If no match is found, it responds with 404 Not Found.
+// If no match is found, the request is forwarded to the fallback upstream,
+// or it responds with 404 Not Found if there is none.
 // If an error occurs during response building, it responds with 500 Internal Server Error.
+// The scenario of the matched handle moves to its new state after the response is written;
+// it stays when building fails, the client goes away during the delay or a fault is injected.
 //
 // Parameters:
 //   - w: the HTTP response writer.
 //   - r: the HTTP request.
 func (inst *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	logReq := inst.buildLogRequest(r)
+	logReq := buildLogRequest(inst.log, inst.template, r)
 
-	resProvider := inst.findMatches(r)
-	if resProvider == nil {
+	handle, pending := inst.findMatches(r)
+	if handle == nil && inst.fallback != nil {
+		inst.proxy(w, r, inst.fallback, logReq)
+		return
+	}
+
+	if handle == nil {
 		logReq.Response.SetStatus = http.StatusNotFound
 		inst.processLogger.Log(logReq)
 		inst.log.Info("Serve HTTP", zap.Any("Request", logReq), zap.String("Response", "StatusNotFound"))
@@ -62,7 +102,10 @@ func (inst *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := resProvider.Build(r)
+	logReq.HandleIndex = &handle.Index
+	inst.log.Debug("matched handle", zap.Int("index", handle.Index), zap.Int("priority", handle.Priority))
+
+	response, err := handle.Builder.Build(r)
 	if err != nil {
 		logReq.Response.SetStatus = http.StatusInternalServerError
 		inst.processLogger.Log(logReq)
@@ -81,12 +124,54 @@ func (inst *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if response.SetFile != nil {
+		defer response.SetFile.Close()
+	}
+
+	logReq.Variant = response.SetVariant
+
+	if response.SetDelay > 0 && !inst.delay(r, response.SetDelay, logReq) {
+		logReq.Response = *response
+		inst.processLogger.Log(logReq)
+		inst.log.Info("Serve HTTP", zap.Any("Request", logReq), zap.String("Response", "RequestCancelled"))
+		return
+	}
+
+	if response.SetFault != nil {
+		logReq.Fault = response.SetFault.Type
+	}
+
+	if response.SetFault != nil && response.SetFault.Type != model.FaultTruncate {
+		logReq.Response = *response
+		inst.processLogger.Log(logReq)
+		inst.log.Info("Serve HTTP", zap.Any("Request", logReq), zap.String("Response", "Fault"))
+
+		inst.abort(w, response.SetFault)
+		return
+	}
+
+	// The scenario moves on once the response is written, a truncated response is a fault as well
+	if response.SetFault == nil {
+		defer inst.commit(pending)
+	}
+
+	if handle.Proxy != nil {
+		inst.proxy(w, r, handle.Proxy, logReq)
+		return
+	}
+
 	if response.SetHeaders != nil {
 		for k, v := range response.SetHeaders {
 			w.Header().Set(k, v)
 		}
 	}
 
+	for k, values := range response.SetHeaderValues {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+
 	status := http.StatusOK
 	if response.SetStatus != 0 {
 		status = response.SetStatus
@@ -95,31 +180,87 @@ func (inst *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	switch {
+	case response.SetStream != nil:
+		logReq.Response = *response
+		inst.processLogger.Log(logReq)
+		inst.log.Info("Serve HTTP", zap.Any("Request", logReq), zap.Any("Response", response))
+
+		inst.stream(w, r, status, response.SetStream)
+		return
 	case response.SetFile != nil:
 		logReq.Response = *response
 		inst.processLogger.Log(logReq)
 		inst.log.Info("Serve HTTP", zap.Any("Request", logReq), zap.Any("Response", response))
 
 		w.Header().Set("Content-Disposition", "attachment; filename="+response.SetFile.Name())
+
+		if response.SetFault != nil {
+			info, err := response.SetFile.Stat()
+			if err != nil {
+				inst.log.Error("stat response file", zap.Error(err))
+				http.Error(w, "failed prepare response", http.StatusInternalServerError)
+				return
+			}
+
+			inst.truncate(w, status, response.SetFile, info.Size(), response.SetFault)
+			return
+		}
+
+		if response.SetThrottle != nil {
+			info, err := response.SetFile.Stat()
+			if err != nil {
+				inst.log.Error("stat response file", zap.Error(err))
+				http.Error(w, "failed prepare response", http.StatusInternalServerError)
+				return
+			}
+
+			inst.throttle(w, r, status, response.SetFile, info.Size(), response.SetThrottle)
+			return
+		}
+
 		w.WriteHeader(status)
 		http.ServeFile(w, r, response.SetFile.Name())
 		return
-	case response.SetBody != nil:
+	case response.SetBody != nil, response.SetRawBody != "":
 		logReq.Response = *response
 		inst.processLogger.Log(logReq)
 		inst.log.Info("Serve HTTP", zap.Any("Request", logReq), zap.Any("Response", response))
 
-		bodyByte, err := json.Marshal(response.SetBody)
-		if err != nil {
-			inst.log.Info("Serve HTTP",
-				zap.Any("Request", logReq),
-				zap.String("Response", "StatusInternalServerError"),
-			)
-			http.Error(w, "failed prepare response", http.StatusInternalServerError)
+		bodyByte, contentType := []byte(response.SetRawBody), "text/plain; charset=utf-8"
+		if response.SetBody != nil {
+			// SetBody is written as XML when SetHeaders declare an XML Content-Type.
+			marshal := json.Marshal
+			contentType = "application/json"
+			if xml := w.Header().Get("Content-Type"); xmltree.IsXML(xml) {
+				marshal, contentType = xmltree.Marshal, xml
+			}
+
+			var err error
+			if bodyByte, err = marshal(response.SetBody); err != nil {
+				inst.log.Info("Serve HTTP",
+					zap.Any("Request", logReq),
+					zap.String("Response", "StatusInternalServerError"),
+				)
+				http.Error(w, "failed prepare response", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// Content-Type from SetHeaders takes precedence over the default one.
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+
+		if response.SetFault != nil {
+			inst.truncate(w, status, bytes.NewReader(bodyByte), int64(len(bodyByte)), response.SetFault)
+			return
+		}
+
+		if response.SetThrottle != nil {
+			inst.throttle(w, r, status, bytes.NewReader(bodyByte), int64(len(bodyByte)), response.SetThrottle)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(status)
 		w.Write(bodyByte)
 		return
@@ -132,25 +273,101 @@ func (inst *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(status)
 }
 
-// findMatches finds the first matching response builder for the incoming request
-// by iterating over the registered request matchers.
+// transition is the scenario state change of a matched handle, made once the response is written.
+type transition struct {
+	scenario string
+	from     string // State the scenario must still be in, empty for any state.
+	to       string
+}
+
+// findMatches finds the first matching handle for the incoming request
+// by iterating over the registered handles in priority order.
+//
+// Handles bound to a scenario are skipped unless the scenario is in the required
+// state. Matching happens under the scenario store lock, so that every handle
+// is checked against the same states. The new state of the chosen handle is
+// returned as a pending transition, the caller commits it after responding.
 //
 // Parameters:
 //   - req: the incoming HTTP request.
 //
 // Returns:
 //
-//	The first matching ResponseBuilder, or nil if no match is found.
-func (inst *Handler) findMatches(req *http.Request) transport.ResponseBuilder {
-	for reqMatcher, resProvider := range inst.matchers {
-		if reqMatcher.Match(req) {
-			return resProvider
+//	The first matching Handle and its pending transition, nil if the handle does not
+//	change a scenario; a nil Handle if no match is found.
+func (inst *Handler) findMatches(req *http.Request) (*transport.Handle, *transition) {
+	if inst.stateful {
+		inst.scenarios.Lock()
+		defer inst.scenarios.Unlock()
+	}
+
+	for i := range inst.handles {
+		handle := &inst.handles[i]
+		if handle.Scenario != "" && handle.RequiredScenarioState != "" &&
+			inst.scenarios.State(handle.Scenario) != handle.RequiredScenarioState {
+			continue
+		}
+
+		if handle.Matcher.Match(req) {
+			if handle.Scenario == "" || handle.NewScenarioState == "" {
+				return handle, nil
+			}
+			return handle, &transition{
+				scenario: handle.Scenario,
+				from:     handle.RequiredScenarioState,
+				to:       handle.NewScenarioState,
+			}
 		}
 	}
-	return nil
+	return nil, nil
+}
+
+// commit moves the scenario to the new state of the transition, if the scenario
+// is still in the state the handle was matched in. A concurrent request that
+// already moved the scenario on is not overwritten.
+func (inst *Handler) commit(pending *transition) {
+	if pending == nil {
+		return
+	}
+
+	inst.scenarios.Lock()
+	defer inst.scenarios.Unlock()
+
+	if pending.from != "" && inst.scenarios.State(pending.scenario) != pending.from {
+		inst.log.Debug("scenario state changed meanwhile, transition skipped",
+			zap.String("scenario", pending.scenario),
+			zap.String("state", inst.scenarios.State(pending.scenario)))
+		return
+	}
+
+	inst.scenarios.SetState(pending.scenario, pending.to)
+	inst.log.Debug("scenario state changed",
+		zap.String("scenario", pending.scenario),
+		zap.String("state", pending.to))
+}
+
+// delay waits for the response delay, or until the request context is cancelled
+// because the client went away. The delay actually applied is recorded in the
+// process log entry.
+//
+// Returns false if the request was cancelled during the delay.
+func (inst *Handler) delay(r *http.Request, d time.Duration, logReq *model.ProcessLoggingFileds) bool {
+	start := time.Now()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		logReq.Delay = time.Since(start).String()
+		return true
+	case <-r.Context().Done():
+		logReq.Delay = time.Since(start).String()
+		return false
+	}
 }
 
-func (inst *Handler) buildLogRequest(r *http.Request) *model.ProcessLoggingFileds {
+// buildLogRequest creates the process log entry of the request, the body is restored after reading.
+func buildLogRequest(log *zap.Logger, template string, r *http.Request) *model.ProcessLoggingFileds {
 	logReq := &model.LogginRequest{
 		Headers: make(map[string]any),
 	}
@@ -158,6 +375,7 @@ func (inst *Handler) buildLogRequest(r *http.Request) *model.ProcessLoggingFiled
 	logReq.Url = r.URL.String()
 	logReq.RemoteAddr = r.RemoteAddr
 	logReq.Method = r.Method
+	logReq.PathParams = mux.Vars(r)
 
 	for name, values := range r.Header {
 		logReq.Headers[name] = values
@@ -170,10 +388,11 @@ func (inst *Handler) buildLogRequest(r *http.Request) *model.ProcessLoggingFiled
 		logReq.Body = string(bodyBytes)
 	}
 
-	inst.log.Info("", zap.Any("Received Request", logReq))
+	log.Info("", zap.Any("Received Request", logReq))
 
 	return &model.ProcessLoggingFileds{
-		Time:    time.Now(),
-		Request: logReq,
+		Time:     time.Now(),
+		Request:  logReq,
+		Template: template,
 	}
 }
@@ -1,14 +1,23 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"mockium/internal/model"
+	"mockium/internal/service/scenario"
 	"mockium/internal/transport"
+	"mockium/internal/transport/websocket"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -37,20 +46,20 @@ func (ml *MockProcessLogger) Log(*model.ProcessLoggingFileds) {}
 
 func TestNewHandler(t *testing.T) {
 	log := zaptest.NewLogger(t)
-	matchers := make(map[transport.RequestMatcher]transport.ResponseBuilder)
+	handles := make([]transport.Handle, 0)
 
-	h := New(log, &MockProcessLogger{}, matchers)
+	h := New(log, &MockProcessLogger{}, scenario.New(), "test.json", nil, handles)
 
 	assert.NotNil(t, h)
 	assert.Equal(t, log, h.log)
-	assert.Len(t, h.matchers, 0)
+	assert.Len(t, h.handles, 0)
 }
 
 func TestServeHTTP_NotFound(t *testing.T) {
 	log := zaptest.NewLogger(t)
-	matchers := make(map[transport.RequestMatcher]transport.ResponseBuilder)
+	handles := make([]transport.Handle, 0)
 
-	h := New(log, &MockProcessLogger{}, matchers)
+	h := New(log, &MockProcessLogger{}, scenario.New(), "test.json", nil, handles)
 
 	req := httptest.NewRequest("GET", "/not-found", nil)
 	rec := httptest.NewRecorder()
@@ -75,11 +84,11 @@ func TestServeHTTP_InternalErrorOnPrepare(t *testing.T) {
 		},
 	}
 
-	matchers := map[transport.RequestMatcher]transport.ResponseBuilder{
-		matcher: provider,
+	handles := []transport.Handle{
+		{Matcher: matcher, Builder: provider},
 	}
 
-	h := New(log, &MockProcessLogger{}, matchers)
+	h := New(log, &MockProcessLogger{}, scenario.New(), "test.json", nil, handles)
 
 	req := httptest.NewRequest("GET", "/error", nil)
 	rec := httptest.NewRecorder()
@@ -109,11 +118,11 @@ func TestServeHTTP_JSONResponse(t *testing.T) {
 		},
 	}
 
-	matchers := map[transport.RequestMatcher]transport.ResponseBuilder{
-		matcher: provider,
+	handles := []transport.Handle{
+		{Matcher: matcher, Builder: provider},
 	}
 
-	h := New(log, &MockProcessLogger{}, matchers)
+	h := New(log, &MockProcessLogger{}, scenario.New(), "test.json", nil, handles)
 
 	req := httptest.NewRequest("GET", "/json", nil)
 	rec := httptest.NewRecorder()
@@ -149,11 +158,11 @@ func TestServeHTTP_FileResponse(t *testing.T) {
 		},
 	}
 
-	matchers := map[transport.RequestMatcher]transport.ResponseBuilder{
-		matcher: provider,
+	handles := []transport.Handle{
+		{Matcher: matcher, Builder: provider},
 	}
 
-	h := New(log, &MockProcessLogger{}, matchers)
+	h := New(log, &MockProcessLogger{}, scenario.New(), "test.json", nil, handles)
 
 	req := httptest.NewRequest("GET", "/file", nil)
 	rec := httptest.NewRecorder()
@@ -164,6 +173,86 @@ func TestServeHTTP_FileResponse(t *testing.T) {
 	assert.Contains(t, rec.Header().Get("Content-Disposition"), testFile.Name())
 }
 
+func TestServeHTTP_BodyTypes(t *testing.T) {
+	log := zaptest.NewLogger(t)
+
+	tests := []struct {
+		name        string
+		response    model.SetResponse
+		contentType string
+		body        string
+	}{
+		{
+			name:        "array",
+			response:    model.SetResponse{SetBody: []any{map[string]any{"id": 1}}},
+			contentType: "application/json",
+			body:        `[{"id":1}]`,
+		},
+		{
+			name:        "string",
+			response:    model.SetResponse{SetBody: "ok"},
+			contentType: "application/json",
+			body:        `"ok"`,
+		},
+		{
+			name:        "raw",
+			response:    model.SetResponse{SetRawBody: "plain text"},
+			contentType: "text/plain; charset=utf-8",
+			body:        "plain text",
+		},
+		{
+			name: "raw with content type",
+			response: model.SetResponse{
+				SetHeaders: map[string]string{"Content-Type": "text/html"},
+				SetRawBody: "<h1>Hello</h1>",
+			},
+			contentType: "text/html",
+			body:        "<h1>Hello</h1>",
+		},
+		{
+			name: "json with content type",
+			response: model.SetResponse{
+				SetHeaders: map[string]string{"Content-Type": "application/vnd.api+json"},
+				SetBody:    map[string]any{"data": nil},
+			},
+			contentType: "application/vnd.api+json",
+			body:        `{"data":null}`,
+		},
+		{
+			name: "xml with content type",
+			response: model.SetResponse{
+				SetHeaders: map[string]string{"Content-Type": "text/xml"},
+				SetBody:    map[string]any{"user": map[string]any{"@id": "1", "name": "Tom"}},
+			},
+			contentType: "text/xml",
+			body:        "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<user id=\"1\"><name>Tom</name></user>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &MockResponseProvider{
+				prepareFunc: func(req *http.Request) (*model.SetResponse, error) {
+					response := tt.response
+					return &response, nil
+				},
+			}
+			matcher := &MockRequestMatcher{
+				matchFunc: func(req *http.Request) bool { return true },
+			}
+
+			h := New(log, &MockProcessLogger{}, scenario.New(), "test.json", nil, []transport.Handle{{Matcher: matcher, Builder: provider}})
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.contentType, rec.Header().Get("Content-Type"))
+			assert.Equal(t, tt.body, rec.Body.String())
+		})
+	}
+}
+
 func TestServeHTTP_Headers(t *testing.T) {
 	log := zaptest.NewLogger(t)
 	testHeaders := map[string]string{"X-Test": "value"}
@@ -183,11 +272,11 @@ func TestServeHTTP_Headers(t *testing.T) {
 		},
 	}
 
-	matchers := map[transport.RequestMatcher]transport.ResponseBuilder{
-		matcher: provider,
+	handles := []transport.Handle{
+		{Matcher: matcher, Builder: provider},
 	}
 
-	h := New(log, &MockProcessLogger{}, matchers)
+	h := New(log, &MockProcessLogger{}, scenario.New(), "test.json", nil, handles)
 
 	req := httptest.NewRequest("GET", "/headers", nil)
 	rec := httptest.NewRecorder()
@@ -219,28 +308,858 @@ func TestFindMatches(t *testing.T) {
 		},
 	}
 
-	matchers := map[transport.RequestMatcher]transport.ResponseBuilder{
-		matcher1: provider,
-		matcher2: provider,
+	handles := []transport.Handle{
+		{Index: 0, Matcher: matcher1, Builder: provider},
+		{Index: 1, Matcher: matcher2, Builder: provider},
 	}
 
-	h := New(log, &MockProcessLogger{}, matchers)
+	h := New(log, &MockProcessLogger{}, scenario.New(), "test.json", nil, handles)
 
 	t.Run("match first", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "/first", nil)
-		res := h.findMatches(req)
-		assert.Equal(t, provider, res)
+		res, _ := h.findMatches(req)
+		require.NotNil(t, res)
+		assert.Equal(t, 0, res.Index)
+		assert.Equal(t, provider, res.Builder)
 	})
 
 	t.Run("match second", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "/second", nil)
-		res := h.findMatches(req)
-		assert.Equal(t, provider, res)
+		res, _ := h.findMatches(req)
+		require.NotNil(t, res)
+		assert.Equal(t, 1, res.Index)
+		assert.Equal(t, provider, res.Builder)
 	})
 
 	t.Run("no match", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "/unknown", nil)
-		res := h.findMatches(req)
+		res, _ := h.findMatches(req)
 		assert.Nil(t, res)
 	})
 }
+
+func TestFindMatches_Order(t *testing.T) {
+	log := zaptest.NewLogger(t)
+
+	matchAll := &MockRequestMatcher{
+		matchFunc: func(req *http.Request) bool { return true },
+	}
+
+	provider := &MockResponseProvider{
+		prepareFunc: func(req *http.Request) (*model.SetResponse, error) {
+			return &model.SetResponse{}, nil
+		},
+	}
+
+	t.Run("template order", func(t *testing.T) {
+		handles := []transport.Handle{
+			{Index: 0, Matcher: matchAll, Builder: provider},
+			{Index: 1, Matcher: matchAll, Builder: provider},
+			{Index: 2, Matcher: matchAll, Builder: provider},
+		}
+
+		h := New(log, &MockProcessLogger{}, scenario.New(), "test.json", nil, handles)
+
+		for i := 0; i < 100; i++ {
+			res, _ := h.findMatches(httptest.NewRequest("GET", "/", nil))
+			require.NotNil(t, res)
+			assert.Equal(t, 0, res.Index)
+		}
+	})
+
+	t.Run("priority wins over template order", func(t *testing.T) {
+		handles := []transport.Handle{
+			{Index: 0, Matcher: matchAll, Builder: provider},
+			{Index: 1, Priority: 10, Matcher: matchAll, Builder: provider},
+			{Index: 2, Priority: 10, Matcher: matchAll, Builder: provider},
+		}
+
+		h := New(log, &MockProcessLogger{}, scenario.New(), "test.json", nil, handles)
+
+		res, _ := h.findMatches(httptest.NewRequest("GET", "/", nil))
+		require.NotNil(t, res)
+		assert.Equal(t, 1, res.Index)
+	})
+}
+
+type RecordProcessLogger struct {
+	logs []*model.ProcessLoggingFileds
+}
+
+func (rl *RecordProcessLogger) Log(l *model.ProcessLoggingFileds) { rl.logs = append(rl.logs, l) }
+
+func TestServeHTTP_LogsHandleIndex(t *testing.T) {
+	log := zaptest.NewLogger(t)
+	procLogger := &RecordProcessLogger{}
+
+	matcher := &MockRequestMatcher{
+		matchFunc: func(req *http.Request) bool { return true },
+	}
+
+	provider := &MockResponseProvider{
+		prepareFunc: func(req *http.Request) (*model.SetResponse, error) {
+			return &model.SetResponse{}, nil
+		},
+	}
+
+	h := New(log, procLogger, scenario.New(), "test.json", nil, []transport.Handle{{Index: 3, Matcher: matcher, Builder: provider}})
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	require.Len(t, procLogger.logs, 1)
+	require.NotNil(t, procLogger.logs[0].HandleIndex)
+	assert.Equal(t, 3, *procLogger.logs[0].HandleIndex)
+	assert.Equal(t, "test.json", procLogger.logs[0].Template)
+}
+
+func TestServeHTTP_LogsVariant(t *testing.T) {
+	log := zaptest.NewLogger(t)
+	procLogger := &RecordProcessLogger{}
+
+	matcher := &MockRequestMatcher{
+		matchFunc: func(req *http.Request) bool { return true },
+	}
+
+	variant := 2
+	provider := &MockResponseProvider{
+		prepareFunc: func(req *http.Request) (*model.SetResponse, error) {
+			return &model.SetResponse{SetStatus: http.StatusTooManyRequests, SetVariant: &variant}, nil
+		},
+	}
+
+	h := New(log, procLogger, scenario.New(), "test.json", nil, []transport.Handle{{Matcher: matcher, Builder: provider}})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Len(t, procLogger.logs, 1)
+	require.NotNil(t, procLogger.logs[0].Variant)
+	assert.Equal(t, 2, *procLogger.logs[0].Variant)
+}
+
+func TestServeHTTP_Scenario(t *testing.T) {
+	log := zaptest.NewLogger(t)
+	scenarios := scenario.New()
+
+	matchPath := func(path string) *MockRequestMatcher {
+		return &MockRequestMatcher{
+			matchFunc: func(req *http.Request) bool { return req.URL.Path == path },
+		}
+	}
+
+	status := func(code int) *MockResponseProvider {
+		return &MockResponseProvider{
+			prepareFunc: func(req *http.Request) (*model.SetResponse, error) {
+				return &model.SetResponse{SetStatus: code}, nil
+			},
+		}
+	}
+
+	handles := []transport.Handle{
+		{Index: 0, Scenario: "order", RequiredScenarioState: scenario.StartedState, NewScenarioState: "pending", Matcher: matchPath("/create"), Builder: status(http.StatusCreated)},
+		{Index: 1, Scenario: "order", RequiredScenarioState: "pending", Matcher: matchPath("/order"), Builder: status(http.StatusAccepted)},
+		{Index: 2, Scenario: "order", RequiredScenarioState: "pending", NewScenarioState: "paid", Matcher: matchPath("/pay"), Builder: status(http.StatusOK)},
+		{Index: 3, Scenario: "order", RequiredScenarioState: "paid", Matcher: matchPath("/order"), Builder: status(http.StatusOK)},
+	}
+
+	h := New(log, &MockProcessLogger{}, scenarios, "order.json", nil, handles)
+
+	serve := func(path string) int {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusNotFound, serve("/order"))
+	assert.Equal(t, http.StatusCreated, serve("/create"))
+	assert.Equal(t, http.StatusNotFound, serve("/create"))
+	assert.Equal(t, http.StatusAccepted, serve("/order"))
+	assert.Equal(t, http.StatusOK, serve("/pay"))
+	assert.Equal(t, http.StatusOK, serve("/order"))
+	assert.Equal(t, map[string]string{"order": "paid"}, scenarios.States())
+
+	scenarios.ResetAll()
+	assert.Equal(t, http.StatusCreated, serve("/create"))
+}
+
+func TestServeHTTP_ScenarioTransitionAfterResponse(t *testing.T) {
+	log := zaptest.NewLogger(t)
+
+	matchAll := &MockRequestMatcher{
+		matchFunc: func(req *http.Request) bool { return true },
+	}
+
+	tests := []struct {
+		name     string
+		prepare  func(req *http.Request) (*model.SetResponse, error)
+		cancel   bool
+		expected int
+		state    string
+	}{
+		{
+			name: "responded",
+			prepare: func(req *http.Request) (*model.SetResponse, error) {
+				return &model.SetResponse{SetStatus: http.StatusCreated}, nil
+			},
+			expected: http.StatusCreated,
+			state:    "pending",
+		},
+		{
+			name:     "build error",
+			prepare:  func(req *http.Request) (*model.SetResponse, error) { return nil, errors.New("bad placeholder") },
+			expected: http.StatusInternalServerError,
+			state:    scenario.StartedState,
+		},
+		{
+			name: "fault",
+			prepare: func(req *http.Request) (*model.SetResponse, error) {
+				return &model.SetResponse{SetRawBody: "truncated", SetFault: &model.FaultTemplate{Type: model.FaultTruncate, TruncateAfter: 4}}, nil
+			},
+			expected: http.StatusOK,
+			state:    scenario.StartedState,
+		},
+		{
+			name: "cancelled delay",
+			prepare: func(req *http.Request) (*model.SetResponse, error) {
+				return &model.SetResponse{SetDelay: time.Second}, nil
+			},
+			cancel:   true,
+			expected: http.StatusOK,
+			state:    scenario.StartedState,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenarios := scenario.New()
+			scenarios.Register("order")
+			handles := []transport.Handle{{
+				Scenario:              "order",
+				RequiredScenarioState: scenario.StartedState,
+				NewScenarioState:      "pending",
+				Matcher:               matchAll,
+				Builder:               &MockResponseProvider{prepareFunc: tt.prepare},
+			}}
+			h := New(log, &MockProcessLogger{}, scenarios, "order.json", nil, handles)
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.cancel {
+				ctx, cancel := context.WithCancel(req.Context())
+				cancel()
+				req = req.WithContext(ctx)
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expected, rec.Code)
+			assert.Equal(t, map[string]string{"order": tt.state}, scenarios.States())
+		})
+	}
+
+	t.Run("state changed meanwhile", func(t *testing.T) {
+		scenarios := scenario.New()
+		handles := []transport.Handle{{
+			Scenario:              "order",
+			RequiredScenarioState: scenario.StartedState,
+			NewScenarioState:      "pending",
+			Matcher:               matchAll,
+			Builder: &MockResponseProvider{prepareFunc: func(req *http.Request) (*model.SetResponse, error) {
+				// Another request moves the scenario on while this one is served.
+				scenarios.Update("order", "paid")
+				return &model.SetResponse{}, nil
+			}},
+		}}
+		h := New(log, &MockProcessLogger{}, scenarios, "order.json", nil, handles)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, map[string]string{"order": "paid"}, scenarios.States())
+	})
+}
+
+func TestServeHTTP_ScenarioConcurrent(t *testing.T) {
+	log := zaptest.NewLogger(t)
+
+	matchAll := &MockRequestMatcher{
+		matchFunc: func(req *http.Request) bool { return true },
+	}
+
+	provider := &MockResponseProvider{
+		prepareFunc: func(req *http.Request) (*model.SetResponse, error) {
+			return &model.SetResponse{}, nil
+		},
+	}
+
+	handles := []transport.Handle{
+		{Index: 0, Scenario: "once", RequiredScenarioState: scenario.StartedState, NewScenarioState: "done", Matcher: matchAll, Builder: provider},
+	}
+
+	scenarios := scenario.New()
+	h := New(log, &MockProcessLogger{}, scenarios, "once.json", nil, handles)
+
+	var wg sync.WaitGroup
+	var ok atomic.Int32
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+			if rec.Code == http.StatusOK {
+				ok.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The requests matched before the first response was written are served as well,
+	// afterwards the scenario is done.
+	assert.GreaterOrEqual(t, ok.Load(), int32(1))
+	assert.Equal(t, map[string]string{"once": "done"}, scenarios.States())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServeHTTP_Delay(t *testing.T) {
+	log := zaptest.NewLogger(t)
+	procLogger := &RecordProcessLogger{}
+
+	matcher := &MockRequestMatcher{
+		matchFunc: func(req *http.Request) bool { return true },
+	}
+
+	provider := &MockResponseProvider{
+		prepareFunc: func(req *http.Request) (*model.SetResponse, error) {
+			return &model.SetResponse{SetStatus: http.StatusOK, SetDelay: 50 * time.Millisecond}, nil
+		},
+	}
+
+	h := New(log, procLogger, scenario.New(), "test.json", nil, []transport.Handle{{Matcher: matcher, Builder: provider}})
+
+	t.Run("delayed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		start := time.Now()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		require.NotEmpty(t, procLogger.logs)
+		applied, err := time.ParseDuration(procLogger.logs[len(procLogger.logs)-1].Delay)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, applied, 50*time.Millisecond)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+
+		rec := httptest.NewRecorder()
+		start := time.Now()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+
+		assert.Less(t, time.Since(start), 50*time.Millisecond)
+		assert.Empty(t, rec.Body.String())
+
+		applied, err := time.ParseDuration(procLogger.logs[len(procLogger.logs)-1].Delay)
+		require.NoError(t, err)
+		assert.Less(t, applied, 50*time.Millisecond)
+	})
+}
+
+func TestServeHTTP_Fault(t *testing.T) {
+	log := zaptest.NewLogger(t)
+
+	matcher := &MockRequestMatcher{
+		matchFunc: func(req *http.Request) bool { return true },
+	}
+
+	newServer := func(fault *model.FaultTemplate) *httptest.Server {
+		provider := &MockResponseProvider{
+			prepareFunc: func(req *http.Request) (*model.SetResponse, error) {
+				return &model.SetResponse{
+					SetStatus: http.StatusOK,
+					SetBody:   map[string]any{"message": "hello, world"},
+					SetFault:  fault,
+				}, nil
+			},
+		}
+
+		srv := httptest.NewServer(New(log, &MockProcessLogger{}, scenario.New(), "test.json", nil,
+			[]transport.Handle{{Matcher: matcher, Builder: provider}}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	for _, faultType := range []string{model.FaultCloseConnection, model.FaultConnectionReset, model.FaultGarbage} {
+		t.Run(faultType, func(t *testing.T) {
+			srv := newServer(&model.FaultTemplate{Type: faultType, GarbageSize: 16})
+
+			resp, err := http.Get(srv.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run(model.FaultTruncate, func(t *testing.T) {
+		srv := newServer(&model.FaultTemplate{Type: model.FaultTruncate, TruncateAfter: 5})
+
+		resp, err := http.Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		full, _ := json.Marshal(map[string]any{"message": "hello, world"})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int64(len(full)), resp.ContentLength)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.Equal(t, full[:5], body)
+	})
+}
+
+func TestServeHTTP_FaultLogged(t *testing.T) {
+	log := zaptest.NewLogger(t)
+	procLogger := &RecordProcessLogger{}
+
+	matcher := &MockRequestMatcher{
+		matchFunc: func(req *http.Request) bool { return true },
+	}
+
+	provider := &MockResponseProvider{
+		prepareFunc: func(req *http.Request) (*model.SetResponse, error) {
+			return &model.SetResponse{SetFault: &model.FaultTemplate{Type: model.FaultCloseConnection}}, nil
+		},
+	}
+
+	h := New(log, procLogger, scenario.New(), "test.json", nil, []transport.Handle{{Matcher: matcher, Builder: provider}})
+
+	// The recorder cannot be hijacked, so the handler is aborted instead.
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	})
+
+	require.Len(t, procLogger.logs, 1)
+	assert.Equal(t, model.FaultCloseConnection, procLogger.logs[0].Fault)
+}
+
+func TestServeHTTP_Proxy(t *testing.T) {
+	log := zaptest.NewLogger(t)
+	procLogger := &RecordProcessLogger{}
+
+	upstream := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusTeapot)
+			w.Write([]byte(name + ":" + string(body)))
+		})
+	}
+
+	matcher := &MockRequestMatcher{
+		matchFunc: func(req *http.Request) bool {
+			// Consumes the body the way form parsing does.
+			io.ReadAll(req.Body)
+			return req.URL.Path == "/proxy"
+		},
+	}
+
+	provider := &MockResponseProvider{
+		prepareFunc: func(req *http.Request) (*model.SetResponse, error) {
+			return &model.SetResponse{}, nil
+		},
+	}
+
+	h := New(log, procLogger, scenario.New(), "test.json", upstream("fallback"),
+		[]transport.Handle{{Matcher: matcher, Builder: provider, Proxy: upstream("handle")}})
+
+	t.Run("handle", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("POST", "/proxy", strings.NewReader("body")))
+
+		assert.Equal(t, http.StatusTeapot, rec.Code)
+		assert.Equal(t, "handle:body", rec.Body.String())
+
+		last := procLogger.logs[len(procLogger.logs)-1]
+		assert.True(t, last.Proxied)
+		assert.Equal(t, http.StatusTeapot, last.Response.SetStatus)
+		require.NotNil(t, last.HandleIndex)
+	})
+
+	t.Run("unmatched", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("POST", "/other", strings.NewReader("body")))
+
+		assert.Equal(t, http.StatusTeapot, rec.Code)
+		assert.Equal(t, "fallback:body", rec.Body.String())
+
+		last := procLogger.logs[len(procLogger.logs)-1]
+		assert.True(t, last.Proxied)
+		assert.Nil(t, last.HandleIndex)
+	})
+}
+
+type MockRequestValidator struct {
+	violations []model.Violation
+}
+
+func (m *MockRequestValidator) Validate(*http.Request) []model.Violation { return m.violations }
+
+func TestValidation(t *testing.T) {
+	log := zaptest.NewLogger(t)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("mock"))
+	})
+
+	rec := httptest.NewRecorder()
+	NewValidation(log, &MockProcessLogger{}, &MockRequestValidator{}, http.StatusBadRequest, next).
+		ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pets", nil))
+	assert.Equal(t, "mock", rec.Body.String())
+
+	violations := []model.Violation{{In: "query", Name: "limit", Message: "must be integer"}}
+	logger := &RecordProcessLogger{}
+	rec = httptest.NewRecorder()
+	NewValidation(log, logger, &MockRequestValidator{violations: violations}, http.StatusUnprocessableEntity, next).
+		ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pets?limit=ten", nil))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+	var problem map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, "Unprocessable Entity", problem["title"])
+	assert.Equal(t, float64(http.StatusUnprocessableEntity), problem["status"])
+	assert.Equal(t, []any{map[string]any{"in": "query", "name": "limit", "message": "must be integer"}}, problem["violations"])
+
+	require.Len(t, logger.logs, 1)
+	assert.Equal(t, violations, logger.logs[0].Violations)
+	assert.Equal(t, http.StatusUnprocessableEntity, logger.logs[0].Response.SetStatus)
+}
+
+func TestServeHTTP_Stream(t *testing.T) {
+	log := zaptest.NewLogger(t)
+
+	matcher := &MockRequestMatcher{
+		matchFunc: func(req *http.Request) bool { return true },
+	}
+
+	newHandler := func(stream *model.Stream) *Handler {
+		provider := &MockResponseProvider{
+			prepareFunc: func(req *http.Request) (*model.SetResponse, error) {
+				return &model.SetResponse{SetStatus: http.StatusOK, SetStream: stream}, nil
+			},
+		}
+		return New(log, &MockProcessLogger{}, scenario.New(), "test.json", nil, []transport.Handle{{Matcher: matcher, Builder: provider}})
+	}
+
+	t.Run("events", func(t *testing.T) {
+		h := newHandler(&model.Stream{
+			Repeat: 2,
+			Events: []model.StreamEvent{
+				{Event: "update", ID: "1", Data: "{\"n\":1}"},
+				{Data: "line 1\nline 2", Delay: 5 * time.Millisecond},
+			},
+		})
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+		assert.True(t, rec.Flushed)
+
+		pass := "id: 1\nevent: update\ndata: {\"n\":1}\n\ndata: line 1\ndata: line 2\n\n"
+		assert.Equal(t, pass+pass, rec.Body.String())
+	})
+
+	t.Run("keep-alive", func(t *testing.T) {
+		h := newHandler(&model.Stream{
+			Repeat:    1,
+			KeepAlive: 10 * time.Millisecond,
+			Events:    []model.StreamEvent{{Data: "done", Delay: 35 * time.Millisecond}},
+		})
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+		assert.Contains(t, rec.Body.String(), ": keep-alive\n\n")
+		assert.True(t, strings.HasSuffix(rec.Body.String(), "data: done\n\n"))
+	})
+
+	t.Run("client disconnects", func(t *testing.T) {
+		h := newHandler(&model.Stream{
+			Loop:   true,
+			Events: []model.StreamEvent{{Data: "tick", Delay: 5 * time.Millisecond}},
+		})
+
+		srv := httptest.NewServer(h)
+		defer srv.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		req, err := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		buf := make([]byte, len("data: tick\n\n"))
+		_, err = io.ReadFull(resp.Body, buf)
+		require.NoError(t, err)
+		assert.Equal(t, "data: tick\n\n", string(buf))
+
+		cancel()
+		done := make(chan struct{})
+		go func() {
+			srv.Close()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("stream did not stop after the client disconnected")
+		}
+	})
+}
+
+type MockMessageMatcher struct {
+	matchFunc func(message []byte) bool
+}
+
+func (m *MockMessageMatcher) Match(message []byte) bool {
+	return m.matchFunc(message)
+}
+
+// messageBuilder builds a message with the given body, or the body returned for the request.
+func messageBuilder(body func(req *http.Request) any) *MockResponseProvider {
+	return &MockResponseProvider{
+		prepareFunc: func(req *http.Request) (*model.SetResponse, error) {
+			return &model.SetResponse{SetBody: body(req)}, nil
+		},
+	}
+}
+
+// dialWebSocket connects to the handler, the test waits for the handler to return,
+// since httptest does not track the hijacked connections.
+func dialWebSocket(t *testing.T, h http.Handler) *websocket.Conn {
+	var served sync.WaitGroup
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served.Add(1)
+		defer served.Done()
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(served.Wait)
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, srv.URL+"/ws?user=alice", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	return conn
+}
+
+func TestWebSocket_Script(t *testing.T) {
+	log := zaptest.NewLogger(t)
+	procLogger := &RecordProcessLogger{}
+
+	script := &transport.WebSocketScript{
+		OnConnect: []transport.WebSocketMessage{{
+			Builder: messageBuilder(func(req *http.Request) any { return "welcome " + req.URL.Query().Get("user") }),
+		}},
+		OnMessage: []transport.WebSocketReply{{
+			Matcher: &MockMessageMatcher{matchFunc: func(message []byte) bool { return string(message) == "ping" }},
+			Messages: []transport.WebSocketMessage{{
+				Builder: messageBuilder(func(req *http.Request) any {
+					body, _ := io.ReadAll(req.Body)
+					return map[string]any{"echo": string(body)}
+				}),
+				Delay: 5 * time.Millisecond,
+			}},
+		}, {
+			Matcher: &MockMessageMatcher{matchFunc: func(message []byte) bool { return string(message) == "bye" }},
+			Close:   &transport.WebSocketClose{Code: 4001, Reason: "done"},
+		}},
+	}
+
+	conn := dialWebSocket(t, NewWebSocket(log, procLogger, "ws.yaml", script, nil))
+
+	_, message, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "welcome alice", string(message))
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("unknown")))
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("ping")))
+	_, message, err = conn.ReadMessage()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"echo": "ping"}`, string(message))
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("bye")))
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, 4001, closeErr.Code)
+	assert.Equal(t, "done", closeErr.Reason)
+
+	require.Len(t, procLogger.logs, 1)
+	assert.Equal(t, http.StatusSwitchingProtocols, procLogger.logs[0].Response.SetStatus)
+	assert.Equal(t, "ws.yaml", procLogger.logs[0].Template)
+}
+
+func TestWebSocket_TimersAndClose(t *testing.T) {
+	log := zaptest.NewLogger(t)
+
+	script := &transport.WebSocketScript{
+		Timers: []transport.WebSocketTimer{{
+			Every:   5 * time.Millisecond,
+			Times:   2,
+			Builder: messageBuilder(func(*http.Request) any { return "tick" }),
+		}},
+		Close: &transport.WebSocketClose{Code: websocket.CloseNormal, After: 50 * time.Millisecond},
+	}
+
+	conn := dialWebSocket(t, NewWebSocket(log, &MockProcessLogger{}, "ws.yaml", script, nil))
+
+	var messages []string
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			var closeErr *websocket.CloseError
+			require.ErrorAs(t, err, &closeErr)
+			assert.Equal(t, websocket.CloseNormal, closeErr.Code)
+			break
+		}
+		messages = append(messages, string(message))
+	}
+	assert.Equal(t, []string{"tick", "tick"}, messages)
+}
+
+func TestWebSocket_NotUpgrade(t *testing.T) {
+	log := zaptest.NewLogger(t)
+	script := &transport.WebSocketScript{}
+
+	rec := httptest.NewRecorder()
+	NewWebSocket(log, &MockProcessLogger{}, "ws.yaml", script, nil).ServeHTTP(rec, httptest.NewRequest("GET", "/ws", nil))
+	assert.Equal(t, http.StatusUpgradeRequired, rec.Code)
+	assert.Equal(t, "websocket", rec.Header().Get("Upgrade"))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) })
+	rec = httptest.NewRecorder()
+	NewWebSocket(log, &MockProcessLogger{}, "ws.yaml", script, next).ServeHTTP(rec, httptest.NewRequest("GET", "/ws", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+}
+
+// writesRecorder records every write to the response body.
+type writesRecorder struct {
+	*httptest.ResponseRecorder
+	writes []string
+}
+
+func (rec *writesRecorder) Write(b []byte) (int, error) {
+	rec.writes = append(rec.writes, string(b))
+	return rec.ResponseRecorder.Write(b)
+}
+
+func TestServeHTTP_Throttle(t *testing.T) {
+	log := zaptest.NewLogger(t)
+
+	matcher := &MockRequestMatcher{
+		matchFunc: func(req *http.Request) bool { return true },
+	}
+
+	newHandler := func(response func() *model.SetResponse) *Handler {
+		provider := &MockResponseProvider{
+			prepareFunc: func(req *http.Request) (*model.SetResponse, error) { return response(), nil },
+		}
+		return New(log, &MockProcessLogger{}, scenario.New(), "test.json", nil, []transport.Handle{{Matcher: matcher, Builder: provider}})
+	}
+
+	t.Run("chunks", func(t *testing.T) {
+		h := newHandler(func() *model.SetResponse {
+			return &model.SetResponse{
+				SetStatus:   http.StatusCreated,
+				SetRawBody:  "abcdefghij",
+				SetThrottle: &model.Throttle{Chunks: 3, ChunkDelay: 20 * time.Millisecond},
+			}
+		})
+
+		rec := &writesRecorder{ResponseRecorder: httptest.NewRecorder()}
+		start := time.Now()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+		assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "10", rec.Header().Get("Content-Length"))
+		assert.Equal(t, []string{"abcd", "efgh", "ij"}, rec.writes)
+		assert.True(t, rec.Flushed)
+	})
+
+	t.Run("rate", func(t *testing.T) {
+		body := map[string]any{"data": strings.Repeat("x", 290)}
+		h := newHandler(func() *model.SetResponse {
+			return &model.SetResponse{SetBody: body, SetThrottle: &model.Throttle{BytesPerSecond: 3000}}
+		})
+
+		rec := &writesRecorder{ResponseRecorder: httptest.NewRecorder()}
+		start := time.Now()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+		// 301 bytes at 3000 bytes per second, in writes of 300 bytes.
+		assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+		assert.Len(t, rec.writes, 2)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		expected, _ := json.Marshal(body)
+		assert.Equal(t, string(expected), rec.Body.String())
+	})
+
+	t.Run("file", func(t *testing.T) {
+		var file *os.File
+		h := newHandler(func() *model.SetResponse {
+			var err error
+			file, err = os.Open("testdata/file.txt")
+			require.NoError(t, err)
+			return &model.SetResponse{SetFile: file, SetThrottle: &model.Throttle{Chunks: 2}}
+		})
+
+		rec := &writesRecorder{ResponseRecorder: httptest.NewRecorder()}
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+		content, err := os.ReadFile("testdata/file.txt")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, fmt.Sprint(len(content)), rec.Header().Get("Content-Length"))
+		assert.Equal(t, string(content), rec.Body.String())
+		assert.Len(t, rec.writes, 2)
+
+		_, err = file.Stat()
+		assert.ErrorIs(t, err, os.ErrClosed)
+	})
+
+	t.Run("client disconnects", func(t *testing.T) {
+		h := newHandler(func() *model.SetResponse {
+			return &model.SetResponse{SetRawBody: strings.Repeat("x", 100), SetThrottle: &model.Throttle{BytesPerSecond: 10}}
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		rec := httptest.NewRecorder()
+		start := time.Now()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, "x", rec.Body.String())
+	})
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"io"
+	"mockium/internal/model"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// proxy forwards the request to the upstream and writes the upstream response.
+// The exchange is logged as proxied, with the status returned by the upstream.
+//
+// Parameters:
+//   - w: the HTTP response writer.
+//   - r: the HTTP request.
+//   - upstream: the reverse proxy to the upstream.
+//   - logReq: the process log entry of the request.
+func (inst *Handler) proxy(w http.ResponseWriter, r *http.Request, upstream http.Handler, logReq *model.ProcessLoggingFileds) {
+	// Matchers may have consumed the body, e.g. by parsing a form, so it is restored from the log entry.
+	if body, ok := logReq.Request.Body.(string); ok {
+		r.Body = io.NopCloser(strings.NewReader(body))
+		r.ContentLength = int64(len(body))
+	}
+
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	upstream.ServeHTTP(sw, r)
+
+	logReq.Proxied = true
+	logReq.Response.SetStatus = sw.status
+	inst.processLogger.Log(logReq)
+	inst.log.Info("Serve HTTP", zap.Any("Request", logReq), zap.String("Response", "Proxied"))
+}
+
+// statusWriter keeps the status code written by the upstream proxy.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (inst *statusWriter) WriteHeader(status int) {
+	inst.status = status
+	inst.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the flusher of the underlying writer.
+func (inst *statusWriter) Unwrap() http.ResponseWriter {
+	return inst.ResponseWriter
+}
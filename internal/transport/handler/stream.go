@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"mockium/internal/model"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// contentTypeEventStream is the Content-Type of a Server-Sent Events stream.
+const contentTypeEventStream = "text/event-stream"
+
+// stream sends the events of a Server-Sent Events stream, flushing each one.
+// Keep-alive comments are sent while waiting for the delay of the next event.
+// The stream stops when all events are sent, or when the client disconnects.
+//
+// Parameters:
+//   - w: the HTTP response writer.
+//   - r: the HTTP request, its context is cancelled when the client disconnects.
+//   - status: the status of the response.
+//   - stream: the events to send.
+func (inst *Handler) stream(w http.ResponseWriter, r *http.Request, status int, stream *model.Stream) {
+	controller := http.NewResponseController(w)
+
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", contentTypeEventStream)
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(status)
+
+	if err := controller.Flush(); err != nil {
+		inst.log.Error("flush stream", zap.Error(err))
+		return
+	}
+
+	for pass := 0; stream.Loop || pass < stream.Repeat; pass++ {
+		for _, event := range stream.Events {
+			if event.Delay > 0 && !inst.wait(w, controller, r, event.Delay, stream.KeepAlive) {
+				inst.log.Debug("stream closed by client", zap.String("url", r.URL.Path))
+				return
+			}
+
+			if _, err := io.WriteString(w, formatEvent(event)); err != nil {
+				inst.log.Debug("write stream event", zap.Error(err))
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				inst.log.Debug("flush stream event", zap.Error(err))
+				return
+			}
+		}
+	}
+}
+
+// wait waits for the delay, sending a keep-alive comment at every interval if it is set.
+//
+// Returns false if the client disconnected or a comment cannot be sent.
+func (inst *Handler) wait(w http.ResponseWriter, controller *http.ResponseController, r *http.Request, delay, keepAlive time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var tick <-chan time.Time
+	if keepAlive > 0 {
+		ticker := time.NewTicker(keepAlive)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-timer.C:
+			return true
+		case <-r.Context().Done():
+			return false
+		case <-tick:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return false
+			}
+			if err := controller.Flush(); err != nil {
+				return false
+			}
+		}
+	}
+}
+
+// formatEvent writes the event in the text/event-stream format, a data line per line of its data.
+func formatEvent(event model.StreamEvent) string {
+	var b strings.Builder
+	if event.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", event.ID)
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", event.Event)
+	}
+	for _, line := range strings.Split(event.Data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"mockium/internal/model"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// throttleWritesPerSecond is the number of writes per second of a body with a rate limit,
+// so that the client receives the body steadily rather than in bursts.
+const throttleWritesPerSecond = 10
+
+// throttle advertises the full body length in Content-Length and sends the body
+// slowly, at the rate or in the chunks of the throttle. Sending stops when the client
+// disconnects.
+//
+// Parameters:
+//   - w: the HTTP response writer.
+//   - r: the HTTP request, its context is cancelled when the client disconnects.
+//   - status: the response status code.
+//   - body: the full response body.
+//   - size: the full body length.
+//   - throttle: the rate and chunks of the body.
+func (inst *Handler) throttle(w http.ResponseWriter, r *http.Request, status int, body io.Reader, size int64, throttle *model.Throttle) {
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.WriteHeader(status)
+
+	if _, err := io.Copy(newThrottledWriter(w, r.Context(), throttle, size), body); err != nil {
+		inst.log.Debug("write throttled body", zap.Error(err))
+	}
+}
+
+// throttledWriter writes to the response at a limited rate, or in chunks with pauses
+// between them, and flushes every write so that the client receives it at once.
+type throttledWriter struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+	ctx        context.Context
+	rate       int64         // Maximum bytes per second, 0 for no limit.
+	piece      int           // Largest single write with a rate limit.
+	chunk      int64         // Size of a chunk, 0 to write without chunks.
+	pause      time.Duration // Pause before every chunk but the first.
+	start      time.Time     // Time of the first write.
+	written    int64         // Bytes written so far.
+}
+
+// newThrottledWriter creates a throttledWriter for a body of the given size,
+// which is split into throttle.Chunks chunks of equal size.
+func newThrottledWriter(w http.ResponseWriter, ctx context.Context, throttle *model.Throttle, size int64) *throttledWriter {
+	writer := &throttledWriter{
+		w:          w,
+		controller: http.NewResponseController(w),
+		ctx:        ctx,
+		rate:       throttle.BytesPerSecond,
+		piece:      int(max(throttle.BytesPerSecond/throttleWritesPerSecond, 1)),
+		pause:      throttle.ChunkDelay,
+	}
+
+	if throttle.Chunks > 0 {
+		chunks := int64(throttle.Chunks)
+		writer.chunk = max((size+chunks-1)/chunks, 1)
+	}
+	return writer
+}
+
+// Write writes b piece by piece, pausing before each new chunk and waiting
+// as long as the rate limit requires after each piece.
+//
+// Returns the number of bytes written, or an error if the client disconnected.
+func (inst *throttledWriter) Write(b []byte) (int, error) {
+	if inst.start.IsZero() {
+		inst.start = time.Now()
+	}
+
+	total := 0
+	for len(b) > 0 {
+		n := len(b)
+		if inst.chunk > 0 {
+			offset := inst.written % inst.chunk
+			if offset == 0 && inst.written > 0 {
+				if err := sleep(inst.ctx, inst.pause); err != nil {
+					return total, err
+				}
+			}
+			n = int(min(int64(n), inst.chunk-offset))
+		}
+		if inst.rate > 0 {
+			n = min(n, inst.piece)
+		}
+
+		written, err := inst.w.Write(b[:n])
+		total += written
+		inst.written += int64(written)
+		if err != nil {
+			return total, err
+		}
+		if err := inst.controller.Flush(); err != nil {
+			return total, err
+		}
+		b = b[n:]
+
+		if inst.rate > 0 {
+			due := time.Duration(float64(inst.written) / float64(inst.rate) * float64(time.Second))
+			if err := sleep(inst.ctx, time.Until(inst.start.Add(due))); err != nil {
+				return total, err
+			}
+		}
+	}
+	return total, nil
+}
+
+// sleep waits for the duration, or until the context is cancelled.
+//
+// Returns the error of the context if it was cancelled.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
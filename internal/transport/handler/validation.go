@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"mockium/internal/model"
+	"mockium/internal/service"
+	"mockium/internal/service/constants"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// problem is an RFC 9457 problem details response listing the request violations.
+type problem struct {
+	Type       string            `json:"type"`
+	Title      string            `json:"title"`
+	Status     int               `json:"status"`
+	Detail     string            `json:"detail"`
+	Violations []model.Violation `json:"violations"`
+}
+
+// Validation is an HTTP handler rejecting the requests that do not satisfy
+// the API description before they reach the next handler.
+type Validation struct {
+	log           *zap.Logger
+	processLogger service.ProcessLogger
+	validator     service.RequestValidator
+	status        int
+	next          http.Handler
+}
+
+// NewValidation creates a new instance of Validation.
+//
+// Parameters:
+//   - log: a zap.Logger instance for logging rejected requests.
+//   - proceLogger: a process logger receiving every rejected request with its violations.
+//   - validator: the validator checking the requests.
+//   - status: the status of the rejected requests, e.g. 400 Bad Request.
+//   - next: the handler serving the valid requests.
+//
+// Returns:
+//
+//	A pointer to an initialized Validation.
+func NewValidation(log *zap.Logger, proceLogger service.ProcessLogger, validator service.RequestValidator, status int, next http.Handler) *Validation {
+	return &Validation{
+		log:           log,
+		processLogger: proceLogger,
+		validator:     validator,
+		status:        status,
+		next:          next,
+	}
+}
+
+// ServeHTTP passes valid requests to the next handler. Invalid ones are answered with
+// an application/problem+json body describing each violation, which is also written to the process log.
+//
+// Parameters:
+//   - w: the HTTP response writer.
+//   - r: the HTTP request.
+func (inst *Validation) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	violations := inst.validator.Validate(r)
+	if len(violations) == 0 {
+		inst.next.ServeHTTP(w, r)
+		return
+	}
+
+	logReq := buildLogRequest(inst.log, "", r)
+	logReq.Violations = violations
+	logReq.Response.SetStatus = inst.status
+	inst.processLogger.Log(logReq)
+	inst.log.Info("Serve HTTP", zap.Any("Request", logReq), zap.String("Response", "InvalidRequest"))
+
+	w.Header().Set("Content-Type", constants.ContentTypeApplicationProblemJSON)
+	w.WriteHeader(inst.status)
+	json.NewEncoder(w).Encode(problem{
+		Type:       "about:blank",
+		Title:      http.StatusText(inst.status),
+		Status:     inst.status,
+		Detail:     "request does not match the API description",
+		Violations: violations,
+	})
+}
@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mockium/internal/service"
+	"mockium/internal/service/constants"
+	"mockium/internal/transport"
+	"mockium/internal/transport/websocket"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// closeTimeout is how long the server waits for the client to answer its close frame.
+const closeTimeout = time.Second
+
+// WebSocket is an HTTP handler that upgrades the requests to WebSocket
+// and runs the script of the template for every connection.
+type WebSocket struct {
+	log           *zap.Logger
+	template      string
+	script        *transport.WebSocketScript
+	processLogger service.ProcessLogger
+	next          http.Handler
+}
+
+// NewWebSocket creates a new instance of WebSocket.
+//
+// Parameters:
+//   - log: a zap.Logger instance for logging connection activity.
+//   - proceLogger: a process logger receiving every handshake.
+//   - template: ID of the template the script belongs to, written to the process log.
+//   - script: the message exchange run for every connection.
+//   - next: handler of the requests that are not WebSocket handshakes, nil to respond 426 Upgrade Required.
+//
+// Returns:
+//
+//	A pointer to an initialized WebSocket.
+func NewWebSocket(log *zap.Logger, proceLogger service.ProcessLogger, template string, script *transport.WebSocketScript, next http.Handler) *WebSocket {
+	return &WebSocket{
+		log:           log,
+		template:      template,
+		script:        script,
+		processLogger: proceLogger,
+		next:          next,
+	}
+}
+
+// ServeHTTP completes the WebSocket handshake and runs the script until the
+// connection is closed by either side. The handshake is written to the process log.
+//
+// Parameters:
+//   - w: the HTTP response writer, it must support hijacking.
+//   - r: the HTTP request.
+func (inst *WebSocket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !websocket.IsUpgrade(r) && inst.next != nil {
+		inst.next.ServeHTTP(w, r)
+		return
+	}
+
+	logReq := buildLogRequest(inst.log, inst.template, r)
+
+	if !websocket.IsUpgrade(r) {
+		logReq.Response.SetStatus = http.StatusUpgradeRequired
+		inst.processLogger.Log(logReq)
+		inst.log.Info("Serve HTTP", zap.Any("Request", logReq), zap.String("Response", "StatusUpgradeRequired"))
+
+		w.Header().Set("Connection", "Upgrade")
+		w.Header().Set("Upgrade", "websocket")
+		http.Error(w, "websocket upgrade required", http.StatusUpgradeRequired)
+		return
+	}
+
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		logReq.Response.SetStatus = http.StatusBadRequest
+		inst.processLogger.Log(logReq)
+		inst.log.Info("Serve HTTP", zap.Any("Request", logReq), zap.String("Response", "StatusBadRequest"), zap.Error(err))
+		return
+	}
+
+	logReq.Response.SetStatus = http.StatusSwitchingProtocols
+	inst.processLogger.Log(logReq)
+	inst.log.Info("Serve HTTP", zap.Any("Request", logReq), zap.String("Response", "WebSocket"))
+
+	inst.run(conn, r)
+}
+
+// session is the state of a single WebSocket connection running the script.
+type session struct {
+	log       *zap.Logger
+	conn      *websocket.Conn
+	req       *http.Request   // The handshake request, placeholders of the messages are resolved from it.
+	ctx       context.Context // Cancelled once the connection is closing.
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+}
+
+// run sends the messages on connect, starts the timers and the scheduled close,
+// and replies to the incoming messages until the connection is closed.
+func (inst *WebSocket) run(conn *websocket.Conn, r *http.Request) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &session{log: inst.log, conn: conn, req: r, ctx: ctx, cancel: cancel}
+	defer conn.Close()
+
+	messages := make(chan []byte)
+	go s.read(messages)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.send(r, inst.script.OnConnect)
+	}()
+
+	for _, timer := range inst.script.Timers {
+		wg.Add(1)
+		go func(timer transport.WebSocketTimer) {
+			defer wg.Done()
+			s.tick(timer)
+		}(timer)
+	}
+
+	if inst.script.Close != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if s.wait(inst.script.Close.After) {
+				s.close(inst.script.Close)
+			}
+		}()
+	}
+
+	for message := range messages {
+		reply := s.match(inst.script.OnMessage, message)
+		if reply == nil {
+			inst.log.Debug("websocket message without reply", zap.ByteString("message", message))
+			continue
+		}
+
+		if !s.send(messageRequest(r, message), reply.Messages) {
+			continue
+		}
+		if reply.Close != nil && s.wait(reply.Close.After) {
+			s.close(reply.Close)
+		}
+	}
+
+	cancel()
+	wg.Wait()
+}
+
+// read passes the incoming data messages to the channel, which is closed once
+// the connection is closed or fails.
+func (inst *session) read(messages chan<- []byte) {
+	defer close(messages)
+
+	for {
+		_, message, err := inst.conn.ReadMessage()
+		if err != nil {
+			inst.log.Debug("websocket closed", zap.Error(err))
+			return
+		}
+
+		select {
+		case messages <- message:
+		case <-inst.ctx.Done():
+			return
+		}
+	}
+}
+
+// match finds the first reply matching the incoming message, nil if there is none.
+func (inst *session) match(replies []transport.WebSocketReply, message []byte) *transport.WebSocketReply {
+	for i := range replies {
+		if replies[i].Matcher.Match(message) {
+			return &replies[i]
+		}
+	}
+	return nil
+}
+
+// send sends the messages in turn, each one after its delay.
+//
+// Returns false if the connection is closing or a message cannot be sent.
+func (inst *session) send(req *http.Request, messages []transport.WebSocketMessage) bool {
+	for _, message := range messages {
+		if !inst.wait(message.Delay) {
+			return false
+		}
+		if err := inst.write(req, message.Builder); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// tick sends the message of the timer at every interval, Times times or until the connection is closing.
+func (inst *session) tick(timer transport.WebSocketTimer) {
+	ticker := time.NewTicker(timer.Every)
+	defer ticker.Stop()
+
+	for n := 0; timer.Times == 0 || n < timer.Times; n++ {
+		select {
+		case <-ticker.C:
+		case <-inst.ctx.Done():
+			return
+		}
+
+		if err := inst.write(inst.req, timer.Builder); err != nil {
+			return
+		}
+	}
+}
+
+// write builds a message and sends it as a text message. A string body is sent as it is,
+// other values as JSON.
+func (inst *session) write(req *http.Request, builder transport.ResponseBuilder) error {
+	response, err := builder.Build(req)
+	if err != nil {
+		inst.log.Error("build websocket message", zap.Error(err))
+		return err
+	}
+
+	data, ok := response.SetBody.(string)
+	if !ok {
+		encoded, err := json.Marshal(response.SetBody)
+		if err != nil {
+			inst.log.Error("encode websocket message", zap.Error(err))
+			return err
+		}
+		data = string(encoded)
+	}
+
+	if err := inst.conn.WriteMessage(websocket.TextMessage, []byte(data)); err != nil {
+		inst.log.Debug("write websocket message", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// wait waits for the delay, or until the connection is closing.
+//
+// Returns false if the connection is closing.
+func (inst *session) wait(d time.Duration) bool {
+	if d <= 0 {
+		return inst.ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-inst.ctx.Done():
+		return false
+	}
+}
+
+// close starts the closing handshake with the code of the script, the connection is closed
+// once the client answers or after closeTimeout. Further messages are not sent.
+func (inst *session) close(closing *transport.WebSocketClose) {
+	inst.closeOnce.Do(func() {
+		if err := inst.conn.WriteClose(closing.Code, closing.Reason); err != nil {
+			inst.log.Debug("write websocket close", zap.Error(err))
+		}
+		inst.conn.SetReadDeadline(time.Now().Add(closeTimeout))
+		inst.cancel()
+	})
+}
+
+// messageRequest is the handshake request with the incoming message as its body,
+// so that the placeholders and templates of a reply can refer to the message.
+func messageRequest(r *http.Request, message []byte) *http.Request {
+	req := r.Clone(r.Context())
+	req.Body = io.NopCloser(bytes.NewReader(message))
+	req.ContentLength = int64(len(message))
+
+	contentType := constants.ContentTypeTextPlain
+	if json.Valid(message) {
+		contentType = constants.ContentTypeApplicationJSON
+	}
+	req.Header.Set("Content-Type", contentType)
+	return req
+}
@@ -0,0 +1,69 @@
+// Package proxy forwards requests to an upstream server.
+package proxy
+
+import (
+	"fmt"
+	"mockium/internal/model"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"go.uber.org/zap"
+)
+
+// NewReverseProxy creates a reverse proxy forwarding requests to the upstream URL.
+// The request path is appended to the upstream path, and the Host header is set to the upstream host.
+//
+// Parameters:
+//   - log: logger for upstream errors.
+//   - upstream: the upstream URL, e.g. "https://staging.example.com".
+//
+// Returns the reverse proxy or an error if the upstream URL is invalid.
+func NewReverseProxy(log *zap.Logger, upstream string) (*httputil.ReverseProxy, error) {
+	target, err := url.Parse(upstream)
+	if err != nil {
+		return nil, err
+	}
+	if target.Scheme == "" || target.Host == "" {
+		return nil, fmt.Errorf("upstream '%s' must be an absolute URL", upstream)
+	}
+
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+			r.SetXForwarded()
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Error("proxy request", zap.String("upstream", upstream), zap.String("url", r.URL.String()), zap.Error(err))
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+		},
+	}, nil
+}
+
+// NewHandleProxy creates a reverse proxy for a handle's SetProxy.
+// The request headers are added and removed as configured before forwarding.
+//
+// Parameters:
+//   - log: logger for upstream errors.
+//   - template: the upstream URL and the header changes.
+//
+// Returns the reverse proxy or an error if the upstream URL is invalid.
+func NewHandleProxy(log *zap.Logger, template *model.ProxyTemplate) (*httputil.ReverseProxy, error) {
+	proxy, err := NewReverseProxy(log, template.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	rewrite := proxy.Rewrite
+	proxy.Rewrite = func(r *httputil.ProxyRequest) {
+		rewrite(r)
+		for _, name := range template.RemoveHeaders {
+			r.Out.Header.Del(name)
+		}
+		for name, value := range template.AddHeaders {
+			r.Out.Header.Set(name, value)
+		}
+	}
+
+	return proxy, nil
+}
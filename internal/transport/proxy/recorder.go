@@ -0,0 +1,118 @@
+package proxy
+
+import (
+	"bytes"
+	"io"
+	"mockium/internal/model"
+	"mockium/internal/service"
+	"net/http"
+	"net/http/httputil"
+
+	"go.uber.org/zap"
+)
+
+// Recorder is an HTTP handler that proxies every request to the upstream
+// and records the exchange, e.g. as a template.
+type Recorder struct {
+	log      *zap.Logger
+	proxy    *httputil.ReverseProxy
+	recorder service.ExchangeRecorder
+}
+
+// NewRecorder creates a new instance of Recorder.
+//
+// Parameters:
+//   - log: logger for proxy and recording errors.
+//   - upstream: the upstream URL requests are forwarded to.
+//   - recorder: the recorder receiving every successfully proxied exchange.
+//
+// Returns a pointer to a Recorder or an error if the upstream URL is invalid.
+func NewRecorder(log *zap.Logger, upstream string, recorder service.ExchangeRecorder) (*Recorder, error) {
+	proxy, err := NewReverseProxy(log, upstream)
+	if err != nil {
+		return nil, err
+	}
+
+	errorHandler := proxy.ErrorHandler
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		if rec, ok := w.(*recordingWriter); ok {
+			rec.failed = true
+		}
+		errorHandler(w, r, err)
+	}
+
+	return &Recorder{
+		log:      log,
+		proxy:    proxy,
+		recorder: recorder,
+	}, nil
+}
+
+// ServeHTTP forwards the request to the upstream and returns the upstream response
+// to the client. Exchanges that reached the upstream are recorded afterwards.
+//
+// Parameters:
+//   - w: the HTTP response writer.
+//   - r: the HTTP request.
+func (inst *Recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			inst.log.Error("read request body", zap.Error(err))
+			http.Error(w, "failed read request", http.StatusBadRequest)
+			return
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	// Ask the upstream for an uncompressed body, so it can be recorded as is.
+	r.Header.Del("Accept-Encoding")
+
+	exchange := &model.Exchange{
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Query:   r.URL.Query(),
+		Headers: r.Header.Clone(),
+		Body:    body,
+	}
+
+	rec := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+	inst.proxy.ServeHTTP(rec, r)
+
+	if rec.failed {
+		return
+	}
+
+	exchange.Status = rec.status
+	exchange.ResponseHeaders = w.Header().Clone()
+	exchange.ResponseBody = rec.body.Bytes()
+
+	if err := inst.recorder.Record(exchange); err != nil {
+		inst.log.Error("record exchange", zap.String("method", r.Method), zap.String("url", r.URL.String()), zap.Error(err))
+	}
+}
+
+// recordingWriter passes the response through to the client and keeps a copy of it.
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+	failed bool // The upstream could not be reached.
+}
+
+func (inst *recordingWriter) WriteHeader(status int) {
+	inst.status = status
+	inst.ResponseWriter.WriteHeader(status)
+}
+
+func (inst *recordingWriter) Write(b []byte) (int, error) {
+	inst.body.Write(b)
+	return inst.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the flusher of the underlying writer.
+func (inst *recordingWriter) Unwrap() http.ResponseWriter {
+	return inst.ResponseWriter
+}
@@ -0,0 +1,110 @@
+package proxy
+
+import (
+	"compress/gzip"
+	"io"
+	"mockium/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type MockExchangeRecorder struct {
+	exchanges []*model.Exchange
+}
+
+func (m *MockExchangeRecorder) Record(exchange *model.Exchange) error {
+	m.exchanges = append(m.exchanges, exchange)
+	return nil
+}
+
+func TestRecorder_ServeHTTP(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "/api/users", r.URL.Path)
+
+		// A compressed response must still be recorded uncompressed.
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusCreated)
+
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(`{"echo":` + string(body) + `}`))
+		gz.Close()
+	}))
+	defer upstream.Close()
+
+	recorder := &MockExchangeRecorder{}
+	h, err := NewRecorder(zaptest.NewLogger(t), upstream.URL+"/api", recorder)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/users?page=1", strings.NewReader(`{"name":"test"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, `{"echo":{"name":"test"}}`, rec.Body.String())
+
+	require.Len(t, recorder.exchanges, 1)
+	exchange := recorder.exchanges[0]
+	assert.Equal(t, "POST", exchange.Method)
+	assert.Equal(t, "/users", exchange.Path)
+	assert.Equal(t, "1", exchange.Query.Get("page"))
+	assert.Equal(t, `{"name":"test"}`, string(exchange.Body))
+	assert.Equal(t, http.StatusCreated, exchange.Status)
+	assert.Equal(t, "application/json", exchange.ResponseHeaders.Get("Content-Type"))
+	assert.Equal(t, `{"echo":{"name":"test"}}`, string(exchange.ResponseBody))
+}
+
+func TestRecorder_UpstreamDown(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	upstream.Close()
+
+	recorder := &MockExchangeRecorder{}
+	h, err := NewRecorder(zaptest.NewLogger(t), upstream.URL, recorder)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Empty(t, recorder.exchanges)
+}
+
+func TestNewReverseProxy_InvalidUpstream(t *testing.T) {
+	_, err := NewReverseProxy(zaptest.NewLogger(t), "localhost:8080")
+	assert.Error(t, err)
+}
+
+func TestNewHandleProxy_Headers(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+		assert.Empty(t, r.Header.Get("Cookie"))
+		assert.Equal(t, "keep", r.Header.Get("X-Keep"))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer upstream.Close()
+
+	h, err := NewHandleProxy(zaptest.NewLogger(t), &model.ProxyTemplate{
+		URL:           upstream.URL,
+		AddHeaders:    map[string]string{"Authorization": "secret"},
+		RemoveHeaders: []string{"Cookie"},
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Cookie", "session=1")
+	req.Header.Set("X-Keep", "keep")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+}
@@ -3,6 +3,8 @@ package server
 import (
 	"mockium/internal/transport"
 	"net/http"
+	"strings"
+	"sync/atomic"
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
@@ -17,11 +19,19 @@ import (
 // Returns:
 //   - Pointer to a newly initialized Server instance
 func New(log *zap.Logger, routes ...transport.Router) *Server {
-	return &Server{
+	srv := &Server{
 		log:    log,
 		server: &http.Server{},
 		routes: routes,
 	}
+	srv.handler = http.HandlerFunc(srv.route)
+	return srv
+}
+
+// mount is an HTTP handler serving every request under a path prefix.
+type mount struct {
+	prefix  string
+	handler http.Handler
 }
 
 // Server represents an HTTP server that manages multiple routers.
@@ -29,16 +39,56 @@ func New(log *zap.Logger, routes ...transport.Router) *Server {
 // - A logger for recording server operations
 // - The underlying http.Server instance
 // - A collection of registered routers
+// - Handlers mounted under a path prefix, e.g. the admin API
+// - The handler of the requests no route matches
+// - The currently active request router, which can be swapped at runtime
+// - The handler chain in front of the router, e.g. request validation
 type Server struct {
-	log    *zap.Logger        // Logger for server operations
-	server *http.Server       // Underlying HTTP server
-	routes []transport.Router // Collection of registered routers
+	log      *zap.Logger                // Logger for server operations
+	server   *http.Server               // Underlying HTTP server
+	routes   []transport.Router         // Collection of routers registered at creation
+	mounts   []mount                    // Handlers registered under a path prefix
+	notFound http.Handler               // Handler of the requests no route matches, nil for 404 Not Found
+	router   atomic.Pointer[mux.Router] // Active request router
+	handler  http.Handler               // Router wrapped by the registered middlewares, the mounts are served before it
+}
+
+// Mount registers a handler serving every request under the path prefix.
+// Mounted handlers take precedence over the template routes and are kept
+// across reloads. Mount must be called before Start.
+//
+// Parameters:
+//   - prefix: Path prefix handled by the handler (e.g., "/__admin")
+//   - handler: Handler serving the requests under the prefix
+func (inst *Server) Mount(prefix string, handler http.Handler) {
+	inst.mounts = append(inst.mounts, mount{prefix: prefix, handler: handler})
+}
+
+// NotFound registers the handler of the requests no route matches, including the
+// requests to a known path with an unexpected method, e.g. a proxy to an upstream.
+// NotFound must be called before Start and Reload.
+//
+// Parameters:
+//   - handler: Handler serving the unmatched requests
+func (inst *Server) NotFound(handler http.Handler) {
+	inst.notFound = handler
+}
+
+// Use wraps the request router with a middleware, e.g. request validation.
+// The middleware sees the requests to the template routes and the NotFound handler,
+// the mounted handlers are served without it; the last registered middleware runs first.
+// Use must be called before Start.
+//
+// Parameters:
+//   - middleware: Function wrapping the next handler
+func (inst *Server) Use(middleware func(next http.Handler) http.Handler) {
+	inst.handler = middleware(inst.handler)
 }
 
 // Start initializes and runs the HTTP server on the specified address.
 // It performs the following operations:
 // 1. Configures the server address
-// 2. Creates a new router using gorilla/mux
+// 2. Creates a new router using gorilla/mux, unless one was already set by Reload
 // 3. Registers all handlers from the configured routes
 // 4. Starts listening for incoming requests
 //
@@ -47,19 +97,73 @@ type Server struct {
 //
 // Returns:
 //   - error: Any error that occurs during server startup or operation
-//
-// Notes:
-// - Defaults to GET method if no method is specified in the route
-// - Logs each registered handler for debugging purposes
 func (inst *Server) Start(address string) error {
 	inst.server.Addr = address
 
 	// Initialize the request router
+	if inst.router.Load() == nil {
+		inst.router.CompareAndSwap(nil, inst.newRouter(inst.routes))
+	}
+
+	// Dispatch every request to the currently active router
+	inst.server.Handler = inst
+
+	// Start the server
+	inst.log.Info("start listen and serve",
+		zap.String("address", address))
+
+	return inst.server.ListenAndServe()
+}
+
+// Reload builds a new request router from the given routes and atomically
+// replaces the active one. Requests that are already being served keep
+// using the router they were dispatched to.
+//
+// Parameters:
+//   - routes: Variadic list of routers replacing the current configuration
+func (inst *Server) Reload(routes ...transport.Router) {
+	inst.router.Store(inst.newRouter(routes))
+	inst.log.Info("routes reloaded", zap.Int("routes", len(routes)))
+}
+
+// ServeHTTP dispatches the request to the mounted handler of its path prefix,
+// or through the middlewares to the currently active router.
+func (inst *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, m := range inst.mounts {
+		if strings.HasPrefix(r.URL.Path, m.prefix) {
+			m.handler.ServeHTTP(w, r)
+			return
+		}
+	}
+	inst.handler.ServeHTTP(w, r)
+}
+
+// route dispatches the request to the currently active router.
+// It responds with 404 Not Found if no router has been configured yet.
+func (inst *Server) route(w http.ResponseWriter, r *http.Request) {
+	router := inst.router.Load()
+	if router == nil {
+		http.NotFound(w, r)
+		return
+	}
+	router.ServeHTTP(w, r)
+}
+
+// newRouter creates a gorilla/mux router and registers all handlers from the routes.
+//
+// Notes:
+// - Defaults to GET method if no method is specified in the route
+// - Logs each registered handler for debugging purposes
+func (inst *Server) newRouter(routes []transport.Router) *mux.Router {
 	r := mux.NewRouter()
+	if inst.notFound != nil {
+		r.NotFoundHandler = inst.notFound
+		r.MethodNotAllowedHandler = inst.notFound
+	}
 
 	// Register all routes and their handlers
 	method := "GET"
-	for _, route := range inst.routes {
+	for _, route := range routes {
 		for m, hr := range route.Handlers() {
 			// Use GET as default method if not specified
 			if string(m) == "" {
@@ -78,12 +182,5 @@ func (inst *Server) Start(address string) error {
 		}
 	}
 
-	// Set the configured router as the server handler
-	inst.server.Handler = r
-
-	// Start the server
-	inst.log.Info("start listen and serve",
-		zap.String("address", address))
-
-	return inst.server.ListenAndServe()
+	return r
 }
@@ -79,3 +79,122 @@ func TestStartServer_InvalidAddress(t *testing.T) {
 }
 
 var muxNewRouter = mux.NewRouter
+
+func TestServer_Reload(t *testing.T) {
+	log := zaptest.NewLogger(t)
+
+	handlerWith := func(body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		})
+	}
+
+	srv := New(log)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/test", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	srv.Reload(&MockRouter{
+		path:     "/test",
+		handlers: map[model.Method]http.Handler{http.MethodGet: handlerWith("old")},
+	})
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/test", nil))
+	assert.Equal(t, "old", rec.Body.String())
+
+	srv.Reload(&MockRouter{
+		path:     "/test",
+		handlers: map[model.Method]http.Handler{http.MethodGet: handlerWith("new")},
+	})
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/test", nil))
+	assert.Equal(t, "new", rec.Body.String())
+}
+
+func TestServer_Mount(t *testing.T) {
+	log := zaptest.NewLogger(t)
+
+	srv := New(log)
+	srv.Mount("/__admin", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("admin"))
+	}))
+	srv.Reload(&MockRouter{
+		path: "/{any}",
+		handlers: map[model.Method]http.Handler{http.MethodGet: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("mock"))
+		})},
+	})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/__admin/templates", nil))
+	assert.Equal(t, "admin", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, "mock", rec.Body.String())
+}
+
+func TestServer_NotFound(t *testing.T) {
+	log := zaptest.NewLogger(t)
+
+	srv := New(log)
+	srv.NotFound(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("upstream"))
+	}))
+	srv.Reload(&MockRouter{
+		path: "/users",
+		handlers: map[model.Method]http.Handler{http.MethodGet: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("mock"))
+		})},
+	})
+
+	for _, tt := range []struct{ method, path, body string }{
+		{http.MethodGet, "/users", "mock"},
+		{http.MethodPost, "/users", "upstream"},
+		{http.MethodGet, "/orders", "upstream"},
+	} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		assert.Equal(t, tt.body, rec.Body.String(), tt.method+" "+tt.path)
+	}
+}
+
+func TestServer_Use(t *testing.T) {
+	log := zaptest.NewLogger(t)
+
+	srv := New(log)
+	srv.Mount("/__admin", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("admin"))
+	}))
+	srv.Reload(&MockRouter{
+		path: "/users",
+		handlers: map[model.Method]http.Handler{http.MethodGet: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("mock"))
+		})},
+	})
+	srv.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("valid") != "true" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users?valid=true", nil))
+	assert.Equal(t, "mock", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// The mounted handlers are not wrapped by the middlewares.
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/__admin/templates", nil))
+	assert.Equal(t, "admin", rec.Body.String())
+}
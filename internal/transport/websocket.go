@@ -0,0 +1,44 @@
+package transport
+
+import "time"
+
+// MessageMatcher decides whether an incoming WebSocket message applies to a reply.
+type MessageMatcher interface {
+	Match(message []byte) bool
+}
+
+// WebSocketScript is the message exchange run for every connection of a WebSocket template.
+type WebSocketScript struct {
+	OnConnect []WebSocketMessage // Messages sent in turn once the connection is open.
+	OnMessage []WebSocketReply   // Replies to the incoming messages, the first matching one is used.
+	Timers    []WebSocketTimer   // Messages sent periodically.
+	Close     *WebSocketClose    // Close of the connection by the server, nil to wait for the client.
+}
+
+// WebSocketMessage is a message sent by the server. Its builder renders the message
+// as the SetBody of a response, with the incoming message as the request body of a reply.
+type WebSocketMessage struct {
+	Builder ResponseBuilder // Builder of the message.
+	Delay   time.Duration   // Wait before the message is sent.
+}
+
+// WebSocketReply pairs a message matcher with the messages sent back.
+type WebSocketReply struct {
+	Matcher  MessageMatcher     // Matcher deciding whether the reply applies to a message.
+	Messages []WebSocketMessage // Messages sent in turn.
+	Close    *WebSocketClose    // Close of the connection after the messages, nil to keep it open.
+}
+
+// WebSocketTimer sends a message at every interval.
+type WebSocketTimer struct {
+	Every   time.Duration   // Interval between the messages.
+	Times   int             // Number of messages sent, 0 until the connection is closed.
+	Builder ResponseBuilder // Builder of the message.
+}
+
+// WebSocketClose closes the connection with a close code once its delay has passed.
+type WebSocketClose struct {
+	Code   int           // Close code sent to the client.
+	Reason string        // Close reason sent to the client.
+	After  time.Duration // Wait before closing.
+}
@@ -0,0 +1,460 @@
+// Package websocket implements the WebSocket protocol (RFC 6455) on top of
+// hijacked HTTP connections: the server handshake, framing with fragmented
+// messages, ping/pong and the closing handshake. A minimal client is provided
+// to exercise WebSocket mocks.
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message types, the opcodes of the data frames.
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+)
+
+// Opcodes of the continuation and control frames.
+const (
+	continuationFrame = 0
+	closeFrame        = 8
+	pingFrame         = 9
+	pongFrame         = 10
+)
+
+// Close codes used by the connection itself, see RFC 6455 section 7.4.1.
+const (
+	CloseNormal        = 1000
+	CloseProtocolError = 1002
+	CloseNoStatus      = 1005
+	CloseTooBig        = 1009
+)
+
+const (
+	// acceptGUID is appended to the handshake key to compute Sec-WebSocket-Accept.
+	acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	// MaxMessageSize is the largest message accepted, larger ones close the connection.
+	MaxMessageSize = 1 << 20
+
+	// maxControlSize is the largest payload of a control frame.
+	maxControlSize = 125
+)
+
+// ErrClosed is returned when writing after the close frame was sent.
+var ErrClosed = errors.New("websocket: close sent")
+
+// CloseError is returned by ReadMessage once the peer closed the connection.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (inst *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed with code %d %s", inst.Code, inst.Reason)
+}
+
+// Conn is a WebSocket connection. Messages are read by a single goroutine,
+// while writes may come from several goroutines.
+type Conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	client bool // The client side masks the frames it sends.
+
+	mu        sync.Mutex
+	closeSent bool
+}
+
+// IsUpgrade reports whether the request asks to upgrade the connection to WebSocket.
+func IsUpgrade(r *http.Request) bool {
+	return hasToken(r.Header, "Connection", "upgrade") && hasToken(r.Header, "Upgrade", "websocket")
+}
+
+// Upgrade completes the WebSocket handshake of the request and takes over its connection.
+// If the handshake is invalid, an error response is written and an error returned.
+//
+// Parameters:
+//   - w: the HTTP response writer, it must support hijacking.
+//   - r: the handshake request.
+//
+// Returns the open connection, or an error if the handshake failed.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet || !IsUpgrade(r) {
+		http.Error(w, "websocket upgrade required", http.StatusBadRequest)
+		return nil, errors.New("websocket: not an upgrade request")
+	}
+
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusUpgradeRequired)
+		return nil, fmt.Errorf("websocket: unsupported version '%s'", r.Header.Get("Sec-WebSocket-Version"))
+	}
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("websocket: missing Sec-WebSocket-Key")
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "websocket upgrade unsupported", http.StatusInternalServerError)
+		return nil, err
+	}
+
+	handshake := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	if _, err := rw.WriteString(handshake); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// The handshake may have had a deadline set by the server, the connection has none.
+	conn.SetDeadline(time.Time{})
+	return &Conn{conn: conn, reader: rw.Reader}, nil
+}
+
+// Dial opens a WebSocket connection to a ws:// or http:// URL.
+//
+// Parameters:
+//   - ctx: the context of the dial and the handshake.
+//   - rawURL: the URL of the WebSocket endpoint.
+//   - header: additional headers of the handshake request, may be nil.
+//
+// Returns the open connection and the handshake response, or an error if the server
+// did not switch protocols; the response is returned with the error if there is one.
+func Dial(ctx context.Context, rawURL string, header http.Header) (*Conn, *http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch u.Scheme {
+	case "ws", "http":
+		u.Scheme = "http"
+	default:
+		return nil, nil, fmt.Errorf("websocket: unsupported scheme '%s'", u.Scheme)
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "80")
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		conn.Close()
+		return nil, resp, fmt.Errorf("websocket: handshake failed with status %d", resp.StatusCode)
+	}
+
+	conn.SetDeadline(time.Time{})
+	return &Conn{conn: conn, reader: reader, client: true}, resp, nil
+}
+
+// ReadMessage reads the next data message, assembling its fragments.
+// Pings are answered and pongs skipped. When the peer sends a close frame,
+// it is echoed unless a close frame was already sent, and a *CloseError is returned.
+//
+// Returns the message type (TextMessage or BinaryMessage) and its payload.
+func (inst *Conn) ReadMessage() (int, []byte, error) {
+	messageType := 0
+	var message []byte
+
+	for {
+		fin, opcode, payload, err := inst.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch opcode {
+		case pingFrame:
+			if err := inst.writeFrame(pongFrame, payload); err != nil && !errors.Is(err, ErrClosed) {
+				return 0, nil, err
+			}
+			continue
+		case pongFrame:
+			continue
+		case closeFrame:
+			closeErr := &CloseError{Code: CloseNoStatus}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+			}
+			if err := inst.WriteClose(closeErr.Code, ""); err != nil && !errors.Is(err, ErrClosed) {
+				return 0, nil, err
+			}
+			return 0, nil, closeErr
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, inst.fail(CloseProtocolError, "websocket: unfinished fragmented message")
+			}
+			messageType = opcode
+		case continuationFrame:
+			if messageType == 0 {
+				return 0, nil, inst.fail(CloseProtocolError, "websocket: unexpected continuation frame")
+			}
+		default:
+			return 0, nil, inst.fail(CloseProtocolError, fmt.Sprintf("websocket: unknown opcode %d", opcode))
+		}
+
+		if len(message)+len(payload) > MaxMessageSize {
+			return 0, nil, inst.fail(CloseTooBig, "websocket: message too big")
+		}
+		message = append(message, payload...)
+
+		if fin {
+			return messageType, message, nil
+		}
+	}
+}
+
+// WriteMessage sends a message in a single frame.
+//
+// Parameters:
+//   - messageType: TextMessage or BinaryMessage.
+//   - data: the payload of the message.
+//
+// Returns ErrClosed if the close frame was already sent.
+func (inst *Conn) WriteMessage(messageType int, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("websocket: unknown message type %d", messageType)
+	}
+	return inst.writeFrame(messageType, data)
+}
+
+// WriteClose sends the close frame starting the closing handshake.
+// Messages cannot be written afterwards; the peer is expected to echo the
+// close frame, which ReadMessage returns as a *CloseError.
+//
+// Returns ErrClosed if the close frame was already sent.
+func (inst *Conn) WriteClose(code int, reason string) error {
+	var payload []byte
+	if code != CloseNoStatus {
+		payload = binary.BigEndian.AppendUint16(nil, uint16(code))
+		payload = append(payload, reason...)
+		if len(payload) > maxControlSize {
+			payload = payload[:maxControlSize]
+		}
+	}
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if inst.closeSent {
+		return ErrClosed
+	}
+	inst.closeSent = true
+	return inst.write(closeFrame, payload)
+}
+
+// SetReadDeadline sets the deadline of the reads, e.g. to wait for the close frame of the peer.
+func (inst *Conn) SetReadDeadline(t time.Time) error {
+	return inst.conn.SetReadDeadline(t)
+}
+
+// Close closes the underlying connection without the closing handshake.
+func (inst *Conn) Close() error {
+	return inst.conn.Close()
+}
+
+// fail sends a close frame with the code after a protocol violation of the peer.
+//
+// Returns the error describing the violation.
+func (inst *Conn) fail(code int, text string) error {
+	inst.WriteClose(code, "")
+	return errors.New(text)
+}
+
+// readFrame reads a single frame and unmasks its payload.
+func (inst *Conn) readFrame() (bool, int, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(inst.reader, header); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := header[0]&0x80 != 0
+	opcode := int(header[0] & 0x0f)
+	masked := header[1]&0x80 != 0
+
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, inst.fail(CloseProtocolError, "websocket: unexpected reserved bits")
+	}
+	// Frames sent by a client are masked, frames sent by a server are not.
+	if masked == inst.client {
+		return false, 0, nil, inst.fail(CloseProtocolError, "websocket: unexpected frame masking")
+	}
+
+	size := uint64(header[1] & 0x7f)
+	switch size {
+	case 126:
+		extended := make([]byte, 2)
+		if _, err := io.ReadFull(inst.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+		size = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		if _, err := io.ReadFull(inst.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(extended)
+	}
+
+	if opcode >= closeFrame && (!fin || size > maxControlSize) {
+		return false, 0, nil, inst.fail(CloseProtocolError, "websocket: invalid control frame")
+	}
+	if size > MaxMessageSize {
+		return false, 0, nil, inst.fail(CloseTooBig, "websocket: message too big")
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(inst.reader, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(inst.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		maskBytes(mask, payload)
+	}
+	return fin, opcode, payload, nil
+}
+
+// writeFrame sends a single final frame, unless the close frame was already sent.
+func (inst *Conn) writeFrame(opcode int, payload []byte) error {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if inst.closeSent {
+		return ErrClosed
+	}
+	return inst.write(opcode, payload)
+}
+
+// write encodes and sends a frame, the caller holds the lock.
+func (inst *Conn) write(opcode int, payload []byte) error {
+	frame := make([]byte, 0, len(payload)+14)
+	frame = append(frame, 0x80|byte(opcode))
+
+	maskBit := byte(0)
+	if inst.client {
+		maskBit = 0x80
+	}
+
+	switch size := len(payload); {
+	case size <= 125:
+		frame = append(frame, maskBit|byte(size))
+	case size <= 0xffff:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(size))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(size))
+	}
+
+	if !inst.client {
+		frame = append(frame, payload...)
+		_, err := inst.conn.Write(frame)
+		return err
+	}
+
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return err
+	}
+	frame = append(frame, mask[:]...)
+	start := len(frame)
+	frame = append(frame, payload...)
+	maskBytes(mask, frame[start:])
+
+	_, err := inst.conn.Write(frame)
+	return err
+}
+
+// maskBytes applies the masking key to the payload, masking and unmasking are the same.
+func maskBytes(mask [4]byte, payload []byte) {
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+}
+
+// acceptKey computes the Sec-WebSocket-Accept of a Sec-WebSocket-Key.
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// hasToken reports whether a comma separated header contains the token, ignoring case.
+func hasToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, item := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
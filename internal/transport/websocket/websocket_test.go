@@ -0,0 +1,171 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoServer echoes the messages of every connection until it is closed.
+func echoServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			messageType, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if string(message) == "close" {
+				conn.WriteClose(4000, "bye")
+				continue
+			}
+			if err := conn.WriteMessage(messageType, message); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func dial(t *testing.T, srv *httptest.Server) *Conn {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	conn, resp, err := Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// maskedFrame encodes a short client frame with a zero masking key.
+func maskedFrame(fin bool, opcode int, payload string) []byte {
+	first := byte(opcode)
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first, 0x80 | byte(len(payload)), 0, 0, 0, 0}
+	return append(frame, payload...)
+}
+
+func TestAcceptKey(t *testing.T) {
+	// Example of RFC 6455 section 1.3.
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", acceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+func TestUpgrade_Invalid(t *testing.T) {
+	srv := echoServer(t)
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Connection", "keep-alive, Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "8")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUpgradeRequired, resp.StatusCode)
+	assert.Equal(t, "13", resp.Header.Get("Sec-WebSocket-Version"))
+}
+
+func TestConn_Messages(t *testing.T) {
+	conn := dial(t, echoServer(t))
+
+	long := strings.Repeat("x", 70000)
+	for _, message := range []string{"hello", strings.Repeat("y", 300), long} {
+		require.NoError(t, conn.WriteMessage(TextMessage, []byte(message)))
+
+		messageType, echo, err := conn.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, TextMessage, messageType)
+		assert.Equal(t, message, string(echo))
+	}
+
+	require.NoError(t, conn.WriteMessage(BinaryMessage, []byte{0, 1, 2}))
+	messageType, echo, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, BinaryMessage, messageType)
+	assert.Equal(t, []byte{0, 1, 2}, echo)
+}
+
+func TestConn_FragmentsAndPing(t *testing.T) {
+	conn := dial(t, echoServer(t))
+
+	for _, frame := range [][]byte{
+		maskedFrame(false, TextMessage, "hel"),
+		maskedFrame(true, pingFrame, "ping"),
+		maskedFrame(true, continuationFrame, "lo"),
+	} {
+		_, err := conn.conn.Write(frame)
+		require.NoError(t, err)
+	}
+
+	// The pong is read before the message and skipped.
+	_, message, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(message))
+}
+
+func TestConn_Close(t *testing.T) {
+	conn := dial(t, echoServer(t))
+
+	require.NoError(t, conn.WriteMessage(TextMessage, []byte("close")))
+
+	_, _, err := conn.ReadMessage()
+	var closeErr *CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, 4000, closeErr.Code)
+	assert.Equal(t, "bye", closeErr.Reason)
+
+	assert.ErrorIs(t, conn.WriteMessage(TextMessage, []byte("late")), ErrClosed)
+}
+
+func TestConn_UnmaskedClientFrame(t *testing.T) {
+	srv := echoServer(t)
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	require.NoError(t, req.Write(conn))
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	require.NoError(t, err)
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+
+	// An unmasked frame from a client is a protocol error.
+	_, err = conn.Write([]byte{0x81, 0x02, 'h', 'i'})
+	require.NoError(t, err)
+
+	header := make([]byte, 4)
+	_, err = reader.Read(header)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0x88), header[0])
+	assert.Equal(t, uint16(CloseProtocolError), binary.BigEndian.Uint16(header[2:]))
+}